@@ -12,29 +12,33 @@ function ExcelUpload() {
 
     const handleUpload = async () => {
         if (file) {
-            const data = await file.arrayBuffer();
-            const workbook = XLSX.read(data);
-            const worksheetName = workbook.SheetNames[0];
-            const worksheet = workbook.Sheets[worksheetName];
-            const json = XLSX.utils.sheet_to_json(worksheet);
-            console.log(json)
-            sendDataToServer(json);
+            const data = new FormData();
+            if (file.name.toLowerCase().endsWith('.xls')) {
+                // The server only reads .xlsx and .csv, so legacy workbooks are converted here
+                const workbook = XLSX.read(await file.arrayBuffer());
+                const csv = XLSX.utils.sheet_to_csv(workbook.Sheets[workbook.SheetNames[0]]);
+                data.append('file', new Blob([csv], { type: 'text/csv' }), file.name.replace(/\.xls$/i, '.csv'));
+            } else {
+                data.append('file', file);
+            }
+            sendDataToServer(data);
         }
     };
 
     const sendDataToServer = (data) => {
-        fetch('/api/spreadsheets', {
+        fetch('/api/spreadsheets/import', {
             method: 'POST',
-            headers: {
-                'Content-Type': 'application/json'
-            },
-            body: JSON.stringify(data)
+            body: data
         })
-        .then(response => {
+        .then(async response => {
+            const report = await response.json();
             if (response.ok) {
-                return response.json();
+                return report;
+            }
+            if (report.errors) {
+                throw new Error(report.errors.map(error => `row ${error.row}: ${error.message}`).join('\n'));
             }
-            throw new Error('Network response was not ok.');
+            throw new Error(report.message || 'Network response was not ok.');
         })
         .then(() => alert('Data successfully uploaded'))
         .catch(error => alert('Failed to upload data: ' + error.message));
@@ -42,7 +46,7 @@ function ExcelUpload() {
 
     return (
         <div>
-            <input type="file" onChange={handleFileChange} accept=".xlsx, .xls" />
+            <input type="file" onChange={handleFileChange} accept=".xlsx, .xls, .csv" />
             <button onClick={handleUpload}>Upload</button>
         </div>
     );
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/Tchoukball-Tracker/pkg/backup"
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/logger"
+)
+
+// runCommand runs a maintenance subcommand of the server binary instead of
+// starting the server.
+func runCommand(name string, args []string) {
+	switch name {
+	case "backup":
+		runBackup(args)
+	case "restore":
+		runRestore(args)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q, use backup or restore\n", name)
+		os.Exit(2)
+	}
+}
+
+// runBackup writes every collection of the database to a zip archive.
+func runBackup(args []string) {
+	flags := flag.NewFlagSet("backup", flag.ExitOnError)
+	output := flags.String("o", "", "archive to write (default tchoukball-<date>-<time>.zip)")
+	flags.Parse(args)
+
+	path := *output
+	if path == "" {
+		path = "tchoukball-" + time.Now().UTC().Format("20060102-150405") + ".zip"
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	connectDatabase()
+	defer database.Disconnect()
+
+	file, err := os.Create(path)
+	if err != nil {
+		logger.Log.Fatalf("Failed to create backup: %v", err)
+	}
+
+	manifest, err := backup.Backup(ctx, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		logger.Log.Fatalf("Failed to back up the database: %v", err)
+	}
+
+	documents := 0
+	for _, collection := range manifest.Collections {
+		documents += collection.Documents
+		logger.Log.Infof("Backed up %d documents of %s", collection.Documents, collection.Name)
+	}
+	logger.Log.Infof("Backed up %d documents from %d collections to %s", documents, len(manifest.Collections), path)
+}
+
+// runRestore verifies a backup archive and loads it into the database.
+func runRestore(args []string) {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	policy := flags.String("conflict", backup.PolicyAbort, "what to do with documents that already exist: "+strings.Join(backup.Policies, ", "))
+	dryRun := flags.Bool("dry-run", false, "only verify the archive")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: server restore [flags] <archive>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	path := flags.Arg(0)
+
+	file, err := os.Open(path)
+	if err != nil {
+		logger.Log.Fatalf("Failed to open backup: %v", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		logger.Log.Fatalf("Failed to open backup: %v", err)
+	}
+
+	if *dryRun {
+		manifest, err := backup.Verify(file, info.Size())
+		if err != nil {
+			logger.Log.Fatalf("Invalid backup: %v", err)
+		}
+		logger.Log.Infof("Backup from %s of %d collections is valid", manifest.CreatedAt.Format(time.RFC3339), len(manifest.Collections))
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	connectDatabase()
+	defer database.Disconnect()
+
+	results, err := backup.Restore(ctx, file, info.Size(), *policy)
+	for _, result := range results {
+		logger.Log.Infof("Restored %d documents of %s, skipped %d", result.Restored, result.Name, result.Skipped)
+	}
+	if err != nil {
+		logger.Log.Fatalf("Failed to restore the database: %v", err)
+	}
+}
@@ -15,27 +15,72 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/matches": {
+        "/analytics/actions": {
             "get": {
-                "description": "get all matches from the database",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "stream every event of the action log of the matches selected by the filters, one row per event with the details of its match, as NDJSON or Parquet",
                 "produces": [
-                    "application/json"
+                    "application/x-ndjson",
+                    "application/vnd.apache.parquet"
                 ],
                 "tags": [
-                    "matches"
+                    "analytics"
+                ],
+                "summary": "Export the action log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ndjson (default) or parquet",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches on or after this date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches on or before this date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches from this season",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches from this competition",
+                        "name": "competition",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches this team was tracked in",
+                        "name": "team",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches where this team opposed the tracked players",
+                        "name": "opponent",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Retrieve all matches",
                 "responses": {
                     "200": {
-                        "description": "List of matches",
+                        "description": "Action log",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Match"
-                            }
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid filter or format",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
                         }
                     },
                     "500": {
@@ -45,45 +90,72 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "create a new match with the provided details",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/analytics/counters": {
+            "get": {
+                "description": "stream the attacking and defending counters of every player in every third of the matches selected by the filters, one row per player per third with the details of its match, as NDJSON or Parquet",
                 "produces": [
-                    "application/json"
+                    "application/x-ndjson",
+                    "application/vnd.apache.parquet"
                 ],
                 "tags": [
-                    "matches"
+                    "analytics"
                 ],
-                "summary": "Create a new match",
+                "summary": "Export the player counters",
                 "parameters": [
                     {
-                        "description": "Match Info",
-                        "name": "match",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Match"
-                        }
+                        "type": "string",
+                        "description": "ndjson (default) or parquet",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches on or after this date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches on or before this date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches from this season",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches from this competition",
+                        "name": "competition",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches this team was tracked in",
+                        "name": "team",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches where this team opposed the tracked players",
+                        "name": "opponent",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Successfully created",
+                    "200": {
+                        "description": "Player counters",
                         "schema": {
-                            "$ref": "#/definitions/models.Match"
+                            "type": "file"
                         }
                     },
                     "400": {
-                        "description": "Bad request - invalid JSON",
-                        "schema": {
-                            "$ref": "#/definitions/models.HTTPError"
-                        }
-                    },
-                    "422": {
-                        "description": "Bad request - missing element",
+                        "description": "Bad request - invalid filter or format",
                         "schema": {
                             "$ref": "#/definitions/models.HTTPError"
                         }
@@ -97,9 +169,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/matches/{id}": {
-            "get": {
-                "description": "get match by ID from the database",
+        "/auth/setup": {
+            "post": {
+                "description": "create the first admin with the one-time setup token logged by the server when it starts without any admin. The token stops working once it has been used",
                 "consumes": [
                     "application/json"
                 ],
@@ -107,80 +179,69 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "matches"
+                    "auth"
                 ],
-                "summary": "Retrieve a match by ID",
+                "summary": "Create the first admin",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Match ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Setup token and admin details",
+                        "name": "setup",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SetupRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Match retrieved",
+                    "201": {
+                        "description": "Successfully created",
                         "schema": {
-                            "$ref": "#/definitions/models.Match"
+                            "$ref": "#/definitions/models.User"
                         }
                     },
-                    "404": {
-                        "description": "Match not found",
+                    "400": {
+                        "description": "Bad request - invalid JSON",
                         "schema": {
                             "$ref": "#/definitions/models.HTTPError"
                         }
-                    }
-                }
-            },
-            "put": {
-                "description": "update details of a match by ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "matches"
-                ],
-                "summary": "Update a match",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Match ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
                     },
-                    {
-                        "description": "Match info",
-                        "name": "match",
-                        "in": "body",
-                        "required": true,
+                    "401": {
+                        "description": "Invalid setup token",
                         "schema": {
-                            "$ref": "#/definitions/models.Match"
+                            "$ref": "#/definitions/models.HTTPError"
                         }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Match updated",
+                    },
+                    "403": {
+                        "description": "Setup already done",
                         "schema": {
-                            "$ref": "#/definitions/models.Match"
+                            "$ref": "#/definitions/models.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Match not found",
+                    "409": {
+                        "description": "Name already used",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - missing name or short password",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/models.HTTPError"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "delete a match by ID",
+            }
+        },
+        "/compare": {
+            "get": {
+                "description": "compare the counters and metrics of two or more players or matches, with differences from the first",
                 "consumes": [
                     "application/json"
                 ],
@@ -188,27 +249,87 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "matches"
+                    "compare"
                 ],
-                "summary": "Delete a match",
+                "summary": "Compare players or matches",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Match ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Comma separated player names",
+                        "name": "players",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated match IDs",
+                        "name": "matches",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "total",
+                        "description": "Compare players by total or per-match average",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches on or after this date (YYYY-MM-DD), players only",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches on or before this date (YYYY-MM-DD), players only",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches from this season, players only",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches from this competition, players only",
+                        "name": "competition",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches this team was tracked in, players only",
+                        "name": "team",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches where this team opposed the tracked players, players only",
+                        "name": "opponent",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully deleted",
+                        "description": "Comparison",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Comparison"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
                         }
                     },
                     "404": {
-                        "description": "Match not found",
+                        "description": "Player or match not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/models.HTTPError"
                         }
@@ -216,9 +337,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/spreadsheets": {
+        "/dashboards": {
             "get": {
-                "description": "get all spreadsheets from the database",
+                "description": "get the dashboards owned by the user and those shared by other users",
                 "consumes": [
                     "application/json"
                 ],
@@ -226,16 +347,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "spreadsheets"
+                    "dashboards"
                 ],
-                "summary": "Retrieve all spreadsheets",
+                "summary": "Retrieve all dashboards",
                 "responses": {
                     "200": {
-                        "description": "List of spreadsheets",
+                        "description": "List of dashboards",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Spreadsheet"
+                                "$ref": "#/definitions/models.Dashboard"
                             }
                         }
                     },
@@ -248,7 +369,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "create a new spreadsheet with the provided details",
+                "description": "create a dashboard laying out saved graphs on a grid. Graphs without a position or size take the one saved on the graph, and must fit the columns of the grid without overlapping.",
                 "consumes": [
                     "application/json"
                 ],
@@ -256,17 +377,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "spreadsheets"
+                    "dashboards"
                 ],
-                "summary": "Create a new spreadsheet",
+                "summary": "Create a new dashboard",
                 "parameters": [
                     {
-                        "description": "Spreadsheet Info",
-                        "name": "spreadsheet",
+                        "description": "Dashboard Info",
+                        "name": "dashboard",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Spreadsheet"
+                            "$ref": "#/definitions/models.Dashboard"
                         }
                     }
                 ],
@@ -274,7 +395,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Successfully created",
                         "schema": {
-                            "$ref": "#/definitions/models.Spreadsheet"
+                            "$ref": "#/definitions/models.Dashboard"
                         }
                     },
                     "400": {
@@ -284,7 +405,7 @@ const docTemplate = `{
                         }
                     },
                     "422": {
-                        "description": "Bad request - missing element",
+                        "description": "Bad request - missing name, unknown or overlapping graphs",
                         "schema": {
                             "$ref": "#/definitions/models.HTTPError"
                         }
@@ -298,9 +419,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/spreadsheets/{id}": {
+        "/dashboards/{id}": {
             "get": {
-                "description": "get spreadsheet by ID from the database",
+                "description": "get a dashboard owned by the user or shared by another user",
                 "consumes": [
                     "application/json"
                 ],
@@ -308,13 +429,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "spreadsheets"
+                    "dashboards"
                 ],
-                "summary": "Retrieve a spreadsheet by ID",
+                "summary": "Retrieve a dashboard by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Spreadsheet ID",
+                        "description": "Dashboard ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -322,13 +443,13 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Spreadsheet retrieved",
+                        "description": "Dashboard retrieved",
                         "schema": {
-                            "$ref": "#/definitions/models.Spreadsheet"
+                            "$ref": "#/definitions/models.Dashboard"
                         }
                     },
                     "404": {
-                        "description": "Spreadsheet not found",
+                        "description": "Dashboard not found",
                         "schema": {
                             "$ref": "#/definitions/models.HTTPError"
                         }
@@ -336,7 +457,7 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "update details of a spreadsheet by ID",
+                "description": "replace the name, sharing, columns and graphs of a dashboard owned by the user",
                 "consumes": [
                     "application/json"
                 ],
@@ -344,36 +465,60 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "spreadsheets"
+                    "dashboards"
                 ],
-                "summary": "Update a spreadsheet",
+                "summary": "Update a dashboard",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Spreadsheet ID",
+                        "description": "Dashboard ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Spreadsheet info",
-                        "name": "spreadsheet",
+                        "description": "Dashboard info",
+                        "name": "dashboard",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Spreadsheet"
+                            "$ref": "#/definitions/models.Dashboard"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Spreadsheet updated",
+                        "description": "Dashboard updated",
                         "schema": {
-                            "$ref": "#/definitions/models.Spreadsheet"
+                            "$ref": "#/definitions/models.Dashboard"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid JSON",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Dashboard owned by another user",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
                         }
                     },
                     "404": {
-                        "description": "Spreadsheet not found",
+                        "description": "Dashboard not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - unknown or overlapping graphs",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/models.HTTPError"
                         }
@@ -381,7 +526,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "delete a spreadsheet by ID",
+                "description": "delete a dashboard owned by the user, or any dashboard for admins. The graphs on it are kept.",
                 "consumes": [
                     "application/json"
                 ],
@@ -389,13 +534,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "spreadsheets"
+                    "dashboards"
                 ],
-                "summary": "Delete a spreadsheet",
+                "summary": "Delete a dashboard",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Spreadsheet ID",
+                        "description": "Dashboard ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -408,8 +553,14 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "403": {
+                        "description": "Dashboard owned by another user",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
                     "404": {
-                        "description": "Spreadsheet not found",
+                        "description": "Dashboard not found",
                         "schema": {
                             "$ref": "#/definitions/models.HTTPError"
                         }
@@ -417,9 +568,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/spreadsheets/{id}/player": {
+        "/dashboards/{id}/clone": {
             "post": {
-                "description": "create a new player with the provided details",
+                "description": "copy a dashboard owned by the user or shared by another user. The copy belongs to the user and is not shared.",
                 "consumes": [
                     "application/json"
                 ],
@@ -427,24 +578,97 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "spreadsheets"
+                    "dashboards"
                 ],
-                "summary": "Create a new player",
+                "summary": "Clone a dashboard",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Spreadsheet ID",
+                        "description": "Dashboard ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Player",
-                        "name": "player",
+                        "type": "string",
+                        "description": "Name of the copy, defaults to the original name followed by (copy)",
+                        "name": "name",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully cloned",
+                        "schema": {
+                            "$ref": "#/definitions/models.Dashboard"
+                        }
+                    },
+                    "404": {
+                        "description": "Dashboard not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/graphs": {
+            "get": {
+                "description": "get all graphs from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphs"
+                ],
+                "summary": "Retrieve all graphs",
+                "responses": {
+                    "200": {
+                        "description": "List of graphs",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Graph"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "create a graph plotting counters or metrics from a spreadsheet, match or saved query",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphs"
+                ],
+                "summary": "Create a new graph",
+                "parameters": [
+                    {
+                        "description": "Graph Info",
+                        "name": "graph",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Player"
+                            "$ref": "#/definitions/models.Graph"
                         }
                     }
                 ],
@@ -452,7 +676,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Successfully created",
                         "schema": {
-                            "$ref": "#/definitions/models.Spreadsheet"
+                            "$ref": "#/definitions/models.Graph"
                         }
                     },
                     "400": {
@@ -462,7 +686,7 @@ const docTemplate = `{
                         }
                     },
                     "422": {
-                        "description": "Bad request - missing element",
+                        "description": "Bad request - invalid graph or unknown data source",
                         "schema": {
                             "$ref": "#/definitions/models.HTTPError"
                         }
@@ -476,9 +700,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/spreadsheets/{id}/player/": {
-            "delete": {
-                "description": "Removes a player from the spreadsheet specified by ID.",
+        "/graphs/{id}": {
+            "get": {
+                "description": "get graph by ID from the database",
                 "consumes": [
                     "application/json"
                 ],
@@ -486,39 +710,84 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "spreadsheets"
+                    "graphs"
                 ],
-                "summary": "Remove an existing player",
+                "summary": "Retrieve a graph by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Spreadsheet ID",
+                        "description": "Graph ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Graph retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/models.Graph"
+                        }
+                    },
+                    "404": {
+                        "description": "Graph not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "update the name, type, data source, metrics, players or default layout of a graph by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphs"
+                ],
+                "summary": "Update a graph",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Graph ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Player",
-                        "name": "player",
+                        "description": "Graph info",
+                        "name": "graph",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Player"
+                            "$ref": "#/definitions/models.Graph"
                         }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Player successfully removed"
+                    "200": {
+                        "description": "Graph updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.Graph"
+                        }
                     },
                     "400": {
-                        "description": "Bad request - invalid path parameters",
+                        "description": "Bad request - invalid JSON",
                         "schema": {
                             "$ref": "#/definitions/models.HTTPError"
                         }
                     },
                     "404": {
-                        "description": "Player not found",
+                        "description": "Graph not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - invalid graph or unknown data source",
                         "schema": {
                             "$ref": "#/definitions/models.HTTPError"
                         }
@@ -530,11 +799,53 @@ const docTemplate = `{
                         }
                     }
                 }
+            },
+            "delete": {
+                "description": "delete a graph by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphs"
+                ],
+                "summary": "Delete a graph",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Graph ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Graph not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Graph placed on a dashboard",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
             }
         },
-        "/spreadsheets/{id}/player/{player}/action": {
-            "post": {
-                "description": "create a new action for the player",
+        "/graphs/{id}/data": {
+            "get": {
+                "description": "resolve the data source of a graph into labels and one series per plotted counter or metric. Spreadsheet and match sources are labelled by player, saved queries by match.",
                 "consumes": [
                     "application/json"
                 ],
@@ -542,55 +853,89 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "spreadsheets"
+                    "graphs"
                 ],
-                "summary": "Create a new player action",
+                "summary": "Retrieve the data of a graph",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Spreadsheet ID",
+                        "description": "Graph ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Series data",
+                        "schema": {
+                            "$ref": "#/definitions/models.SeriesData"
+                        }
+                    },
+                    "404": {
+                        "description": "Graph or data source not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
                     },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/graphs/{id}/render": {
+            "get": {
+                "description": "draw the data of a graph as a bar, line, radar or stacked bar chart in SVG or PNG. The height is three fifths of the width.",
+                "produces": [
+                    "image/svg+xml",
+                    "image/png"
+                ],
+                "tags": [
+                    "graphs"
+                ],
+                "summary": "Render a graph",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "Player name",
-                        "name": "player",
+                        "description": "Graph ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Action Info",
-                        "name": "playerAction",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.PlayerAction"
-                        }
+                        "type": "string",
+                        "default": "svg",
+                        "description": "Image format: svg or png",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 800,
+                        "description": "Width of the image in pixels, between 200 and 2000",
+                        "name": "width",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Successfully created",
+                    "200": {
+                        "description": "Rendered graph",
                         "schema": {
-                            "$ref": "#/definitions/models.Player"
+                            "type": "file"
                         }
                     },
                     "400": {
-                        "description": "Bad request - invalid JSON",
+                        "description": "Bad request - invalid format or width",
                         "schema": {
                             "$ref": "#/definitions/models.HTTPError"
                         }
                     },
                     "404": {
-                        "description": "Failed to find player",
-                        "schema": {
-                            "$ref": "#/definitions/models.HTTPError"
-                        }
-                    },
-                    "422": {
-                        "description": "Bad request - missing element",
+                        "description": "Graph or data source not found",
                         "schema": {
                             "$ref": "#/definitions/models.HTTPError"
                         }
@@ -603,119 +948,5095 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.Attacking": {
-            "type": "object",
-            "properties": {
-                "caught": {
-                    "type": "integer"
-                },
-                "mistake": {
-                    "type": "integer"
-                },
-                "point": {
-                    "type": "integer"
-                },
-                "short": {
-                    "type": "integer"
-                }
-            }
         },
-        "models.Defending": {
-            "type": "object",
-            "properties": {
-                "drop": {
-                    "type": "integer"
+        "/leaderboards": {
+            "get": {
+                "description": "rank players by any counter, built-in or user-defined metric within a scope. Players with equal values share a rank and are listed by name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leaderboards"
+                ],
+                "summary": "Retrieve a leaderboard",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Counter or metric to rank by",
+                        "name": "metric",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "all",
+                        "description": "Comma separated scopes such as season:2024, competition:name, opponent:team or match:id",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "total",
+                        "description": "Rank by total or per-match average",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "desc",
+                        "description": "Rank highest first (desc) or lowest first (asc)",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Minimum number of matches played",
+                        "name": "min_matches",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Entries per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Leaderboard",
+                        "schema": {
+                            "$ref": "#/definitions/models.Leaderboard"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/league/{competition}": {
+            "get": {
+                "description": "compute the standings of a competition from its finished matches",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "league"
+                ],
+                "summary": "Retrieve a league table",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Competition name",
+                        "name": "competition",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only count matches from this season",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 3,
+                        "description": "League points for a win",
+                        "name": "win",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 2,
+                        "description": "League points for a draw",
+                        "name": "draw",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "League points for a loss",
+                        "name": "loss",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "League points for the team that forfeited",
+                        "name": "forfeit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Score awarded to the opponent of a forfeit, 0 keeps the recorded score",
+                        "name": "forfeit_score",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated tiebreak order: points_difference, head_to_head, points_scored",
+                        "name": "tiebreak",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "json",
+                        "description": "Response format: json or csv",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "League table",
+                        "schema": {
+                            "$ref": "#/definitions/models.LeagueTable"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid rules",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/matches": {
+            "get": {
+                "description": "get all matches from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Retrieve all matches",
+                "responses": {
+                    "200": {
+                        "description": "List of matches",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Match"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "create a new match with the provided details",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Create a new match",
+                "parameters": [
+                    {
+                        "description": "Match Info",
+                        "name": "match",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Match"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Match"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid JSON",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - missing element",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/import": {
+            "post": {
+                "description": "create a match, its spreadsheets and its action log from an archive exported by this or another server. Every identifier is replaced. The match keeps its archived name unless another is given, and is refused if a match already uses that name.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Import a match archive",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the imported match, defaults to the archived name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Match archive",
+                        "name": "archive",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MatchArchive"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Match imported",
+                        "schema": {
+                            "$ref": "#/definitions/models.Match"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid JSON",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Match name already used",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unsupported version or inconsistent archive",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}": {
+            "get": {
+                "description": "get match by ID from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Retrieve a match by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Match retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/models.Match"
+                        }
+                    },
+                    "404": {
+                        "description": "Match not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "update details of a match by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Update a match",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Match info",
+                        "name": "match",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Match"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Match updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.Match"
+                        }
+                    },
+                    "404": {
+                        "description": "Match not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete a match by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Delete a match",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Match not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/archive": {
+            "get": {
+                "description": "download a versioned JSON archive of a match, the spreadsheet of each third, the action log and the players they name, to import on another server",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Export a match archive",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Match archive",
+                        "schema": {
+                            "$ref": "#/definitions/models.MatchArchive"
+                        }
+                    },
+                    "404": {
+                        "description": "Match not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/conceded": {
+            "post": {
+                "description": "record points scored by the opponent at the given game clock",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Record a point conceded",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Conceded point with period, clock and value",
+                        "name": "conceded",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Event"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid JSON",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Match or period not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - missing element",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/events": {
+            "get": {
+                "description": "get the player actions, substitutions and conceded points of a match in order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Retrieve the action log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Action log",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Event"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Match not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/export.xlsx": {
+            "get": {
+                "description": "download a match as an Excel workbook with a sheet of player counters for every third and a sheet of match totals",
+                "produces": [
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Export a match to XLSX",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "XLSX workbook",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Match not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/lineups/{period}": {
+            "put": {
+                "description": "set the players on court at the start of a period of the match",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Set a period lineup",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Period (first, second or third)",
+                        "name": "period",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Starting players",
+                        "name": "lineup",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Lineup"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lineup set",
+                        "schema": {
+                            "$ref": "#/definitions/models.Match"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid JSON",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Match or period not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - unknown player",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/periods/{period}/end": {
+            "post": {
+                "description": "record the end of a started period of the match in the action log at the full length of a period",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "End a period",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Period (first, second or third)",
+                        "name": "period",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Event"
+                        }
+                    },
+                    "404": {
+                        "description": "Match or period not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Period not started or already ended",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/periods/{period}/start": {
+            "post": {
+                "description": "record the start of a period of the match in the action log at game clock 0, marking a scheduled match as live",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Start a period",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Period (first, second or third)",
+                        "name": "period",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Event"
+                        }
+                    },
+                    "404": {
+                        "description": "Match or period not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Period already started",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/playing-time": {
+            "get": {
+                "description": "compute the time on court and plus/minus per player per period from the lineups and timed events. While the match is live, a period that has not ended counts up to its last timed event",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Retrieve playing time",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Playing time per player",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PlayingTime"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Match not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/report.pdf": {
+            "get": {
+                "description": "generate an A4 PDF report of a match with its teams, date and competition, the score of every third, the attacking and defending counters and metrics of every player, and charts of shots, scores and metrics",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Retrieve a match report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "PDF report",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Match not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/result": {
+            "put": {
+                "description": "set the final score of a match, optionally as a forfeit, and mark it as finished",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Record a match result",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Final result",
+                        "name": "result",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MatchResult"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Match updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.Match"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid JSON",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Match not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - invalid result",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/shares": {
+            "get": {
+                "description": "get every share link of a match, including expired and revoked ones. Tokens are only returned when a share is created",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Retrieve the share links of a match",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of shares",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Share"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "create a public read-only link to the score and statistics of a match, valid for the given number of hours, 24 by default and 720 at most. Player names are replaced by numbers if hide_players is set",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Share a match",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Share Info",
+                        "name": "share",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ShareRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatedShare"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid JSON",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Match not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - invalid expiry",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/shares/{share}": {
+            "delete": {
+                "description": "stop a share link of a match from working. The share is kept in the list of shares with the time it was revoked",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Revoke a share link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share ID",
+                        "name": "share",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully revoked",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Share not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/stats": {
+            "get": {
+                "description": "compute per-player totals, per-period breakdowns and team totals across all thirds of a match, from the spreadsheet counters, or from its action log when the logged actions add up to the same counters. The source used is reported.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Retrieve match statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Match statistics",
+                        "schema": {
+                            "$ref": "#/definitions/models.MatchStats"
+                        }
+                    },
+                    "404": {
+                        "description": "Match not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/stream": {
+            "get": {
+                "description": "stream the changes to a match as Server-Sent Events. A new client first receives a snapshot event with the match, its spreadsheets, score and trackers, then action, player_added, player_removed, score and presence events as they happen. A client reconnecting with the Last-Event-ID header receives only the events it missed, or a new snapshot if they are no longer available. A comment is sent every 15 seconds while the stream is idle",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Follow a match live",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the last event received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of events",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Match not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/substitutions": {
+            "post": {
+                "description": "record a player coming on for another at the given game clock",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Record a substitution",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Substitution with period, clock, player_in and player_out",
+                        "name": "substitution",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Event"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid JSON",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Match or period not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - missing element",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/track": {
+            "get": {
+                "description": "open a WebSocket on which a tracker records the actions of one side of the play, attacking or defending, alongside other trackers. The tracker first receives a snapshot of the match, then every live update of the match, including the actions recorded by the others and presence messages listing the connected trackers. A tracker records an action by sending {\"type\": \"action\", \"ref\": \"...\", \"period\": \"first\", \"player\": \"...\", \"action\": {\"type\": \"point\", \"value\": 1}}, answered by an ack with the player's counters or an error, both carrying the same ref. Writes to a spreadsheet are applied one at a time. The user is checked again before each message and the session is closed once they are disabled, removed or no longer allowed to track",
+                "tags": [
+                    "matches"
+                ],
+                "summary": "Track a match with other trackers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tracker role (attacking or defending)",
+                        "name": "role",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching to the WebSocket protocol"
+                    },
+                    "400": {
+                        "description": "Bad request - invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Match not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/metrics": {
+            "get": {
+                "description": "get all saved metric formulas from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "metrics"
+                ],
+                "summary": "Retrieve all user-defined metrics",
+                "responses": {
+                    "200": {
+                        "description": "List of metrics",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Metric"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "save a named metric as an expression over the action counters and built-in metrics",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "metrics"
+                ],
+                "summary": "Create a new user-defined metric",
+                "parameters": [
+                    {
+                        "description": "Metric Info",
+                        "name": "metric",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Metric"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Metric"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid JSON",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - invalid name or expression",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/metrics/definitions": {
+            "get": {
+                "description": "get the formula and description of every built-in derived metric",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "metrics"
+                ],
+                "summary": "Retrieve the built-in metrics",
+                "responses": {
+                    "200": {
+                        "description": "List of built-in metrics",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/metrics.Definition"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/metrics/{id}": {
+            "get": {
+                "description": "get metric by ID from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "metrics"
+                ],
+                "summary": "Retrieve a user-defined metric by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Metric ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Metric retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/models.Metric"
+                        }
+                    },
+                    "404": {
+                        "description": "Metric not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "update the name, expression or description of a metric by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "metrics"
+                ],
+                "summary": "Update a user-defined metric",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Metric ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Metric info",
+                        "name": "metric",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Metric"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Metric updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.Metric"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid JSON",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Metric not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - invalid name or expression",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete a metric by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "metrics"
+                ],
+                "summary": "Delete a user-defined metric",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Metric ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Metric not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/players/{id}/stats": {
+            "get": {
+                "description": "aggregate a player's totals, per-match and per-period averages over the matches selected by the filters",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "Retrieve player career statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player name",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches on or after this date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches on or before this date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches from this season",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches from this competition",
+                        "name": "competition",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches this team was tracked in",
+                        "name": "team",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches where this team opposed the tracked players",
+                        "name": "opponent",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Player statistics",
+                        "schema": {
+                            "$ref": "#/definitions/models.CareerStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "No matches found for the player",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/public/shares/{token}": {
+            "get": {
+                "description": "get the teams, result, status and score of every third of the match of a share link, without an account",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "public"
+                ],
+                "summary": "Retrieve a shared scoreboard",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Share token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Scoreboard",
+                        "schema": {
+                            "$ref": "#/definitions/models.Scoreboard"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid, expired or revoked share link",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Match not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/public/shares/{token}/stats": {
+            "get": {
+                "description": "compute the per-player totals, per-period breakdowns and team totals of the match of a share link, without an account. Player names are replaced by numbers if the share hides them",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "public"
+                ],
+                "summary": "Retrieve shared match statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Share token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Match statistics",
+                        "schema": {
+                            "$ref": "#/definitions/models.MatchStats"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid, expired or revoked share link",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Match not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/public/shares/{token}/stream": {
+            "get": {
+                "description": "stream the score of the match of a share link as Server-Sent Events, without an account. A new client first receives a snapshot event with the scoreboard, then a score event each time a point is recorded. A client reconnecting with the Last-Event-ID header receives only the events it missed. The stream ends once the share expires or is revoked",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "public"
+                ],
+                "summary": "Follow a shared scoreboard live",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Share token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the last event received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of events",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid, expired or revoked share link",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Match not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/queries": {
+            "get": {
+                "description": "get all saved match and player selections from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queries"
+                ],
+                "summary": "Retrieve all saved queries",
+                "responses": {
+                    "200": {
+                        "description": "List of queries",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Query"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "save a selection of matches and players that graphs can use as their data source",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queries"
+                ],
+                "summary": "Create a new saved query",
+                "parameters": [
+                    {
+                        "description": "Query Info",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Query"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Query"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid JSON",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - missing or duplicate name",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/queries/{id}": {
+            "get": {
+                "description": "get saved query by ID from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queries"
+                ],
+                "summary": "Retrieve a saved query by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Query ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Query retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/models.Query"
+                        }
+                    },
+                    "404": {
+                        "description": "Query not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "update the name, description, filter or players of a saved query by ID. Fields left out keep their value and an empty list of players selects the whole team",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queries"
+                ],
+                "summary": "Update a saved query",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Query ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Query info",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Query"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Query updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.Query"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid JSON",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Query not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - duplicate name",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete a saved query by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queries"
+                ],
+                "summary": "Delete a saved query",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Query ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Query not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/spreadsheets": {
+            "get": {
+                "description": "get all spreadsheets from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spreadsheets"
+                ],
+                "summary": "Retrieve all spreadsheets",
+                "responses": {
+                    "200": {
+                        "description": "List of spreadsheets",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Spreadsheet"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "create a new spreadsheet with the provided details",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spreadsheets"
+                ],
+                "summary": "Create a new spreadsheet",
+                "parameters": [
+                    {
+                        "description": "Spreadsheet Info",
+                        "name": "spreadsheet",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Spreadsheet"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Spreadsheet"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid JSON",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - missing element",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/spreadsheets/import": {
+            "post": {
+                "description": "create a spreadsheet from the rows of an XLSX or CSV file with a player column and a column per counter. Headers are matched to counters by name or alias, ignoring case, spaces and punctuation. Rows with a missing or repeated player, or counters that are not whole non-negative numbers, are reported by row and prevent the import. A dry run reports the result without saving it.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spreadsheets"
+                ],
+                "summary": "Import a spreadsheet",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "XLSX or CSV file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name of the spreadsheet, defaults to the file name",
+                        "name": "name",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sheet to import from an XLSX file, defaults to the first",
+                        "name": "sheet",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "JSON object of extra headers for the name column and counters, such as {\\",
+                        "name": "aliases",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Preview the import without saving it",
+                        "name": "dry_run",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dry run report",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportReport"
+                        }
+                    },
+                    "201": {
+                        "description": "Spreadsheet imported",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - missing, unreadable or unsupported file, or invalid aliases",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "413": {
+                        "description": "File too large, uploaded or decompressed",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Rows with errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportReport"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/spreadsheets/{id}": {
+            "get": {
+                "description": "get spreadsheet by ID from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spreadsheets"
+                ],
+                "summary": "Retrieve a spreadsheet by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Spreadsheet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Spreadsheet retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/models.Spreadsheet"
+                        }
+                    },
+                    "404": {
+                        "description": "Spreadsheet not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "update details of a spreadsheet by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spreadsheets"
+                ],
+                "summary": "Update a spreadsheet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Spreadsheet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Spreadsheet info",
+                        "name": "spreadsheet",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Spreadsheet"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Spreadsheet updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.Spreadsheet"
+                        }
+                    },
+                    "404": {
+                        "description": "Spreadsheet not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete a spreadsheet by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spreadsheets"
+                ],
+                "summary": "Delete a spreadsheet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Spreadsheet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Spreadsheet not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/spreadsheets/{id}/export.xlsx": {
+            "get": {
+                "description": "download the attacking and defending counters of every player in a spreadsheet, with totals and metrics, as an Excel workbook",
+                "produces": [
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "spreadsheets"
+                ],
+                "summary": "Export a spreadsheet to XLSX",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Spreadsheet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "XLSX workbook",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Spreadsheet not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/spreadsheets/{id}/player": {
+            "post": {
+                "description": "create a new player with the provided details",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spreadsheets"
+                ],
+                "summary": "Create a new player",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Spreadsheet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Player",
+                        "name": "player",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Player"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Spreadsheet"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid JSON",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - missing element",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/spreadsheets/{id}/player/": {
+            "delete": {
+                "description": "Removes a player from the spreadsheet specified by ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spreadsheets"
+                ],
+                "summary": "Remove an existing player",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Spreadsheet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Player",
+                        "name": "player",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Player"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Player successfully removed"
+                    },
+                    "400": {
+                        "description": "Bad request - invalid path parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Player not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/spreadsheets/{id}/player/{player}/action": {
+            "post": {
+                "description": "create a new action for the player",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spreadsheets"
+                ],
+                "summary": "Create a new player action",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Spreadsheet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Player name",
+                        "name": "player",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Action Info",
+                        "name": "playerAction",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PlayerAction"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Player"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid JSON",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Failed to find player",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - missing element",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/tournaments": {
+            "get": {
+                "description": "get all tournaments from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournaments"
+                ],
+                "summary": "Retrieve all tournaments",
+                "responses": {
+                    "200": {
+                        "description": "List of tournaments",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Tournament"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "draw the registered teams into pools and create a match for every round robin fixture",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournaments"
+                ],
+                "summary": "Create a new tournament",
+                "parameters": [
+                    {
+                        "description": "Tournament Info",
+                        "name": "tournament",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Tournament"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tournament"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid JSON",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - missing element",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/tournaments/{id}": {
+            "get": {
+                "description": "get tournament by ID from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournaments"
+                ],
+                "summary": "Retrieve a tournament by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tournament retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tournament"
+                        }
+                    },
+                    "404": {
+                        "description": "Tournament not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete a tournament by ID, the matches played in it are kept",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournaments"
+                ],
+                "summary": "Delete a tournament",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Tournament not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/tournaments/{id}/knockout": {
+            "post": {
+                "description": "seed the knockout bracket from the pool standings once every pool match is finished",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournaments"
+                ],
+                "summary": "Seed the knockout rounds",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Knockout seeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tournament"
+                        }
+                    },
+                    "404": {
+                        "description": "Tournament not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Pools unfinished or knockout already seeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/tournaments/{id}/standings": {
+            "get": {
+                "description": "compute the standings of every pool from the finished pool matches",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournaments"
+                ],
+                "summary": "Retrieve pool standings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Pool standings",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PoolStandings"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Tournament not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/trends": {
+            "get": {
+                "description": "compute a metric for a player or the team across consecutive matches with a rolling average, flagging significant changes against their own baseline",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trends"
+                ],
+                "summary": "Retrieve a trend",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Counter or metric to follow",
+                        "name": "metric",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Player name, the whole team if omitted",
+                        "name": "player",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches this team was tracked in, naming the trend after it when no player is given",
+                        "name": "team",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 3,
+                        "description": "Number of matches in the rolling average",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "default": 2,
+                        "description": "Standard errors from the baseline a rolling average must move to be flagged",
+                        "name": "threshold",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "desc",
+                        "description": "Whether higher (desc) or lower (asc) values are better",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches on or after this date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches on or before this date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches from this season",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches from this competition",
+                        "name": "competition",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only matches where this team opposed the tracked players",
+                        "name": "opponent",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Trend",
+                        "schema": {
+                            "$ref": "#/definitions/models.Trend"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "get every user with their role and whether they are disabled, without their password. Admins only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Retrieve all users",
+                "responses": {
+                    "200": {
+                        "description": "List of users",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.User"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "create a user with the given name, password and role, admin, coach, tracker or viewer, which is viewer if not given. Admins only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create a user",
+                "parameters": [
+                    {
+                        "description": "User Info",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid JSON",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Name already used",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - missing name, short password or unknown role",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "delete": {
+                "description": "delete a user by ID, who is logged out straight away. The last active admin cannot be deleted. Admins only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully deleted",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Last active admin",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/disable": {
+            "post": {
+                "description": "stop a user from logging in and log them out straight away. The last active admin cannot be disabled. Admins only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Disable a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User disabled",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Last active admin",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/enable": {
+            "post": {
+                "description": "let a disabled user log in again. Admins only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Enable a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User enabled",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/password": {
+            "put": {
+                "description": "set a new password for a user. Admins only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Reset the password of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New password",
+                        "name": "password",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password reset",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid JSON",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - short password",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/role": {
+            "put": {
+                "description": "give a user the admin, coach, tracker or viewer role, which applies to their next request. The last active admin cannot lose the admin role. Admins only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Change the role of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Role changed",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid JSON",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Last active admin",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - unknown role",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "description": "get all registered webhooks from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Retrieve all webhooks",
+                "responses": {
+                    "200": {
+                        "description": "List of webhooks",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Webhook"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "register a URL to receive a signed JSON POST for each of the given events, or for every event if none are given. A signing secret is generated if none is given, and is only returned in this response. The URL must not resolve to a loopback, private or link-local address, though loopback and private addresses are allowed when WEBHOOK_ALLOW_PRIVATE is true.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Register a webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook Info",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookWithSecret"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created, with the secret which is not returned again",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookWithSecret"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid JSON",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - missing name, invalid or internal URL or unknown event",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "description": "get webhook by ID from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Retrieve a webhook by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "update the name, URL, secret, events or disabled flag of a webhook by ID. Missing fields keep their value. Retries of pending deliveries stop once the webhook is disabled.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Update a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook info",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookWithSecret"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid JSON",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Bad request - invalid or internal URL or unknown event",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete a webhook by ID so that it receives no new events and retries of its pending deliveries stop",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "get the most recent deliveries to a webhook, newest first, with the payload and every attempt made",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Retrieve the delivery log of a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only deliveries with this status (pending, delivered or failed)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of deliveries (default 50, at most 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Delivery log",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid limit",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries/{delivery}/replay": {
+            "post": {
+                "description": "send the payload of a past delivery to the webhook again as a new delivery, retried like any other",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Replay a webhook delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "delivery",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Replay queued",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDelivery"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook or delivery not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.HTTPError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "metrics.Definition": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "formula": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Attacking": {
+            "type": "object",
+            "properties": {
+                "badPass": {
+                    "type": "integer"
+                },
+                "caught": {
+                    "type": "integer"
+                },
+                "dropPass": {
+                    "type": "integer"
+                },
+                "footing": {
+                    "type": "integer"
+                },
+                "frame": {
+                    "type": "integer"
+                },
+                "landed": {
+                    "type": "integer"
+                },
+                "point": {
+                    "type": "integer"
+                },
+                "short": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CareerStats": {
+            "type": "object",
+            "properties": {
+                "custom_metrics": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MetricValue"
+                    }
+                },
+                "filter": {
+                    "$ref": "#/definitions/models.StatsFilter"
+                },
+                "matches": {
+                    "type": "integer"
+                },
+                "metrics": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "per_match": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "per_period": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "periods": {
+                    "type": "integer"
+                },
+                "player": {
+                    "type": "string"
+                },
+                "totals": {
+                    "$ref": "#/definitions/models.Counters"
+                }
+            }
+        },
+        "models.Comparison": {
+            "type": "object",
+            "properties": {
+                "kind": {
+                    "type": "string"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ComparisonRow"
+                    }
+                },
+                "subjects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ComparisonSubject"
+                    }
+                }
+            }
+        },
+        "models.ComparisonRow": {
+            "type": "object",
+            "properties": {
+                "differences": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Difference"
+                    }
+                },
+                "metric": {
+                    "type": "string"
+                },
+                "values": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "models.ComparisonSubject": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "Player name or match ID",
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "matches": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Counters": {
+            "type": "object",
+            "properties": {
+                "attacking": {
+                    "$ref": "#/definitions/models.Attacking"
+                },
+                "defending": {
+                    "$ref": "#/definitions/models.Defending"
+                }
+            }
+        },
+        "models.CreatedShare": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "hide_players": {
+                    "description": "Replace player names by numbers",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "match": {
+                    "type": "string"
+                },
+                "path": {
+                    "description": "Path of the public scoreboard",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Dashboard": {
+            "type": "object",
+            "properties": {
+                "columns": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "graphs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DashboardGraph"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "Name of the user who created it",
+                    "type": "string"
+                },
+                "shared": {
+                    "description": "Whether other users can view and clone it",
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DashboardGraph": {
+            "type": "object",
+            "properties": {
+                "graph": {
+                    "type": "string"
+                },
+                "position": {
+                    "$ref": "#/definitions/models.Position"
+                },
+                "size": {
+                    "$ref": "#/definitions/models.Size"
+                }
+            }
+        },
+        "models.Defending": {
+            "type": "object",
+            "properties": {
+                "dig": {
+                    "type": "integer"
+                },
+                "drop": {
+                    "type": "integer"
+                },
+                "first": {
+                    "type": "integer"
+                },
+                "gap": {
+                    "type": "integer"
+                },
+                "second": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DeliveryAttempt": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Difference": {
+            "type": "object",
+            "properties": {
+                "absolute": {
+                    "type": "number"
+                },
+                "percent": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.Event": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "clock": {
+                    "description": "Seconds elapsed in the period",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "match": {
+                    "type": "string"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "player": {
+                    "type": "string"
+                },
+                "player_in": {
+                    "type": "string"
+                },
+                "player_out": {
+                    "type": "string"
+                },
+                "spreadsheet": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Fixture": {
+            "type": "object",
+            "properties": {
+                "away_team": {
+                    "type": "string"
+                },
+                "finished": {
+                    "type": "boolean"
+                },
+                "home_team": {
+                    "type": "string"
+                },
+                "match": {
+                    "type": "string"
+                },
+                "round": {
+                    "type": "integer"
+                },
+                "winner": {
+                    "description": "Not omitted so that a corrected result clears it",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Graph": {
+            "type": "object",
+            "properties": {
+                "datasource": {
+                    "type": "string"
+                },
+                "graphType": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "metrics": {
+                    "description": "Counters or metrics plotted, one series each",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "players": {
+                    "description": "Players plotted, all if empty",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "position": {
+                    "$ref": "#/definitions/models.Position"
+                },
+                "size": {
+                    "$ref": "#/definitions/models.Size"
+                },
+                "sourceType": {
+                    "description": "spreadsheet, match or query",
+                    "type": "string"
+                }
+            }
+        },
+        "models.HTTPError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ImportError": {
+            "type": "object",
+            "properties": {
+                "column": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ImportReport": {
+            "type": "object",
+            "properties": {
+                "columns": {
+                    "description": "Headers of the file keyed by the counter they were mapped to",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportError"
+                    }
+                },
+                "ignored": {
+                    "description": "Headers that did not match any counter",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "rows": {
+                    "description": "Player rows read from the file",
+                    "type": "integer"
+                },
+                "spreadsheet": {
+                    "description": "Spreadsheet created, or that would be created in a dry run",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Spreadsheet"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.Leaderboard": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LeaderboardEntry"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "metric": {
+                    "type": "string"
+                },
+                "min_matches": {
+                    "type": "integer"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "scope": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LeaderboardEntry": {
+            "type": "object",
+            "properties": {
+                "matches": {
+                    "type": "integer"
+                },
+                "player": {
+                    "type": "string"
+                },
+                "rank": {
+                    "description": "Shared by players with equal values, who are listed by name",
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.LeagueRules": {
+            "type": "object",
+            "properties": {
+                "draw": {
+                    "type": "integer"
+                },
+                "forfeit": {
+                    "description": "League points for the team that forfeited",
+                    "type": "integer"
+                },
+                "forfeit_score": {
+                    "description": "Score awarded to the opponent of a forfeit, 0 keeps the recorded score",
+                    "type": "integer"
+                },
+                "loss": {
+                    "type": "integer"
+                },
+                "tiebreakers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "win": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LeagueTable": {
+            "type": "object",
+            "properties": {
+                "competition": {
+                    "type": "string"
+                },
+                "rules": {
+                    "$ref": "#/definitions/models.LeagueRules"
+                },
+                "season": {
+                    "type": "string"
+                },
+                "standings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Standing"
+                    }
+                }
+            }
+        },
+        "models.Lineup": {
+            "type": "object",
+            "properties": {
+                "players": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.LiveScoreUpdate": {
+            "type": "object",
+            "properties": {
+                "conceded": {
+                    "type": "integer"
+                },
+                "periods": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PeriodScore"
+                    }
+                },
+                "scored": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Match": {
+            "type": "object",
+            "properties": {
+                "away_score": {
+                    "type": "integer"
+                },
+                "away_team": {
+                    "type": "string"
+                },
+                "competition": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "forfeit": {
+                    "description": "Not omitted so that correcting a result clears it",
+                    "type": "string"
+                },
+                "home_score": {
+                    "type": "integer"
+                },
+                "home_team": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lineups": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "period_length": {
+                    "description": "Seconds",
+                    "type": "integer"
+                },
+                "players": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "season": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "thirds": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "tracked_side": {
+                    "description": "Side whose players are tracked, \"home\" or \"away\", home if not set",
+                    "type": "string"
+                }
+            }
+        },
+        "models.MatchArchive": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Event"
+                    }
+                },
+                "exported_at": {
+                    "type": "string"
+                },
+                "match": {
+                    "$ref": "#/definitions/models.Match"
+                },
+                "players": {
+                    "description": "Every player named in the spreadsheets, lineups and events",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "schema_version": {
+                    "type": "integer"
+                },
+                "spreadsheets": {
+                    "description": "Keyed by period",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.Spreadsheet"
+                    }
+                }
+            }
+        },
+        "models.MatchResult": {
+            "type": "object",
+            "properties": {
+                "away_score": {
+                    "type": "integer"
+                },
+                "forfeit": {
+                    "description": "Side that forfeited, \"home\" or \"away\"",
+                    "type": "string"
+                },
+                "home_score": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MatchStats": {
+            "type": "object",
+            "properties": {
+                "custom_metrics": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MetricValue"
+                    }
+                },
+                "match": {
+                    "type": "string"
+                },
+                "metrics": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "periods": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.Counters"
+                    }
+                },
+                "players": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PlayerStats"
+                    }
+                },
+                "source": {
+                    "description": "counters or events, whichever the totals came from",
+                    "type": "string"
+                },
+                "totals": {
+                    "$ref": "#/definitions/models.Counters"
+                }
+            }
+        },
+        "models.Metric": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "expression": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MetricValue": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.PasswordRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PeriodScore": {
+            "type": "object",
+            "properties": {
+                "conceded": {
+                    "type": "integer"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "scored": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PeriodTime": {
+            "type": "object",
+            "properties": {
+                "plus_minus": {
+                    "type": "integer"
+                },
+                "seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Player": {
+            "type": "object",
+            "properties": {
+                "attacking": {
+                    "$ref": "#/definitions/models.Attacking"
+                },
+                "defending": {
+                    "$ref": "#/definitions/models.Defending"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PlayerAction": {
+            "type": "object",
+            "properties": {
+                "clock": {
+                    "description": "Seconds elapsed in the period",
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PlayerStats": {
+            "type": "object",
+            "properties": {
+                "custom_metrics": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MetricValue"
+                    }
+                },
+                "metrics": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "periods": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.Counters"
+                    }
+                },
+                "totals": {
+                    "$ref": "#/definitions/models.Counters"
+                }
+            }
+        },
+        "models.PlayingTime": {
+            "type": "object",
+            "properties": {
+                "periods": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.PeriodTime"
+                    }
+                },
+                "player": {
+                    "type": "string"
+                },
+                "plus_minus": {
+                    "type": "integer"
+                },
+                "seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Pool": {
+            "type": "object",
+            "properties": {
+                "fixtures": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Fixture"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "teams": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.PoolStandings": {
+            "type": "object",
+            "properties": {
+                "pool": {
+                    "type": "string"
+                },
+                "standings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Standing"
+                    }
+                }
+            }
+        },
+        "models.Position": {
+            "type": "object",
+            "properties": {
+                "x": {
+                    "type": "integer"
+                },
+                "y": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Query": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "filter": {
+                    "$ref": "#/definitions/models.StatsFilter"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "players": {
+                    "description": "Players totalled, the whole team if empty",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.RoleRequest": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Round": {
+            "type": "object",
+            "properties": {
+                "fixtures": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Fixture"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Scoreboard": {
+            "type": "object",
+            "properties": {
+                "away_score": {
+                    "type": "integer"
+                },
+                "away_team": {
+                    "type": "string"
+                },
+                "competition": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "forfeit": {
+                    "type": "string"
+                },
+                "home_score": {
+                    "type": "integer"
+                },
+                "home_team": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "score": {
+                    "description": "Points tracked in each third",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.LiveScoreUpdate"
+                        }
+                    ]
+                },
+                "season": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Series": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "values": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "models.SeriesData": {
+            "type": "object",
+            "properties": {
+                "labels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "first": {
+                "series": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Series"
+                    }
+                }
+            }
+        },
+        "models.SetupRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Share": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "hide_players": {
+                    "description": "Replace player names by numbers",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "match": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ShareRequest": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "description": "Hours, 24 if not given",
                     "type": "integer"
                 },
-                "gap": {
+                "hide_players": {
+                    "type": "boolean"
+                },
+                "label": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Size": {
+            "type": "object",
+            "properties": {
+                "height": {
                     "type": "integer"
                 },
-                "second": {
+                "width": {
                     "type": "integer"
                 }
             }
         },
-        "models.HTTPError": {
+        "models.Spreadsheet": {
             "type": "object",
             "properties": {
-                "code": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "players": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Player"
+                    }
+                }
+            }
+        },
+        "models.Standing": {
+            "type": "object",
+            "properties": {
+                "drawn": {
                     "type": "integer"
                 },
-                "message": {
+                "forfeited": {
+                    "type": "integer"
+                },
+                "league_points": {
+                    "type": "integer"
+                },
+                "lost": {
+                    "type": "integer"
+                },
+                "played": {
+                    "type": "integer"
+                },
+                "points_against": {
+                    "type": "integer"
+                },
+                "points_difference": {
+                    "type": "integer"
+                },
+                "points_for": {
+                    "type": "integer"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "team": {
                     "type": "string"
+                },
+                "won": {
+                    "type": "integer"
                 }
             }
         },
-        "models.Match": {
+        "models.StatsFilter": {
+            "type": "object",
+            "properties": {
+                "competition": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "matches": {
+                    "description": "Match IDs",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "opponent": {
+                    "description": "Team on the side opposing the tracked players",
+                    "type": "string"
+                },
+                "season": {
+                    "type": "string"
+                },
+                "team": {
+                    "description": "Team of the tracked players",
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Tournament": {
             "type": "object",
             "properties": {
+                "competition": {
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
+                "knockout": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Round"
+                    }
+                },
                 "name": {
                     "type": "string"
                 },
-                "players": {
+                "pool_count": {
+                    "type": "integer"
+                },
+                "pools": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/models.Pool"
                     }
                 },
-                "thirds": {
-                    "type": "object",
-                    "additionalProperties": {
+                "qualifiers_per_pool": {
+                    "type": "integer"
+                },
+                "season": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "teams": {
+                    "type": "array",
+                    "items": {
                         "type": "string"
                     }
+                },
+                "winner": {
+                    "description": "Not omitted so that a corrected final clears it",
+                    "type": "string"
                 }
             }
         },
-        "models.Player": {
+        "models.Trend": {
             "type": "object",
             "properties": {
-                "attacking": {
-                    "$ref": "#/definitions/models.Attacking"
+                "baseline": {
+                    "type": "number"
                 },
-                "defending": {
-                    "$ref": "#/definitions/models.Defending"
+                "metric": {
+                    "type": "string"
                 },
-                "name": {
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TrendPoint"
+                    }
+                },
+                "series": {
+                    "$ref": "#/definitions/models.SeriesData"
+                },
+                "std_dev": {
+                    "type": "number"
+                },
+                "subject": {
                     "type": "string"
+                },
+                "threshold": {
+                    "type": "number"
+                },
+                "window": {
+                    "type": "integer"
                 }
             }
         },
-        "models.PlayerAction": {
+        "models.TrendPoint": {
             "type": "object",
             "properties": {
-                "type": {
+                "date": {
+                    "type": "string"
+                },
+                "flag": {
+                    "type": "string"
+                },
+                "match": {
+                    "type": "string"
+                },
+                "name": {
                     "type": "string"
                 },
+                "rolling_average": {
+                    "type": "number"
+                },
                 "value": {
-                    "type": "integer"
+                    "type": "number"
                 }
             }
         },
-        "models.Spreadsheet": {
+        "models.User": {
             "type": "object",
             "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "description": "Not omitted so that enabling a user clears it",
+                    "type": "boolean"
+                },
                 "id": {
                     "type": "string"
                 },
+                "keep_logged_in": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "description": "bcrypt hash, never returned by the API",
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserRequest": {
+            "type": "object",
+            "properties": {
                 "name": {
                     "type": "string"
                 },
-                "players": {
+                "password": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "viewer if not given",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Webhook": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "events": {
+                    "description": "Every event if empty",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Player"
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DeliveryAttempt"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "replay_of": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "webhook": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookWithSecret": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "events": {
+                    "description": "Every event if empty",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
                 }
             }
         }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -23,30 +24,23 @@ import (
 // @host      localhost:8080
 // @BasePath  /
 func main() {
-	if os.Getenv("GIN_MODE") != "release" {
-		// Attempt to load the .env file from Docker path
-		err := godotenv.Load("/app/.env") // Docker Path
-		if err != nil {
-			// If not found, attempt to load the .env file from local path
-			err = godotenv.Load("../.env")
-			if err != nil {
-				log.Fatalf("Error loading .env file")
-			}
-		}
-	} else {
-		log.Println("GIN_MODE is set to release, skipping loading .env file")
+	loadEnv()
+
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1], os.Args[2:])
+		return
 	}
 
 	router := gin.Default()
 
-	connectionString := fmt.Sprintf(
-		"mongodb://%s/db?authSource=admin&ssl=true&tlsCertificateKeyFile=%s&tlsCAFile=%s",
-		os.Getenv("MONGO_HOST"),
-		os.Getenv("TLS_CERT_FILE"),
-		os.Getenv("TLS_CA_FILE"))
+	connectDatabase()
 
-	if err := database.Connect(connectionString, "Tchoukball"); err != nil {
-		logger.Log.Fatalf("Failed to connect to database: %v", err)
+	if err := handlers.BootstrapAdmin(context.Background()); err != nil {
+		logger.Log.Fatalf("Failed to check for an admin: %v", err)
+	}
+
+	if err := handlers.ResumeWebhookDeliveries(context.Background()); err != nil {
+		logger.Log.Errorf("Failed to resume webhook deliveries: %v", err)
 	}
 
 	router.GET("/", func(c *gin.Context) {
@@ -57,6 +51,20 @@ func main() {
 	handlers.RegisterSpreadsheetsRoutes(router.Group("/spreadsheets"))
 	handlers.RegisterMatchesRoutes(router.Group("/matches"))
 	handlers.RegisterAuthRoutes(router.Group("/auth"))
+	handlers.RegisterLeagueRoutes(router.Group("/league"))
+	handlers.RegisterTournamentsRoutes(router.Group("/tournaments"))
+	handlers.RegisterPlayersRoutes(router.Group("/players"))
+	handlers.RegisterMetricsRoutes(router.Group("/metrics"))
+	handlers.RegisterLeaderboardsRoutes(router.Group("/leaderboards"))
+	handlers.RegisterCompareRoutes(router.Group("/compare"))
+	handlers.RegisterTrendsRoutes(router.Group("/trends"))
+	handlers.RegisterQueriesRoutes(router.Group("/queries"))
+	handlers.RegisterGraphsRoutes(router.Group("/graphs"))
+	handlers.RegisterDashboardsRoutes(router.Group("/dashboards"))
+	handlers.RegisterAnalyticsRoutes(router.Group("/analytics"))
+	handlers.RegisterWebhooksRoutes(router.Group("/webhooks"))
+	handlers.RegisterPublicRoutes(router.Group("/public"))
+	handlers.RegisterUsersRoutes(router.Group("/users"))
 
 	logger.Log.Infof("Starting the server on port %s", os.Getenv("SERVER_PORT"))
 	if os.Getenv("GIN_MODE") != "release" {
@@ -69,3 +77,33 @@ func main() {
 		}
 	}
 }
+
+// loadEnv loads the .env file outside of release mode.
+func loadEnv() {
+	if os.Getenv("GIN_MODE") != "release" {
+		// Attempt to load the .env file from Docker path
+		err := godotenv.Load("/app/.env") // Docker Path
+		if err != nil {
+			// If not found, attempt to load the .env file from local path
+			err = godotenv.Load("../.env")
+			if err != nil {
+				log.Fatalf("Error loading .env file")
+			}
+		}
+	} else {
+		log.Println("GIN_MODE is set to release, skipping loading .env file")
+	}
+}
+
+// connectDatabase connects to MongoDB, exiting if it cannot be reached.
+func connectDatabase() {
+	connectionString := fmt.Sprintf(
+		"mongodb://%s/db?authSource=admin&ssl=true&tlsCertificateKeyFile=%s&tlsCAFile=%s",
+		os.Getenv("MONGO_HOST"),
+		os.Getenv("TLS_CERT_FILE"),
+		os.Getenv("TLS_CA_FILE"))
+
+	if err := database.Connect(connectionString, "Tchoukball"); err != nil {
+		logger.Log.Fatalf("Failed to connect to database: %v", err)
+	}
+}
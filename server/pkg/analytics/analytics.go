@@ -0,0 +1,149 @@
+// Package analytics flattens the action log and the counters of every player
+// in every third into tables, and writes them row by row as NDJSON or Parquet
+// for analysis tools such as pandas and DuckDB.
+package analytics
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/parquet"
+)
+
+var ErrUnknownFormat = errors.New("Unknown format, use ndjson or parquet")
+
+// Encoder writes the rows of a table, each holding a value per column.
+type Encoder interface {
+	Write(row []interface{}) error
+	Close() error
+}
+
+// matchColumns describe the match a row belongs to.
+var matchColumns = []parquet.Column{
+	{Name: "match_id", Type: parquet.String},
+	{Name: "match_name", Type: parquet.String},
+	{Name: "match_date", Type: parquet.Timestamp},
+	{Name: "competition", Type: parquet.String},
+	{Name: "season", Type: parquet.String},
+	{Name: "period", Type: parquet.String},
+}
+
+// ActionColumns are the columns of the action log table.
+var ActionColumns = append(append([]parquet.Column{}, matchColumns...),
+	parquet.Column{Name: "type", Type: parquet.String},
+	parquet.Column{Name: "clock", Type: parquet.Int64},
+	parquet.Column{Name: "player", Type: parquet.String},
+	parquet.Column{Name: "action", Type: parquet.String},
+	parquet.Column{Name: "value", Type: parquet.Int64},
+	parquet.Column{Name: "player_in", Type: parquet.String},
+	parquet.Column{Name: "player_out", Type: parquet.String},
+	parquet.Column{Name: "created_at", Type: parquet.Timestamp},
+)
+
+// CounterColumns are the columns of the counters table, a column per counter
+// after the player.
+var CounterColumns = counterColumns()
+
+func counterColumns() []parquet.Column {
+	columns := append(append([]parquet.Column{}, matchColumns...), parquet.Column{Name: "player", Type: parquet.String})
+	for _, names := range [][]string{models.AttackingCounters, models.DefendingCounters} {
+		for _, name := range names {
+			columns = append(columns, parquet.Column{Name: name, Type: parquet.Int64})
+		}
+	}
+	return columns
+}
+
+// NewEncoder returns an encoder writing a table with the given columns to w in
+// the given format.
+func NewEncoder(w io.Writer, format string, columns []parquet.Column) (Encoder, error) {
+	switch format {
+	case models.AnalyticsNDJSON:
+		return &ndjson{w: bufio.NewWriter(w), columns: columns}, nil
+	case models.AnalyticsParquet:
+		return parquet.NewWriter(w, columns), nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+// ActionValues returns the values of an action log row in the order of
+// ActionColumns.
+func ActionValues(row *models.ActionRow) []interface{} {
+	var clock interface{}
+	if row.Clock != nil {
+		clock = *row.Clock
+	}
+
+	return append(matchValues(row.Match.Hex(), row.MatchName, row.MatchDate, row.Competition, row.Season, row.Period),
+		nullable(row.Type),
+		clock,
+		nullable(row.Player),
+		nullable(row.Action),
+		row.Value,
+		nullable(row.PlayerIn),
+		nullable(row.PlayerOut),
+		row.CreatedAt,
+	)
+}
+
+// CounterValues returns the values of a counters row in the order of
+// CounterColumns.
+func CounterValues(row *models.CounterRow) []interface{} {
+	values := append(matchValues(row.Match.Hex(), row.MatchName, row.MatchDate, row.Competition, row.Season, row.Period), nullable(row.Player))
+
+	counters := row.Counters.Values()
+	for _, names := range [][]string{models.AttackingCounters, models.DefendingCounters} {
+		for _, name := range names {
+			values = append(values, int64(counters[name]))
+		}
+	}
+	return values
+}
+
+func matchValues(id, name string, date time.Time, competition, season, period string) []interface{} {
+	return []interface{}{id, nullable(name), date, nullable(competition), nullable(season), nullable(period)}
+}
+
+// nullable returns nil for an empty string so it is exported as null.
+func nullable(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// ndjson writes each row as a JSON object on its own line, with the keys in
+// column order.
+type ndjson struct {
+	w       *bufio.Writer
+	columns []parquet.Column
+}
+
+func (e *ndjson) Write(row []interface{}) error {
+	e.w.WriteByte('{')
+	for i, column := range e.columns {
+		if i > 0 {
+			e.w.WriteByte(',')
+		}
+		key, _ := json.Marshal(column.Name)
+		e.w.Write(key)
+		e.w.WriteByte(':')
+
+		value, err := json.Marshal(row[i])
+		if err != nil {
+			return err
+		}
+		e.w.Write(value)
+	}
+	// Write errors are kept by the buffered writer, so the last one reports them
+	_, err := e.w.WriteString("}\n")
+	return err
+}
+
+func (e *ndjson) Close() error {
+	return e.w.Flush()
+}
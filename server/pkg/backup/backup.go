@@ -0,0 +1,345 @@
+// Package backup copies every collection of the database into a zip archive
+// and loads such archives back.
+//
+// Each collection is stored as collections/<name>.bson, a file of BSON
+// documents one after another as written by mongodump. The archive ends with
+// manifest.json, which lists every collection file with its number of
+// documents, size and SHA-256 checksum. An archive is checked in full against
+// its manifest before anything is restored from it.
+package backup
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Version is the format version of the archives written by Backup. Archives
+// of any other version are refused.
+const Version = 1
+
+// ManifestName is the name of the manifest inside an archive.
+const ManifestName = "manifest.json"
+
+// maxDocumentSize is the largest document MongoDB stores, in bytes.
+const maxDocumentSize = 16 << 20
+
+// conflictBatch is the number of _id values checked for conflicts at once.
+const conflictBatch = 1000
+
+// Conflict policies decide what Restore does with a document whose _id is
+// already used in the database.
+const (
+	PolicyAbort     = "abort"     // Restore nothing if any document already exists
+	PolicySkip      = "skip"      // Keep the existing document
+	PolicyOverwrite = "overwrite" // Replace the existing document
+)
+
+// Policies lists every conflict policy.
+var Policies = []string{PolicyAbort, PolicySkip, PolicyOverwrite}
+
+var (
+	ErrNoManifest    = errors.New("Archive has no manifest")
+	ErrUnknownPolicy = errors.New("Unknown conflict policy, use abort, skip or overwrite")
+)
+
+type Manifest struct {
+	Version     int           `json:"version"`
+	CreatedAt   time.Time     `json:"created_at"`
+	Collections []*Collection `json:"collections"`
+}
+
+type Collection struct {
+	Name      string `json:"name"`
+	File      string `json:"file"`
+	Documents int    `json:"documents"`
+	Size      int64  `json:"size"` // Bytes
+	SHA256    string `json:"sha256"`
+}
+
+// Result counts the documents restored into a collection and those left out
+// because their _id was already used.
+type Result struct {
+	Name     string
+	Restored int
+	Skipped  int
+}
+
+// Backup writes every collection of the database to w as a zip archive and
+// returns its manifest. Documents are streamed from the database one at a
+// time.
+func Backup(ctx context.Context, w io.Writer) (*Manifest, error) {
+	names, err := database.Collections(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := zip.NewWriter(w)
+	manifest := &Manifest{Version: Version, CreatedAt: time.Now().UTC()}
+	for _, name := range names {
+		collection := &Collection{Name: name, File: "collections/" + name + ".bson"}
+		file, err := archive.Create(collection.File)
+		if err != nil {
+			return nil, err
+		}
+
+		checksum := sha256.New()
+		out := io.MultiWriter(file, checksum)
+		err = database.Dump(ctx, name, func(document bson.Raw) error {
+			collection.Documents++
+			collection.Size += int64(len(document))
+			_, err := out.Write(document)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("backing up %s: %w", name, err)
+		}
+
+		collection.SHA256 = hex.EncodeToString(checksum.Sum(nil))
+		manifest.Collections = append(manifest.Collections, collection)
+	}
+
+	file, err := archive.Create(ManifestName)
+	if err != nil {
+		return nil, err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, archive.Close()
+}
+
+// Verify checks that an archive has a manifest of a supported version, that
+// it holds exactly the files listed in the manifest and that every file is a
+// run of valid BSON documents matching its count, size and checksum.
+func Verify(r io.ReaderAt, size int64) (*Manifest, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	manifest, files, err := readManifest(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, collection := range manifest.Collections {
+		var documents int
+		var size int64
+		checksum := sha256.New()
+		err := readCollection(files[collection.File], checksum, func(document bson.Raw) error {
+			documents++
+			size += int64(len(document))
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", collection.File, err)
+		}
+
+		switch {
+		case documents != collection.Documents:
+			return nil, fmt.Errorf("%s holds %d documents, the manifest lists %d", collection.File, documents, collection.Documents)
+		case size != collection.Size:
+			return nil, fmt.Errorf("%s is %d bytes, the manifest lists %d", collection.File, size, collection.Size)
+		case hex.EncodeToString(checksum.Sum(nil)) != collection.SHA256:
+			return nil, fmt.Errorf("%s does not match its checksum", collection.File)
+		}
+	}
+	return manifest, nil
+}
+
+// Restore verifies an archive and loads every collection in it into the
+// database, handling documents that already exist by the given policy. With
+// PolicyAbort the database is checked for conflicts before anything is
+// written.
+func Restore(ctx context.Context, r io.ReaderAt, size int64, policy string) ([]*Result, error) {
+	if !isPolicy(policy) {
+		return nil, ErrUnknownPolicy
+	}
+
+	manifest, err := Verify(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify has already read the archive, so it is known to be a valid zip
+	// file holding every file in the manifest.
+	archive, _ := zip.NewReader(r, size)
+	_, files, _ := readManifest(archive)
+
+	if policy == PolicyAbort {
+		for _, collection := range manifest.Collections {
+			if err := checkConflicts(ctx, collection.Name, files[collection.File]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	results := make([]*Result, 0, len(manifest.Collections))
+	for _, collection := range manifest.Collections {
+		result := &Result{Name: collection.Name}
+		err := readCollection(files[collection.File], nil, func(document bson.Raw) error {
+			restored, err := database.Load(ctx, collection.Name, document, policy == PolicyOverwrite)
+			if err != nil {
+				return err
+			}
+			if restored {
+				result.Restored++
+			} else {
+				result.Skipped++
+			}
+			return nil
+		})
+		results = append(results, result)
+		if err != nil {
+			return results, fmt.Errorf("restoring %s: %w", collection.Name, err)
+		}
+	}
+	return results, nil
+}
+
+// readManifest decodes the manifest of an archive and returns it with the
+// archive's files keyed by name, refusing files the manifest does not list.
+func readManifest(archive *zip.Reader) (*Manifest, map[string]*zip.File, error) {
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	file, ok := files[ManifestName]
+	if !ok {
+		return nil, nil, ErrNoManifest
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer reader.Close()
+
+	var manifest Manifest
+	if err := json.NewDecoder(reader).Decode(&manifest); err != nil {
+		return nil, nil, fmt.Errorf("reading %s: %w", ManifestName, err)
+	}
+	if manifest.Version != Version {
+		return nil, nil, fmt.Errorf("Unsupported archive version %d, this server restores version %d", manifest.Version, Version)
+	}
+
+	listed := map[string]bool{ManifestName: true}
+	for i, collection := range manifest.Collections {
+		if collection == nil {
+			return nil, nil, fmt.Errorf("Manifest collection %d is empty", i+1)
+		}
+		if collection.Name == "" || listed[collection.File] {
+			return nil, nil, fmt.Errorf("Manifest lists %q more than once or without a name", collection.File)
+		}
+		if _, ok := files[collection.File]; !ok {
+			return nil, nil, fmt.Errorf("Archive is missing %s", collection.File)
+		}
+		listed[collection.File] = true
+	}
+	for name := range files {
+		if !listed[name] {
+			return nil, nil, fmt.Errorf("Archive holds %s, which is not in the manifest", name)
+		}
+	}
+	return &manifest, files, nil
+}
+
+// readCollection calls fn with every document of a collection file, writing
+// the raw bytes read to checksum if it is not nil.
+func readCollection(file *zip.File, checksum hash.Hash, fn func(document bson.Raw) error) error {
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	var in io.Reader = bufio.NewReader(reader)
+	if checksum != nil {
+		in = io.TeeReader(in, checksum)
+	}
+
+	var length [4]byte
+	for {
+		if _, err := io.ReadFull(in, length[:]); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		size := binary.LittleEndian.Uint32(length[:])
+		if size < 5 || size > maxDocumentSize {
+			return fmt.Errorf("invalid document length %d", size)
+		}
+
+		document := make(bson.Raw, size)
+		copy(document, length[:])
+		if _, err := io.ReadFull(in, document[4:]); err != nil {
+			return err
+		}
+		if err := document.Validate(); err != nil {
+			return err
+		}
+		if err := fn(document); err != nil {
+			return err
+		}
+	}
+}
+
+// checkConflicts returns an error if any document of a collection file has
+// an _id that is already used in the database.
+func checkConflicts(ctx context.Context, name string, file *zip.File) error {
+	var conflicts int64
+	ids := make([]interface{}, 0, conflictBatch)
+	count := func() error {
+		if len(ids) == 0 {
+			return nil
+		}
+		found, err := database.CountByIDs(ctx, name, ids)
+		conflicts += found
+		ids = ids[:0]
+		return err
+	}
+
+	err := readCollection(file, nil, func(document bson.Raw) error {
+		ids = append(ids, document.Lookup("_id"))
+		if len(ids) == conflictBatch {
+			return count()
+		}
+		return nil
+	})
+	if err == nil {
+		err = count()
+	}
+	if err != nil {
+		return fmt.Errorf("checking %s for conflicts: %w", name, err)
+	}
+
+	if conflicts > 0 {
+		return fmt.Errorf("%d documents of %s already exist, restore with the skip or overwrite policy", conflicts, name)
+	}
+	return nil
+}
+
+func isPolicy(policy string) bool {
+	for _, known := range Policies {
+		if known == policy {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,352 @@
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// memoryDatabase keeps raw documents by collection and _id, as printed by
+// bson.RawValue, implementing the calls made by Backup and Restore.
+type memoryDatabase struct {
+	models.Database
+	collections map[string]map[string]bson.Raw
+}
+
+func newMemoryDatabase(collections map[string][]bson.D) *memoryDatabase {
+	db := &memoryDatabase{collections: map[string]map[string]bson.Raw{}}
+	for name, documents := range collections {
+		db.collections[name] = map[string]bson.Raw{}
+		for _, document := range documents {
+			data, _ := bson.Marshal(document)
+			db.collections[name][bson.Raw(data).Lookup("_id").String()] = data
+		}
+	}
+	return db
+}
+
+func (db *memoryDatabase) Collections(ctx context.Context) ([]string, error) {
+	var names []string
+	for name := range db.collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (db *memoryDatabase) Dump(ctx context.Context, collection string, fn func(document bson.Raw) error) error {
+	var ids []string
+	for id := range db.collections[collection] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if err := fn(db.collections[collection][id]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *memoryDatabase) Load(ctx context.Context, collection string, document bson.Raw, overwrite bool) (bool, error) {
+	if db.collections[collection] == nil {
+		db.collections[collection] = map[string]bson.Raw{}
+	}
+	id := document.Lookup("_id").String()
+	if _, ok := db.collections[collection][id]; ok && !overwrite {
+		return false, nil
+	}
+	db.collections[collection][id] = append(bson.Raw{}, document...)
+	return true, nil
+}
+
+func (db *memoryDatabase) CountByIDs(ctx context.Context, collection string, ids []interface{}) (int64, error) {
+	var found int64
+	for _, id := range ids {
+		if _, ok := db.collections[collection][id.(bson.RawValue).String()]; ok {
+			found++
+		}
+	}
+	return found, nil
+}
+
+var testCollections = map[string][]bson.D{
+	"matches": {
+		{{Key: "_id", Value: "m1"}, {Key: "name", Value: "A v B"}, {Key: "home_score", Value: 15}},
+		{{Key: "_id", Value: "m2"}, {Key: "name", Value: "C v D"}, {Key: "home_score", Value: 9}},
+		{{Key: "_id", Value: "m3"}, {Key: "name", Value: "A v C"}},
+	},
+	"users": {
+		{{Key: "_id", Value: "u1"}, {Key: "name", Value: "admin"}},
+		{{Key: "_id", Value: "u2"}, {Key: "name", Value: "coach"}},
+	},
+}
+
+// backupOf returns an archive of the test collections.
+func backupOf(t *testing.T) []byte {
+	t.Helper()
+	database.Use(newMemoryDatabase(testCollections))
+	var buf bytes.Buffer
+	if _, err := Backup(context.Background(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	archive := backupOf(t)
+
+	manifest, err := Verify(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Version != Version || len(manifest.Collections) != 2 {
+		t.Fatalf("manifest = %+v, want version %d with 2 collections", manifest, Version)
+	}
+	for _, collection := range manifest.Collections {
+		if collection.File != "collections/"+collection.Name+".bson" || collection.Documents != len(testCollections[collection.Name]) {
+			t.Errorf("manifest lists %+v", collection)
+		}
+	}
+
+	restored := newMemoryDatabase(nil)
+	database.Use(restored)
+	results, err := Restore(context.Background(), bytes.NewReader(archive), int64(len(archive)), PolicyAbort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Result{{Name: "matches", Restored: 3}, {Name: "users", Restored: 2}}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("results = %+v, want %+v", results, want)
+	}
+	if original := newMemoryDatabase(testCollections); !reflect.DeepEqual(restored.collections, original.collections) {
+		t.Errorf("restored %v, want %v", restored.collections, original.collections)
+	}
+}
+
+func TestRestorePolicies(t *testing.T) {
+	archive := backupOf(t)
+	existing := map[string][]bson.D{"matches": {{{Key: "_id", Value: "m2"}, {Key: "name", Value: "Kept"}}}}
+
+	tests := []struct {
+		policy  string
+		results []*Result
+		name    string // Name of match m2 once restored
+		err     string
+	}{
+		{policy: PolicyAbort, err: "1 documents of matches already exist", name: "Kept"},
+		{policy: PolicySkip, results: []*Result{{Name: "matches", Restored: 2, Skipped: 1}, {Name: "users", Restored: 2}}, name: "Kept"},
+		{policy: PolicyOverwrite, results: []*Result{{Name: "matches", Restored: 3}, {Name: "users", Restored: 2}}, name: "C v D"},
+		{policy: "merge", err: ErrUnknownPolicy.Error(), name: "Kept"},
+	}
+
+	for _, test := range tests {
+		db := newMemoryDatabase(existing)
+		database.Use(db)
+		results, err := Restore(context.Background(), bytes.NewReader(archive), int64(len(archive)), test.policy)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: error = %v, want %q", test.policy, err, test.err)
+			}
+			if len(db.collections) != 1 || len(db.collections["matches"]) != 1 {
+				t.Errorf("%s: restored documents despite the error: %v", test.policy, db.collections)
+			}
+		} else if err != nil {
+			t.Errorf("%s: %v", test.policy, err)
+		} else if !reflect.DeepEqual(results, test.results) {
+			t.Errorf("%s: results = %+v, want %+v", test.policy, results, test.results)
+		}
+
+		if name := db.collections["matches"][`"m2"`].Lookup("name").StringValue(); name != test.name {
+			t.Errorf("%s: match m2 is named %q, want %q", test.policy, name, test.name)
+		}
+	}
+}
+
+// writeArchive zips files, adding the manifest encoded as JSON unless it is
+// nil.
+func writeArchive(t *testing.T, files map[string][]byte, manifest interface{}) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, data := range files {
+		file, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.Write(data)
+	}
+	if manifest != nil {
+		file, err := archive.Create(ManifestName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		json.NewEncoder(file).Encode(manifest)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// collectionFile encodes documents as a collection file and returns it with
+// its entry in the manifest.
+func collectionFile(name string, documents ...[]byte) ([]byte, *Collection) {
+	data := bytes.Join(documents, nil)
+	checksum := sha256.Sum256(data)
+	return data, &Collection{Name: name, File: "collections/" + name + ".bson", Documents: len(documents), Size: int64(len(data)), SHA256: hex.EncodeToString(checksum[:])}
+}
+
+func TestVerifyRefusesDamagedArchives(t *testing.T) {
+	first, _ := bson.Marshal(bson.D{{Key: "_id", Value: "m1"}, {Key: "name", Value: "A v B"}})
+	second, _ := bson.Marshal(bson.D{{Key: "_id", Value: "m2"}, {Key: "name", Value: "C v D"}})
+
+	tests := []struct {
+		name string
+		// change damages a valid archive of one collection file
+		change func(files map[string][]byte, manifest *Manifest) interface{}
+		err    string
+	}{
+		{
+			name:   "valid",
+			change: func(files map[string][]byte, manifest *Manifest) interface{} { return manifest },
+		},
+		{
+			name: "tampered checksum",
+			change: func(files map[string][]byte, manifest *Manifest) interface{} {
+				manifest.Collections[0].SHA256 = strings.Repeat("0", 64)
+				return manifest
+			},
+			err: "does not match its checksum",
+		},
+		{
+			name: "tampered document",
+			change: func(files map[string][]byte, manifest *Manifest) interface{} {
+				data := files["collections/matches.bson"]
+				data[bytes.Index(data, []byte("C v D"))] = 'X'
+				return manifest
+			},
+			err: "does not match its checksum",
+		},
+		{
+			name: "missing file",
+			change: func(files map[string][]byte, manifest *Manifest) interface{} {
+				delete(files, "collections/matches.bson")
+				return manifest
+			},
+			err: "Archive is missing collections/matches.bson",
+		},
+		{
+			name: "extra file",
+			change: func(files map[string][]byte, manifest *Manifest) interface{} {
+				files["collections/users.bson"] = first
+				return manifest
+			},
+			err: "Archive holds collections/users.bson, which is not in the manifest",
+		},
+		{
+			name: "listed twice",
+			change: func(files map[string][]byte, manifest *Manifest) interface{} {
+				manifest.Collections = append(manifest.Collections, manifest.Collections[0])
+				return manifest
+			},
+			err: "more than once",
+		},
+		{
+			name:   "no manifest",
+			change: func(files map[string][]byte, manifest *Manifest) interface{} { return nil },
+			err:    ErrNoManifest.Error(),
+		},
+		{
+			name:   "null manifest",
+			change: func(files map[string][]byte, manifest *Manifest) interface{} { return json.RawMessage("null") },
+			err:    "Unsupported archive version 0",
+		},
+		{
+			name: "null collection",
+			change: func(files map[string][]byte, manifest *Manifest) interface{} {
+				return json.RawMessage(`{"version": 1, "collections": [null]}`)
+			},
+			err: "Manifest collection 1 is empty",
+		},
+		{
+			name: "newer version",
+			change: func(files map[string][]byte, manifest *Manifest) interface{} {
+				manifest.Version = Version + 1
+				return manifest
+			},
+			err: "Unsupported archive version",
+		},
+		{
+			name: "wrong document count",
+			change: func(files map[string][]byte, manifest *Manifest) interface{} {
+				manifest.Collections[0].Documents = 3
+				return manifest
+			},
+			err: "holds 2 documents, the manifest lists 3",
+		},
+		{
+			name: "oversized document length",
+			change: func(files map[string][]byte, manifest *Manifest) interface{} {
+				files["collections/matches.bson"] = []byte{0xff, 0xff, 0xff, 0x7f, 0}
+				return manifest
+			},
+			err: "invalid document length 2147483647",
+		},
+		{
+			name: "undersized document length",
+			change: func(files map[string][]byte, manifest *Manifest) interface{} {
+				files["collections/matches.bson"] = []byte{4, 0, 0, 0}
+				return manifest
+			},
+			err: "invalid document length 4",
+		},
+		{
+			name: "truncated document",
+			change: func(files map[string][]byte, manifest *Manifest) interface{} {
+				files["collections/matches.bson"] = first[:len(first)-3]
+				return manifest
+			},
+			err: "unexpected EOF",
+		},
+	}
+
+	for _, test := range tests {
+		data, collection := collectionFile("matches", first, second)
+		files := map[string][]byte{collection.File: append([]byte{}, data...)}
+		manifest := test.change(files, &Manifest{Version: Version, Collections: []*Collection{collection}})
+		archive := writeArchive(t, files, manifest)
+
+		_, err := Verify(bytes.NewReader(archive), int64(len(archive)))
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%s: %v", test.name, err)
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("%s: error = %v, want %q", test.name, err, test.err)
+		}
+
+		// Restore must refuse the archive before writing anything
+		db := newMemoryDatabase(nil)
+		database.Use(db)
+		_, err = Restore(context.Background(), bytes.NewReader(archive), int64(len(archive)), PolicyOverwrite)
+		if test.err != "" && (err == nil || len(db.collections) != 0) {
+			t.Errorf("%s: restore = %v, restored %v", test.name, err, db.collections)
+		}
+	}
+
+	if _, err := Verify(bytes.NewReader([]byte("not a zip file")), 14); err == nil || errors.Is(err, ErrNoManifest) {
+		t.Errorf("verify of a file that is not a zip archive = %v", err)
+	}
+}
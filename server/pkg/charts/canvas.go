@@ -0,0 +1,32 @@
+package charts
+
+import "image/color"
+
+// Point is a position on a canvas, in pixels from the top left corner.
+type Point struct {
+	X, Y float64
+}
+
+// Anchor aligns text horizontally on the position it is drawn at.
+type Anchor int
+
+const (
+	AnchorStart Anchor = iota
+	AnchorMiddle
+	AnchorEnd
+)
+
+// Canvas is a surface charts are drawn onto. Text is positioned on its baseline.
+type Canvas interface {
+	Size() (width, height float64)
+	Rect(x, y, width, height float64, fill color.NRGBA)
+	Polyline(points []Point, width float64, stroke color.NRGBA)
+	Polygon(points []Point, fill color.NRGBA)
+	Text(x, y float64, text string, size float64, anchor Anchor, fill color.NRGBA)
+}
+
+// TextWidth estimates the width of text in a sans-serif font, for laying out
+// labels the same way whichever canvas they are drawn on.
+func TextWidth(text string, size float64) float64 {
+	return float64(len([]rune(text))) * size * 0.55
+}
@@ -0,0 +1,337 @@
+// Package charts draws the series of a graph as bar, line, radar or stacked
+// bar charts onto a canvas, which the SVG and raster backends turn into images.
+package charts
+
+import (
+	"errors"
+	"image/color"
+	"math"
+	"strconv"
+
+	"github.com/Tchoukball-Tracker/pkg/models"
+)
+
+var ErrUnknownType = errors.New("Unknown graph type, use bar, line, radar or stackedBar")
+
+var (
+	background = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	foreground = color.NRGBA{R: 0x33, G: 0x33, B: 0x33, A: 0xff}
+	gridColor  = color.NRGBA{R: 0xdd, G: 0xdd, B: 0xdd, A: 0xff}
+)
+
+// Palette holds the colours given to series in order, repeating when there
+// are more series than colours.
+var Palette = []color.NRGBA{
+	{R: 0x1f, G: 0x77, B: 0xb4, A: 0xff},
+	{R: 0xff, G: 0x7f, B: 0x0e, A: 0xff},
+	{R: 0x2c, G: 0xa0, B: 0x2c, A: 0xff},
+	{R: 0xd6, G: 0x27, B: 0x28, A: 0xff},
+	{R: 0x94, G: 0x67, B: 0xbd, A: 0xff},
+	{R: 0x8c, G: 0x56, B: 0x4b, A: 0xff},
+	{R: 0xe3, G: 0x77, B: 0xc2, A: 0xff},
+	{R: 0x7f, G: 0x7f, B: 0x7f, A: 0xff},
+}
+
+const (
+	margin    = 16.0
+	fontSize  = 12.0
+	titleSize = 16.0
+	ticks     = 5
+)
+
+func seriesColor(i int) color.NRGBA {
+	return Palette[i%len(Palette)]
+}
+
+// Draw draws the data as a chart of the given graph type filling the canvas,
+// with the title above it and a legend of the series below.
+func Draw(canvas Canvas, graphType, title string, data *models.SeriesData) error {
+	draw, ok := map[string]func(Canvas, *models.SeriesData, box){
+		models.GraphBar:        drawBar,
+		models.GraphStackedBar: drawStackedBar,
+		models.GraphLine:       drawLine,
+		models.GraphRadar:      drawRadar,
+	}[graphType]
+	if !ok {
+		return ErrUnknownType
+	}
+
+	width, height := canvas.Size()
+	canvas.Rect(0, 0, width, height, background)
+
+	area := box{x: margin, y: margin, width: width - 2*margin, height: height - 2*margin}
+	if title != "" {
+		canvas.Text(width/2, margin+titleSize, title, titleSize, AnchorMiddle, foreground)
+		area.y += titleSize + margin
+		area.height -= titleSize + margin
+	}
+
+	if len(data.Labels) == 0 || len(data.Series) == 0 {
+		canvas.Text(width/2, area.y+area.height/2, "No data", fontSize, AnchorMiddle, foreground)
+		return nil
+	}
+
+	area.height -= drawLegend(canvas, data, area)
+	draw(canvas, data, area)
+	return nil
+}
+
+// box is a rectangular area of the canvas.
+type box struct {
+	x, y, width, height float64
+}
+
+// drawLegend draws a swatch and the name of every series along the bottom of
+// the area, wrapping onto more rows as needed, and returns the height used.
+func drawLegend(canvas Canvas, data *models.SeriesData, area box) float64 {
+	const swatch = fontSize
+	rowHeight := fontSize + 6
+
+	var rows [][]int
+	rowWidth := 0.0
+	for i, series := range data.Series {
+		itemWidth := swatch + 4 + TextWidth(series.Name, fontSize) + margin
+		if len(rows) == 0 || (rowWidth+itemWidth > area.width && rowWidth > 0) {
+			rows = append(rows, nil)
+			rowWidth = 0
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], i)
+		rowWidth += itemWidth
+	}
+
+	top := area.y + area.height - float64(len(rows))*rowHeight
+	for r, row := range rows {
+		x := area.x
+		y := top + float64(r)*rowHeight
+		for _, i := range row {
+			name := data.Series[i].Name
+			canvas.Rect(x, y, swatch, swatch, seriesColor(i))
+			canvas.Text(x+swatch+4, y+swatch-2, name, fontSize, AnchorStart, foreground)
+			x += swatch + 4 + TextWidth(name, fontSize) + margin
+		}
+	}
+	return float64(len(rows))*rowHeight + margin/2
+}
+
+// scale maps values onto the vertical axis of a plot.
+type scale struct {
+	min, max, step float64
+}
+
+// niceScale covers the range of values with round tick steps, always
+// including zero.
+func niceScale(low, high float64) scale {
+	low, high = math.Min(low, 0), math.Max(high, 0)
+	if low == high {
+		high = low + 1
+	}
+
+	raw := (high - low) / ticks
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude * 10
+	for _, factor := range []float64{1, 2, 5} {
+		if raw <= factor*magnitude {
+			step = factor * magnitude
+			break
+		}
+	}
+	return scale{min: math.Floor(low/step) * step, max: math.Ceil(high/step) * step, step: step}
+}
+
+func (s scale) label(value float64) string {
+	decimals := 0
+	if s.step < 1 {
+		decimals = int(math.Ceil(-math.Log10(s.step)))
+	}
+	return strconv.FormatFloat(value, 'f', decimals, 64)
+}
+
+// axes draws the value gridlines and the labels of a bar or line chart and
+// returns the plot area inside them.
+func axes(canvas Canvas, labels []string, s scale, area box) box {
+	valueWidth := 0.0
+	for v := s.min; v <= s.max+s.step/2; v += s.step {
+		valueWidth = math.Max(valueWidth, TextWidth(s.label(v), fontSize))
+	}
+
+	plot := box{x: area.x + valueWidth + 6, y: area.y, width: area.width - valueWidth - 6, height: area.height - fontSize - 6}
+	for v := s.min; v <= s.max+s.step/2; v += s.step {
+		y := plot.y + plot.height - (v-s.min)/(s.max-s.min)*plot.height
+		canvas.Polyline([]Point{{plot.x, y}, {plot.x + plot.width, y}}, 1, gridColor)
+		canvas.Text(plot.x-6, y+fontSize/3, s.label(v), fontSize, AnchorEnd, foreground)
+	}
+
+	band := plot.width / float64(len(labels))
+	for i, label := range labels {
+		canvas.Text(plot.x+band*(float64(i)+0.5), area.y+area.height, fit(label, band), fontSize, AnchorMiddle, foreground)
+	}
+	return plot
+}
+
+// fit shortens text with an ellipsis until it is no wider than width.
+func fit(text string, width float64) string {
+	runes := []rune(text)
+	if TextWidth(text, fontSize) <= width {
+		return text
+	}
+	for len(runes) > 0 && TextWidth(string(runes)+"…", fontSize) > width {
+		runes = runes[:len(runes)-1]
+	}
+	if len(runes) == 0 {
+		return ""
+	}
+	return string(runes) + "…"
+}
+
+// valueRange returns the lowest and highest values of the series, ignoring gaps.
+func valueRange(series []models.Series) (float64, float64) {
+	low, high := 0.0, 0.0
+	for _, s := range series {
+		for _, v := range s.Values {
+			if v != nil {
+				low, high = math.Min(low, *v), math.Max(high, *v)
+			}
+		}
+	}
+	return low, high
+}
+
+func (s scale) y(plot box, value float64) float64 {
+	return plot.y + plot.height - (value-s.min)/(s.max-s.min)*plot.height
+}
+
+func drawBar(canvas Canvas, data *models.SeriesData, area box) {
+	s := niceScale(valueRange(data.Series))
+	plot := axes(canvas, data.Labels, s, area)
+
+	band := plot.width / float64(len(data.Labels))
+	barWidth := band * 0.8 / float64(len(data.Series))
+	for i, series := range data.Series {
+		for j, v := range series.Values {
+			if v == nil {
+				continue
+			}
+			x := plot.x + band*float64(j) + band*0.1 + barWidth*float64(i)
+			top, bottom := s.y(plot, math.Max(*v, 0)), s.y(plot, math.Min(*v, 0))
+			canvas.Rect(x, top, barWidth, bottom-top, seriesColor(i))
+		}
+	}
+}
+
+func drawStackedBar(canvas Canvas, data *models.SeriesData, area box) {
+	positive := make([]float64, len(data.Labels))
+	negative := make([]float64, len(data.Labels))
+	for _, series := range data.Series {
+		for j, v := range series.Values {
+			if v != nil && *v > 0 {
+				positive[j] += *v
+			} else if v != nil {
+				negative[j] += *v
+			}
+		}
+	}
+
+	low, high := 0.0, 0.0
+	for j := range data.Labels {
+		low, high = math.Min(low, negative[j]), math.Max(high, positive[j])
+	}
+	s := niceScale(low, high)
+	plot := axes(canvas, data.Labels, s, area)
+
+	band := plot.width / float64(len(data.Labels))
+	up := make([]float64, len(data.Labels))
+	down := make([]float64, len(data.Labels))
+	for i, series := range data.Series {
+		for j, v := range series.Values {
+			if v == nil || *v == 0 {
+				continue
+			}
+			stack := up
+			if *v < 0 {
+				stack = down
+			}
+			start := stack[j]
+			stack[j] += *v
+			top, bottom := s.y(plot, math.Max(start, stack[j])), s.y(plot, math.Min(start, stack[j]))
+			canvas.Rect(plot.x+band*float64(j)+band*0.2, top, band*0.6, bottom-top, seriesColor(i))
+		}
+	}
+}
+
+func drawLine(canvas Canvas, data *models.SeriesData, area box) {
+	s := niceScale(valueRange(data.Series))
+	plot := axes(canvas, data.Labels, s, area)
+
+	band := plot.width / float64(len(data.Labels))
+	for i, series := range data.Series {
+		var line []Point
+		for j, v := range series.Values {
+			if v == nil {
+				canvas.Polyline(line, 2, seriesColor(i))
+				line = nil
+				continue
+			}
+			point := Point{plot.x + band*(float64(j)+0.5), s.y(plot, *v)}
+			line = append(line, point)
+			canvas.Rect(point.X-3, point.Y-3, 6, 6, seriesColor(i))
+		}
+		canvas.Polyline(line, 2, seriesColor(i))
+	}
+}
+
+func drawRadar(canvas Canvas, data *models.SeriesData, area box) {
+	_, high := valueRange(data.Series)
+	s := niceScale(0, high)
+
+	radius := math.Min(area.width, area.height)/2 - fontSize - 6
+	centre := Point{area.x + area.width/2, area.y + area.height/2}
+	spoke := func(j int, value float64) Point {
+		angle := 2*math.Pi*float64(j)/float64(len(data.Labels)) - math.Pi/2
+		r := radius * value / s.max
+		return Point{centre.X + r*math.Cos(angle), centre.Y + r*math.Sin(angle)}
+	}
+
+	for v := s.step; v <= s.max+s.step/2; v += s.step {
+		var ring []Point
+		for j := range data.Labels {
+			ring = append(ring, spoke(j, v))
+		}
+		canvas.Polyline(append(ring, ring[0]), 1, gridColor)
+		canvas.Text(centre.X+3, centre.Y-radius*v/s.max+fontSize*0.8+2, s.label(v), fontSize*0.8, AnchorStart, foreground)
+	}
+
+	band := 2 * math.Pi * radius / float64(len(data.Labels))
+	for j, label := range data.Labels {
+		canvas.Polyline([]Point{centre, spoke(j, s.max)}, 1, gridColor)
+
+		end := spoke(j, s.max)
+		anchor := AnchorMiddle
+		if end.X > centre.X+1 {
+			anchor = AnchorStart
+		} else if end.X < centre.X-1 {
+			anchor = AnchorEnd
+		}
+		y := end.Y + fontSize/3
+		if end.Y < centre.Y-1 {
+			y -= 6
+		} else if end.Y > centre.Y+1 {
+			y += fontSize / 2
+		}
+		canvas.Text(end.X, y, fit(label, math.Max(band, area.width/2-radius)), fontSize, anchor, foreground)
+	}
+
+	for i, series := range data.Series {
+		var outline []Point
+		for j, v := range series.Values {
+			value := 0.0
+			if v != nil {
+				value = math.Max(*v, 0)
+			}
+			outline = append(outline, spoke(j, value))
+		}
+		fill := seriesColor(i)
+		fill.A = 0x40
+		canvas.Polygon(outline, fill)
+		canvas.Polyline(append(outline, outline[0]), 2, seriesColor(i))
+	}
+}
@@ -0,0 +1,127 @@
+package charts
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"io"
+	"math"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/Tchoukball-Tracker/pkg/models"
+)
+
+func sampleData(series, labels int) *models.SeriesData {
+	data := &models.SeriesData{}
+	for l := 0; l < labels; l++ {
+		data.Labels = append(data.Labels, "label "+strconv.Itoa(l))
+	}
+	for s := 0; s < series; s++ {
+		values := make([]*float64, labels)
+		for l := range values {
+			if (s+l)%5 != 4 {
+				v := float64((s+1)*(l+1)%17) - 3
+				values[l] = &v
+			}
+		}
+		data.Series = append(data.Series, models.Series{Name: "series " + strconv.Itoa(s), Values: values})
+	}
+	return data
+}
+
+var graphTypes = []string{models.GraphBar, models.GraphStackedBar, models.GraphLine, models.GraphRadar}
+
+func TestRenderPNG(t *testing.T) {
+	for _, graphType := range graphTypes {
+		canvas := NewRaster(400, 240)
+		if err := Draw(canvas, graphType, "Points", sampleData(3, 6)); err != nil {
+			t.Fatalf("%s: %v", graphType, err)
+		}
+
+		var buffer bytes.Buffer
+		if err := png.Encode(&buffer, canvas.Image()); err != nil {
+			t.Fatalf("%s: %v", graphType, err)
+		}
+		decoded, err := png.Decode(&buffer)
+		if err != nil {
+			t.Fatalf("%s: %v", graphType, err)
+		}
+		if decoded.Bounds().Dx() != 400 || decoded.Bounds().Dy() != 240 {
+			t.Errorf("%s: image is %v, want 400x240", graphType, decoded.Bounds())
+		}
+
+		// The first series must have been drawn somewhere
+		found := false
+		bounds := canvas.Image().Bounds()
+		for y := bounds.Min.Y; y < bounds.Max.Y && !found; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X && !found; x++ {
+				r, g, b, _ := canvas.Image().At(x, y).RGBA()
+				want := Palette[0]
+				found = r>>8 == uint32(want.R) && g>>8 == uint32(want.G) && b>>8 == uint32(want.B)
+			}
+		}
+		if !found {
+			t.Errorf("%s: no pixel in the colour of the first series", graphType)
+		}
+	}
+}
+
+func TestRenderSVG(t *testing.T) {
+	for _, graphType := range graphTypes {
+		canvas := NewSVG(400, 240)
+		if err := Draw(canvas, graphType, "Points <&> \"quoted\"", sampleData(3, 6)); err != nil {
+			t.Fatalf("%s: %v", graphType, err)
+		}
+
+		var buffer bytes.Buffer
+		if _, err := canvas.WriteTo(&buffer); err != nil {
+			t.Fatalf("%s: %v", graphType, err)
+		}
+		decoder := xml.NewDecoder(&buffer)
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s: invalid SVG: %v", graphType, err)
+			}
+		}
+	}
+}
+
+func TestRenderUnknownType(t *testing.T) {
+	if err := Draw(NewSVG(400, 240), "pie", "", sampleData(1, 1)); err != ErrUnknownType {
+		t.Errorf("got %v, want %v", err, ErrUnknownType)
+	}
+}
+
+func TestRasterShapesOutsideImage(t *testing.T) {
+	canvas := NewRaster(100, 100)
+	canvas.Polygon([]Point{{-50, -50}, {-10, -50}, {-10, -10}}, Palette[0])
+	canvas.Polygon([]Point{{math.NaN(), 0}, {10, 10}, {0, 10}}, Palette[0])
+	canvas.Polygon([]Point{{-1e300, 200}, {1e300, 200}, {0, 1e300}}, Palette[1])
+	canvas.Polyline([]Point{{-50, 50}, {150, 50}}, 4, Palette[0])
+
+	if r, g, b, _ := canvas.Image().At(50, 50).RGBA(); r>>8 != uint32(Palette[0].R) || g>>8 != uint32(Palette[0].G) || b>>8 != uint32(Palette[0].B) {
+		t.Errorf("line across the image not drawn, got %d %d %d", r>>8, g>>8, b>>8)
+	}
+	if _, _, _, a := canvas.Image().At(5, 5).RGBA(); a != 0 {
+		t.Errorf("shape outside the image drawn at 5,5")
+	}
+}
+
+func TestRasterMemory(t *testing.T) {
+	// Every bar used to allocate a rasterizer the size of the whole image
+	data := sampleData(40, 20)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if err := Draw(NewRaster(2000, 1200), models.GraphBar, "Points", data); err != nil {
+		t.Fatal(err)
+	}
+	runtime.ReadMemStats(&after)
+
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 64<<20 {
+		t.Errorf("drawing %d bars allocated %d MB, want at most 64 MB", 40*20, allocated>>20)
+	}
+}
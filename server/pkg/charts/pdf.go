@@ -0,0 +1,60 @@
+package charts
+
+import (
+	"image/color"
+
+	"github.com/Tchoukball-Tracker/pkg/pdf"
+)
+
+// PDF is a canvas drawing as vector shapes onto an area of a PDF page, so
+// that charts print sharply at any size.
+type PDF struct {
+	page                *pdf.Page
+	x, y, width, height float64
+}
+
+// NewPDF returns a canvas covering the area of the page with its top left
+// corner at x, y.
+func NewPDF(page *pdf.Page, x, y, width, height float64) *PDF {
+	return &PDF{page: page, x: x, y: y, width: width, height: height}
+}
+
+// Size implements Canvas.
+func (p *PDF) Size() (float64, float64) {
+	return p.width, p.height
+}
+
+// Rect implements Canvas.
+func (p *PDF) Rect(x, y, width, height float64, fill color.NRGBA) {
+	p.page.Rect(p.x+x, p.y+y, width, height, fill)
+}
+
+// Polyline implements Canvas.
+func (p *PDF) Polyline(points []Point, width float64, stroke color.NRGBA) {
+	p.page.Polyline(p.points(points), width, stroke)
+}
+
+// Polygon implements Canvas.
+func (p *PDF) Polygon(points []Point, fill color.NRGBA) {
+	p.page.Polygon(p.points(points), fill)
+}
+
+// Text implements Canvas.
+func (p *PDF) Text(x, y float64, text string, size float64, anchor Anchor, fill color.NRGBA) {
+	width := pdf.TextWidth(text, pdf.Helvetica, size)
+	switch anchor {
+	case AnchorMiddle:
+		x -= width / 2
+	case AnchorEnd:
+		x -= width
+	}
+	p.page.Text(p.x+x, p.y+y, text, pdf.Helvetica, size, fill)
+}
+
+func (p *PDF) points(points []Point) []pdf.Point {
+	translated := make([]pdf.Point, len(points))
+	for i, point := range points {
+		translated[i] = pdf.Point{X: p.x + point.X, Y: p.y + point.Y}
+	}
+	return translated
+}
@@ -0,0 +1,172 @@
+package charts
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// regular is the font text is drawn in on raster canvases.
+var regular, _ = opentype.Parse(goregular.TTF)
+
+// Raster is a canvas drawing into an anti-aliased bitmap image.
+type Raster struct {
+	image  *image.RGBA
+	faces  map[float64]font.Face
+	shapes *vector.Rasterizer // Reused for every shape to keep its buffer
+}
+
+// NewRaster returns an empty raster canvas of the given size in pixels.
+func NewRaster(width, height int) *Raster {
+	return &Raster{
+		image: image.NewRGBA(image.Rect(0, 0, width, height)),
+		faces: make(map[float64]font.Face),
+	}
+}
+
+// Image returns the image drawn so far.
+func (r *Raster) Image() *image.RGBA {
+	return r.image
+}
+
+// Size implements Canvas.
+func (r *Raster) Size() (float64, float64) {
+	bounds := r.image.Bounds()
+	return float64(bounds.Dx()), float64(bounds.Dy())
+}
+
+// Rect implements Canvas.
+func (r *Raster) Rect(x, y, width, height float64, fill color.NRGBA) {
+	r.Polygon([]Point{{x, y}, {x + width, y}, {x + width, y + height}, {x, y + height}}, fill)
+}
+
+// Polyline implements Canvas. Every segment is filled as a quadrilateral of
+// the line width, with a square cap at each point to close the joins.
+func (r *Raster) Polyline(points []Point, width float64, stroke color.NRGBA) {
+	if len(points) < 2 {
+		return
+	}
+
+	half := width / 2
+	rasterizer, area := r.rasterizer(points, half)
+	if area.Empty() {
+		return
+	}
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		length := math.Hypot(b.X-a.X, b.Y-a.Y)
+		if length == 0 {
+			continue
+		}
+		nx, ny := -(b.Y-a.Y)/length*half, (b.X-a.X)/length*half
+		path(rasterizer, area.Min, []Point{{a.X + nx, a.Y + ny}, {b.X + nx, b.Y + ny}, {b.X - nx, b.Y - ny}, {a.X - nx, a.Y - ny}})
+	}
+	for _, p := range points[1 : len(points)-1] {
+		path(rasterizer, area.Min, []Point{{p.X - half, p.Y - half}, {p.X + half, p.Y - half}, {p.X + half, p.Y + half}, {p.X - half, p.Y + half}})
+	}
+	r.fill(rasterizer, area, stroke)
+}
+
+// Polygon implements Canvas.
+func (r *Raster) Polygon(points []Point, fill color.NRGBA) {
+	if len(points) < 3 {
+		return
+	}
+
+	rasterizer, area := r.rasterizer(points, 0)
+	if area.Empty() {
+		return
+	}
+	path(rasterizer, area.Min, points)
+	r.fill(rasterizer, area, fill)
+}
+
+// Text implements Canvas.
+func (r *Raster) Text(x, y float64, text string, size float64, anchor Anchor, fill color.NRGBA) {
+	drawer := &font.Drawer{Dst: r.image, Src: image.NewUniform(fill), Face: r.face(size)}
+	width := float64(drawer.MeasureString(text)) / 64
+	switch anchor {
+	case AnchorMiddle:
+		x -= width / 2
+	case AnchorEnd:
+		x -= width
+	}
+	drawer.Dot = fixed.Point26_6{X: fixed.Int26_6(x * 64), Y: fixed.Int26_6(y * 64)}
+	drawer.DrawString(text)
+}
+
+// rasterizer returns the rasterizer of the canvas reset to the area covered by
+// the points grown by pad on every side, clipped to the image, so that drawing
+// a small shape only needs a buffer the size of the shape. The area is empty
+// when the shape falls outside the image.
+func (r *Raster) rasterizer(points []Point, pad float64) (*vector.Rasterizer, image.Rectangle) {
+	width, height := r.image.Bounds().Dx(), r.image.Bounds().Dy()
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range points {
+		minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+		maxX, maxY = math.Max(maxX, p.X), math.Max(maxY, p.Y)
+	}
+	if math.IsNaN(minX+minY+maxX+maxY) || minX > maxX || minY > maxY {
+		return nil, image.Rectangle{}
+	}
+
+	// Clamp before converting so that far off coordinates cannot overflow
+	clamp := func(v float64, max int) int {
+		return int(math.Max(0, math.Min(v, float64(max))))
+	}
+	area := image.Rect(
+		clamp(math.Floor(minX-pad), width), clamp(math.Floor(minY-pad), height),
+		clamp(math.Ceil(maxX+pad), width), clamp(math.Ceil(maxY+pad), height),
+	)
+	if area.Empty() {
+		return nil, area
+	}
+
+	if r.shapes == nil {
+		r.shapes = vector.NewRasterizer(area.Dx(), area.Dy())
+	} else {
+		r.shapes.Reset(area.Dx(), area.Dy())
+	}
+	return r.shapes, area
+}
+
+func (r *Raster) fill(rasterizer *vector.Rasterizer, area image.Rectangle, c color.NRGBA) {
+	rasterizer.DrawOp = draw.Over
+	rasterizer.Draw(r.image, area, image.NewUniform(c), image.Point{})
+}
+
+// face returns the font face of the given size, falling back to a fixed
+// bitmap font if the vector font is unavailable.
+func (r *Raster) face(size float64) font.Face {
+	if face, ok := r.faces[size]; ok {
+		return face
+	}
+
+	var face font.Face = basicfont.Face7x13
+	if regular != nil {
+		if f, err := opentype.NewFace(regular, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull}); err == nil {
+			face = f
+		}
+	}
+	r.faces[size] = face
+	return face
+}
+
+// path adds a closed path to a rasterizer whose area starts at origin.
+func path(rasterizer *vector.Rasterizer, origin image.Point, points []Point) {
+	x, y := float64(origin.X), float64(origin.Y)
+	rasterizer.MoveTo(float32(points[0].X-x), float32(points[0].Y-y))
+	for _, p := range points[1:] {
+		rasterizer.LineTo(float32(p.X-x), float32(p.Y-y))
+	}
+	rasterizer.ClosePath()
+}
@@ -0,0 +1,85 @@
+package charts
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+)
+
+// SVG is a canvas drawing into a scalable vector graphics document.
+type SVG struct {
+	width, height float64
+	body          bytes.Buffer
+}
+
+// NewSVG returns an empty SVG canvas of the given size in pixels.
+func NewSVG(width, height float64) *SVG {
+	return &SVG{width: width, height: height}
+}
+
+// Size implements Canvas.
+func (s *SVG) Size() (float64, float64) {
+	return s.width, s.height
+}
+
+// Rect implements Canvas.
+func (s *SVG) Rect(x, y, width, height float64, fill color.NRGBA) {
+	fmt.Fprintf(&s.body, `<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" %s/>`+"\n", x, y, width, height, paint("fill", fill))
+}
+
+// Polyline implements Canvas.
+func (s *SVG) Polyline(points []Point, width float64, stroke color.NRGBA) {
+	if len(points) < 2 {
+		return
+	}
+	fmt.Fprintf(&s.body, `<polyline points="%s" fill="none" stroke-width="%.2f" stroke-linejoin="round" %s/>`+"\n", svgPoints(points), width, paint("stroke", stroke))
+}
+
+// Polygon implements Canvas.
+func (s *SVG) Polygon(points []Point, fill color.NRGBA) {
+	if len(points) < 3 {
+		return
+	}
+	fmt.Fprintf(&s.body, `<polygon points="%s" %s/>`+"\n", svgPoints(points), paint("fill", fill))
+}
+
+// Text implements Canvas.
+func (s *SVG) Text(x, y float64, text string, size float64, anchor Anchor, fill color.NRGBA) {
+	if text == "" {
+		return
+	}
+	fmt.Fprintf(&s.body, `<text x="%.2f" y="%.2f" font-size="%.2f" text-anchor="%s" %s>`, x, y, size, [...]string{"start", "middle", "end"}[anchor], paint("fill", fill))
+	xml.EscapeText(&s.body, []byte(text))
+	s.body.WriteString("</text>\n")
+}
+
+// WriteTo writes the SVG document to w.
+func (s *SVG) WriteTo(w io.Writer) (int64, error) {
+	var document bytes.Buffer
+	fmt.Fprintf(&document, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="Helvetica, Arial, sans-serif">`+"\n", s.width, s.height, s.width, s.height)
+	document.Write(s.body.Bytes())
+	document.WriteString("</svg>\n")
+	return document.WriteTo(w)
+}
+
+func svgPoints(points []Point) string {
+	var buf bytes.Buffer
+	for i, p := range points {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		fmt.Fprintf(&buf, "%.2f,%.2f", p.X, p.Y)
+	}
+	return buf.String()
+}
+
+// paint sets the fill or stroke attribute, with its opacity when translucent.
+func paint(attribute string, c color.NRGBA) string {
+	value := fmt.Sprintf(`%s="#%02x%02x%02x"`, attribute, c.R, c.G, c.B)
+	if c.A != 0xff {
+		value += fmt.Sprintf(` %s-opacity="%.3f"`, attribute, float64(c.A)/0xff)
+	}
+	return value
+}
@@ -0,0 +1,72 @@
+// Package dashboard lays out graphs on the grid of a dashboard.
+package dashboard
+
+import (
+	"fmt"
+
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultSize is the size of a graph placed without one on either the
+// dashboard or the graph.
+var DefaultSize = models.Size{Width: 4, Height: 3}
+
+// Layout fills in the position and size of every graph on the dashboard from
+// the saved graphs and checks that they fit within the columns of the grid
+// without overlapping. graphs holds the saved graphs keyed by ID.
+func Layout(d *models.Dashboard, graphs map[primitive.ObjectID]*models.Graph) error {
+	if d.Columns == 0 {
+		d.Columns = models.DefaultDashboardColumns
+	}
+	if d.Columns < 0 {
+		return fmt.Errorf("Columns must be positive")
+	}
+
+	for i, placed := range d.Graphs {
+		graph, ok := graphs[placed.Graph]
+		if !ok {
+			return fmt.Errorf("Graph %s not found", placed.Graph.Hex())
+		}
+
+		if placed.Position == nil && graph.Position != nil {
+			position := *graph.Position
+			placed.Position = &position
+		}
+		if placed.Position == nil {
+			return fmt.Errorf("Graph %q has no position", graph.Name)
+		}
+
+		if placed.Size == nil {
+			size := DefaultSize
+			if graph.Size != nil {
+				size = *graph.Size
+			}
+			placed.Size = &size
+		}
+
+		if placed.Position.X < 0 || placed.Position.Y < 0 {
+			return fmt.Errorf("Graph %q must be placed at a non-negative position", graph.Name)
+		}
+		if placed.Size.Width < 1 || placed.Size.Height < 1 {
+			return fmt.Errorf("Graph %q must span at least one cell", graph.Name)
+		}
+		if placed.Position.X+placed.Size.Width > d.Columns {
+			return fmt.Errorf("Graph %q is wider than the %d columns of the dashboard", graph.Name, d.Columns)
+		}
+
+		for _, other := range d.Graphs[:i] {
+			if overlaps(placed, other) {
+				return fmt.Errorf("Graph %q overlaps %q", graph.Name, graphs[other.Graph].Name)
+			}
+		}
+	}
+	return nil
+}
+
+func overlaps(a, b *models.DashboardGraph) bool {
+	return a.Position.X < b.Position.X+b.Size.Width &&
+		b.Position.X < a.Position.X+a.Size.Width &&
+		a.Position.Y < b.Position.Y+b.Size.Height &&
+		b.Position.Y < a.Position.Y+a.Size.Height
+}
@@ -17,6 +17,12 @@ func Connect(connection string, dbName string) error {
 	return database.Connect(connection, dbName)
 }
 
+// Use sets the database the package functions run against, letting tests
+// run without MongoDB.
+func Use(db models.Database) {
+	database = db
+}
+
 func Disconnect() {
 	database.Disconnect()
 }
@@ -48,3 +54,27 @@ func Update(ctx context.Context, entity models.DatabaseEntity) (*mongo.UpdateRes
 func Delete(ctx context.Context, entity models.DatabaseEntity) (*mongo.DeleteResult, error) {
 	return database.Delete(ctx, entity)
 }
+
+func Aggregate(ctx context.Context, entity models.DatabaseEntity, pipeline mongo.Pipeline, results interface{}) error {
+	return database.Aggregate(ctx, entity, pipeline, results)
+}
+
+func Stream(ctx context.Context, entity models.DatabaseEntity, pipeline mongo.Pipeline, fn func(document bson.Raw) error) error {
+	return database.Stream(ctx, entity, pipeline, fn)
+}
+
+func Collections(ctx context.Context) ([]string, error) {
+	return database.Collections(ctx)
+}
+
+func Dump(ctx context.Context, collection string, fn func(document bson.Raw) error) error {
+	return database.Dump(ctx, collection, fn)
+}
+
+func Load(ctx context.Context, collection string, document bson.Raw, overwrite bool) (bool, error) {
+	return database.Load(ctx, collection, document, overwrite)
+}
+
+func CountByIDs(ctx context.Context, collection string, ids []interface{}) (int64, error) {
+	return database.CountByIDs(ctx, collection, ids)
+}
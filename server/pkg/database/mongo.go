@@ -5,6 +5,8 @@ import (
 	"crypto/tls"
 	"errors"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/Tchoukball-Tracker/pkg/logger"
@@ -70,9 +72,12 @@ func (mdb *MongoDB) Insert(ctx context.Context, entity models.DatabaseEntity) (m
 	collection := mdb.db.Collection(entity.CollectionName())
 
 	res, err := collection.InsertOne(ctx, entity)
+	if err != nil {
+		return entity, err
+	}
 	entity.SetID(res.InsertedID.(primitive.ObjectID))
 
-	return entity, err
+	return entity, nil
 }
 
 func (mdb *MongoDB) FindAll(ctx context.Context, entity models.DatabaseEntity) ([]models.DatabaseEntity, error) {
@@ -161,3 +166,97 @@ func (mdb *MongoDB) Delete(ctx context.Context, entity models.DatabaseEntity) (*
 	result, err := collection.DeleteOne(ctx, bson.M{"_id": entity.GetID()})
 	return result, err
 }
+
+func (mdb *MongoDB) Aggregate(ctx context.Context, entity models.DatabaseEntity, pipeline mongo.Pipeline, results interface{}) error {
+	collection := mdb.db.Collection(entity.CollectionName())
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	return cursor.All(ctx, results)
+}
+
+// Stream runs an aggregation and calls fn with every resulting document as it
+// is read, letting the stages spill to disk instead of holding every result
+// in memory. The document is only valid until fn returns.
+func (mdb *MongoDB) Stream(ctx context.Context, entity models.DatabaseEntity, pipeline mongo.Pipeline, fn func(document bson.Raw) error) error {
+	collection := mdb.db.Collection(entity.CollectionName())
+
+	cursor, err := collection.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		if err := fn(cursor.Current); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// Collections returns the sorted names of every collection in the database,
+// leaving out the system collections.
+func (mdb *MongoDB) Collections(ctx context.Context) ([]string, error) {
+	names, err := mdb.db.ListCollectionNames(ctx, bson.M{"type": "collection"})
+	if err != nil {
+		return nil, err
+	}
+
+	collections := make([]string, 0, len(names))
+	for _, name := range names {
+		if !strings.HasPrefix(name, "system.") {
+			collections = append(collections, name)
+		}
+	}
+	sort.Strings(collections)
+	return collections, nil
+}
+
+// Dump calls fn with every document of a collection in _id order. The
+// document is only valid until fn returns.
+func (mdb *MongoDB) Dump(ctx context.Context, collectionName string, fn func(document bson.Raw) error) error {
+	collection := mdb.db.Collection(collectionName)
+
+	cursor, err := collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		if err := fn(cursor.Current); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// Load stores a raw document as it is. A document whose _id is already used
+// replaces the existing one when overwrite is set and is otherwise left out,
+// in which case Load returns false.
+func (mdb *MongoDB) Load(ctx context.Context, collectionName string, document bson.Raw, overwrite bool) (bool, error) {
+	collection := mdb.db.Collection(collectionName)
+
+	if overwrite {
+		_, err := collection.ReplaceOne(ctx, bson.M{"_id": document.Lookup("_id")}, document, options.Replace().SetUpsert(true))
+		return err == nil, err
+	}
+
+	_, err := collection.InsertOne(ctx, document)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// CountByIDs returns how many of the given _id values are used in a collection.
+func (mdb *MongoDB) CountByIDs(ctx context.Context, collectionName string, ids []interface{}) (int64, error) {
+	collection := mdb.db.Collection(collectionName)
+
+	return collection.CountDocuments(ctx, bson.M{"_id": bson.M{"$in": ids}})
+}
@@ -0,0 +1,339 @@
+// Package formula parses and evaluates arithmetic expressions over named
+// counters, such as (point - caught) / (point + caught + short + frame).
+//
+// Expressions support numbers, identifiers, parentheses, unary minus and the
+// binary operators +, -, * and /. Nothing else can be expressed, so a saved
+// formula can always be evaluated safely.
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MaxLength is the longest expression that will be parsed.
+const MaxLength = 500
+
+var ErrDivisionByZero = errors.New("division by zero")
+
+// Error describes a problem with an expression and where it was found.
+type Error struct {
+	Position int // Byte offset in the expression, starting at 1
+	Message  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Message, e.Position)
+}
+
+// UnknownIdentifierError is returned when an expression uses a name that is
+// not a known variable.
+type UnknownIdentifierError struct {
+	Name     string
+	Position int
+}
+
+func (e *UnknownIdentifierError) Error() string {
+	return fmt.Sprintf("unknown identifier %q at position %d", e.Name, e.Position)
+}
+
+// Formula is a parsed expression ready to be evaluated.
+type Formula struct {
+	expression string
+	root       node
+}
+
+// Compile parses the expression and checks that every identifier it uses is
+// one of variables.
+func Compile(expression string, variables []string) (*Formula, error) {
+	if strings.TrimSpace(expression) == "" {
+		return nil, &Error{Position: 1, Message: "empty expression"}
+	}
+	if len(expression) > MaxLength {
+		return nil, &Error{Position: MaxLength + 1, Message: fmt.Sprintf("expression is longer than %d characters", MaxLength)}
+	}
+
+	p := &parser{input: expression}
+	p.next()
+	root, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if p.token.kind != tokenEOF {
+		return nil, p.unexpected()
+	}
+
+	known := make(map[string]bool, len(variables))
+	for _, variable := range variables {
+		known[variable] = true
+	}
+	if err := root.check(known); err != nil {
+		return nil, err
+	}
+
+	return &Formula{expression: expression, root: root}, nil
+}
+
+// String returns the expression the formula was compiled from.
+func (f *Formula) String() string {
+	return f.expression
+}
+
+// Evaluate computes the formula with the given variable values. It returns
+// ErrDivisionByZero if a divisor evaluates to zero and an
+// UnknownIdentifierError if a variable has no value.
+func (f *Formula) Evaluate(values map[string]float64) (float64, error) {
+	return f.root.evaluate(values)
+}
+
+type node interface {
+	evaluate(values map[string]float64) (float64, error)
+	check(known map[string]bool) error
+}
+
+type number struct {
+	value float64
+}
+
+func (n *number) evaluate(map[string]float64) (float64, error) {
+	return n.value, nil
+}
+
+func (n *number) check(map[string]bool) error {
+	return nil
+}
+
+type identifier struct {
+	name     string
+	position int
+}
+
+func (i *identifier) evaluate(values map[string]float64) (float64, error) {
+	value, ok := values[i.name]
+	if !ok {
+		return 0, &UnknownIdentifierError{Name: i.name, Position: i.position}
+	}
+	return value, nil
+}
+
+func (i *identifier) check(known map[string]bool) error {
+	if !known[i.name] {
+		return &UnknownIdentifierError{Name: i.name, Position: i.position}
+	}
+	return nil
+}
+
+type negation struct {
+	operand node
+}
+
+func (n *negation) evaluate(values map[string]float64) (float64, error) {
+	value, err := n.operand.evaluate(values)
+	return -value, err
+}
+
+func (n *negation) check(known map[string]bool) error {
+	return n.operand.check(known)
+}
+
+type binary struct {
+	operator    byte
+	left, right node
+}
+
+func (b *binary) evaluate(values map[string]float64) (float64, error) {
+	left, err := b.left.evaluate(values)
+	if err != nil {
+		return 0, err
+	}
+	right, err := b.right.evaluate(values)
+	if err != nil {
+		return 0, err
+	}
+
+	switch b.operator {
+	case '+':
+		return left + right, nil
+	case '-':
+		return left - right, nil
+	case '*':
+		return left * right, nil
+	}
+	if right == 0 {
+		return 0, ErrDivisionByZero
+	}
+	return left / right, nil
+}
+
+func (b *binary) check(known map[string]bool) error {
+	if err := b.left.check(known); err != nil {
+		return err
+	}
+	return b.right.check(known)
+}
+
+const (
+	tokenEOF = iota
+	tokenNumber
+	tokenIdentifier
+	tokenOperator
+	tokenOpen
+	tokenClose
+	tokenInvalid
+)
+
+type token struct {
+	kind     int
+	text     string
+	position int
+}
+
+// parser is a recursive descent parser for the grammar
+//
+//	expression = term { ("+" | "-") term }
+//	term       = unary { ("*" | "/") unary }
+//	unary      = "-" unary | primary
+//	primary    = number | identifier | "(" expression ")"
+type parser struct {
+	input  string
+	offset int
+	token  token
+}
+
+func (p *parser) next() {
+	for p.offset < len(p.input) && unicode.IsSpace(rune(p.input[p.offset])) {
+		p.offset++
+	}
+
+	start := p.offset
+	if p.offset >= len(p.input) {
+		p.token = token{kind: tokenEOF, position: start + 1}
+		return
+	}
+
+	c := p.input[p.offset]
+	switch {
+	case isDigit(c) || c == '.':
+		for p.offset < len(p.input) && (isDigit(p.input[p.offset]) || p.input[p.offset] == '.') {
+			p.offset++
+		}
+		p.token = token{kind: tokenNumber, text: p.input[start:p.offset], position: start + 1}
+	case isLetter(c):
+		for p.offset < len(p.input) && (isLetter(p.input[p.offset]) || isDigit(p.input[p.offset])) {
+			p.offset++
+		}
+		p.token = token{kind: tokenIdentifier, text: p.input[start:p.offset], position: start + 1}
+	case strings.IndexByte("+-*/", c) >= 0:
+		p.offset++
+		p.token = token{kind: tokenOperator, text: string(c), position: start + 1}
+	case c == '(':
+		p.offset++
+		p.token = token{kind: tokenOpen, text: "(", position: start + 1}
+	case c == ')':
+		p.offset++
+		p.token = token{kind: tokenClose, text: ")", position: start + 1}
+	default:
+		p.offset++
+		p.token = token{kind: tokenInvalid, text: string(c), position: start + 1}
+	}
+}
+
+func (p *parser) parseExpression() (node, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.token.kind == tokenOperator && (p.token.text == "+" || p.token.text == "-") {
+		operator := p.token.text[0]
+		p.next()
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{operator: operator, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseTerm() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.token.kind == tokenOperator && (p.token.text == "*" || p.token.text == "/") {
+		operator := p.token.text[0]
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{operator: operator, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.token.kind == tokenOperator && p.token.text == "-" {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &negation{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	current := p.token
+	switch current.kind {
+	case tokenNumber:
+		value, err := strconv.ParseFloat(current.text, 64)
+		if err != nil {
+			return nil, &Error{Position: current.position, Message: fmt.Sprintf("invalid number %q", current.text)}
+		}
+		p.next()
+		return &number{value: value}, nil
+	case tokenIdentifier:
+		p.next()
+		return &identifier{name: current.text, position: current.position}, nil
+	case tokenOpen:
+		p.next()
+		inner, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if p.token.kind != tokenClose {
+			if p.token.kind == tokenEOF {
+				return nil, &Error{Position: current.position, Message: "unclosed parenthesis"}
+			}
+			return nil, p.unexpected()
+		}
+		p.next()
+		return inner, nil
+	}
+	return nil, p.unexpected()
+}
+
+func (p *parser) unexpected() error {
+	switch p.token.kind {
+	case tokenEOF:
+		return &Error{Position: p.token.position, Message: "unexpected end of expression"}
+	case tokenInvalid:
+		return &Error{Position: p.token.position, Message: fmt.Sprintf("invalid character %q", p.token.text)}
+	}
+	return &Error{Position: p.token.position, Message: fmt.Sprintf("unexpected %q", p.token.text)}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
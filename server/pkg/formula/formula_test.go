@@ -0,0 +1,112 @@
+package formula
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var variables = []string{"point", "caught", "short", "frame"}
+
+func TestEvaluate(t *testing.T) {
+	values := map[string]float64{"point": 6, "caught": 2, "short": 1, "frame": 1}
+
+	tests := []struct {
+		expression string
+		want       float64
+	}{
+		{expression: "1 + 2 * 3", want: 7},
+		{expression: "(1 + 2) * 3", want: 9},
+		{expression: "8 - 4 - 2", want: 2},
+		{expression: "8 / 4 / 2", want: 1},
+		{expression: "2 * 3 / 4", want: 1.5},
+		{expression: "10 - 2 * 3 + 4 / 2", want: 6},
+		{expression: "-2 * 3", want: -6},
+		{expression: "--2", want: 2},
+		{expression: "4 - -2", want: 6},
+		{expression: "-(1 + 2) * -1", want: 3},
+		{expression: ".5 + 1.25", want: 1.75},
+		{expression: "point", want: 6},
+		{expression: "(point - caught) / (point + caught + short + frame)", want: 0.4},
+		{expression: "point * 100 / (point + caught)", want: 75},
+	}
+
+	for _, test := range tests {
+		f, err := Compile(test.expression, variables)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", test.expression, err)
+			continue
+		}
+		got, err := f.Evaluate(values)
+		if err != nil {
+			t.Errorf("Evaluate(%q): %v", test.expression, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Evaluate(%q) = %v, want %v", test.expression, got, test.want)
+		}
+	}
+}
+
+func TestEvaluateDivisionByZero(t *testing.T) {
+	tests := []struct {
+		expression string
+		values     map[string]float64
+	}{
+		{expression: "1 / 0", values: nil},
+		{expression: "point / caught", values: map[string]float64{"point": 3, "caught": 0}},
+		{expression: "point / (caught - short)", values: map[string]float64{"point": 3, "caught": 2, "short": 2}},
+		{expression: "1 + 2 * (point / short)", values: map[string]float64{"point": 0, "short": 0}},
+	}
+
+	for _, test := range tests {
+		f, err := Compile(test.expression, variables)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", test.expression, err)
+			continue
+		}
+		if _, err := f.Evaluate(test.values); !errors.Is(err, ErrDivisionByZero) {
+			t.Errorf("Evaluate(%q) error = %v, want %v", test.expression, err, ErrDivisionByZero)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		position   int
+		message    string
+	}{
+		{expression: "", position: 1, message: "empty expression"},
+		{expression: "1 +", position: 4, message: "unexpected end of expression"},
+		{expression: "(1 + 2", position: 1, message: "unclosed parenthesis"},
+		{expression: "1 + 2)", position: 6, message: `unexpected ")"`},
+		{expression: "1 % 2", position: 3, message: `invalid character "%"`},
+		{expression: "1..2", position: 1, message: `invalid number "1..2"`},
+		{expression: "* 2", position: 1, message: `unexpected "*"`},
+		{expression: strings.Repeat("1", MaxLength+1), position: MaxLength + 1, message: "expression is longer than 500 characters"},
+	}
+
+	for _, test := range tests {
+		_, err := Compile(test.expression, variables)
+		var formulaErr *Error
+		if !errors.As(err, &formulaErr) {
+			t.Errorf("Compile(%q) error = %v, want an *Error", test.expression, err)
+			continue
+		}
+		if formulaErr.Position != test.position || formulaErr.Message != test.message {
+			t.Errorf("Compile(%q) error = %q at %d, want %q at %d", test.expression, formulaErr.Message, formulaErr.Position, test.message, test.position)
+		}
+	}
+}
+
+func TestCompileUnknownIdentifier(t *testing.T) {
+	_, err := Compile("point + blocked", variables)
+	var unknown *UnknownIdentifierError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("got %v, want an *UnknownIdentifierError", err)
+	}
+	if unknown.Name != "blocked" || unknown.Position != 9 {
+		t.Errorf("got %q at %d, want \"blocked\" at 9", unknown.Name, unknown.Position)
+	}
+}
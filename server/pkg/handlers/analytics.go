@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Tchoukball-Tracker/pkg/analytics"
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/logger"
+	middleware "github.com/Tchoukball-Tracker/pkg/middlewares"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/parquet"
+	"github.com/Tchoukball-Tracker/pkg/stats"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var analyticsContentTypes = map[string]string{
+	models.AnalyticsNDJSON:  "application/x-ndjson",
+	models.AnalyticsParquet: "application/vnd.apache.parquet",
+}
+
+// RegisterAnalyticsRoutes registers analytics export routes in the provided router group.
+func RegisterAnalyticsRoutes(router *gin.RouterGroup) {
+	router.GET("/actions", middleware.JWTAuthMiddleware(models.PermissionRead), exportActions)
+	router.GET("/counters", middleware.JWTAuthMiddleware(models.PermissionRead), exportCounters)
+}
+
+// exportActions streams the action log of the filtered matches.
+// @Summary Export the action log
+// @Description stream every event of the action log of the matches selected by the filters, one row per event with the details of its match, as NDJSON or Parquet
+// @Tags analytics
+// @Produce  application/x-ndjson
+// @Produce  application/vnd.apache.parquet
+// @Param format query string false "ndjson (default) or parquet"
+// @Param from query string false "Only matches on or after this date (YYYY-MM-DD)"
+// @Param to query string false "Only matches on or before this date (YYYY-MM-DD)"
+// @Param season query string false "Only matches from this season"
+// @Param competition query string false "Only matches from this competition"
+// @Param team query string false "Only matches this team was tracked in"
+// @Param opponent query string false "Only matches where this team opposed the tracked players"
+// @Success 200 {file} file "Action log"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid filter or format"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /analytics/actions [get]
+func exportActions(c *gin.Context) {
+	streamAnalytics(c, "actions", analytics.ActionColumns, actionRows, func(document bson.Raw) ([]interface{}, error) {
+		var row models.ActionRow
+		if err := bson.Unmarshal(document, &row); err != nil {
+			return nil, err
+		}
+		return analytics.ActionValues(&row), nil
+	})
+}
+
+// exportCounters streams the counters of every player in every third of the filtered matches.
+// @Summary Export the player counters
+// @Description stream the attacking and defending counters of every player in every third of the matches selected by the filters, one row per player per third with the details of its match, as NDJSON or Parquet
+// @Tags analytics
+// @Produce  application/x-ndjson
+// @Produce  application/vnd.apache.parquet
+// @Param format query string false "ndjson (default) or parquet"
+// @Param from query string false "Only matches on or after this date (YYYY-MM-DD)"
+// @Param to query string false "Only matches on or before this date (YYYY-MM-DD)"
+// @Param season query string false "Only matches from this season"
+// @Param competition query string false "Only matches from this competition"
+// @Param team query string false "Only matches this team was tracked in"
+// @Param opponent query string false "Only matches where this team opposed the tracked players"
+// @Success 200 {file} file "Player counters"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid filter or format"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /analytics/counters [get]
+func exportCounters(c *gin.Context) {
+	streamAnalytics(c, "counters", analytics.CounterColumns, counterRows, func(document bson.Raw) ([]interface{}, error) {
+		var row models.CounterRow
+		if err := bson.Unmarshal(document, &row); err != nil {
+			return nil, err
+		}
+		return analytics.CounterValues(&row), nil
+	})
+}
+
+// rowsSource returns the collection and pipeline producing the rows of an
+// export for the filtered matches.
+type rowsSource func(ctx context.Context, filter models.StatsFilter) (models.DatabaseEntity, mongo.Pipeline, error)
+
+// actionRows runs over the action log of the filtered matches.
+func actionRows(ctx context.Context, filter models.StatsFilter) (models.DatabaseEntity, mongo.Pipeline, error) {
+	var matches []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := database.Aggregate(ctx, &models.Match{}, stats.MatchIDsPipeline(filter), &matches); err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match.ID)
+	}
+	return &models.Event{}, stats.ActionRowsPipeline(ids), nil
+}
+
+// counterRows runs over the filtered matches.
+func counterRows(ctx context.Context, filter models.StatsFilter) (models.DatabaseEntity, mongo.Pipeline, error) {
+	return &models.Match{}, stats.CounterRowsPipeline(filter), nil
+}
+
+// streamAnalytics runs the pipeline of the source and writes each resulting
+// row to the response as it is read from the database. Errors found before
+// anything is written are answered with JSON, later ones can only end the
+// response early.
+func streamAnalytics(c *gin.Context, name string, columns []parquet.Column, source rowsSource, values func(bson.Raw) ([]interface{}, error)) {
+	var filter models.StatsFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	format := c.DefaultQuery("format", models.AnalyticsNDJSON)
+	out := &analyticsWriter{c: c, contentType: analyticsContentTypes[format], filename: name + "." + format}
+	encoder, err := analytics.NewEncoder(out, format, columns)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	entity, pipeline, err := source(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	err = database.Stream(c.Request.Context(), entity, pipeline, func(document bson.Raw) error {
+		row, err := values(document)
+		if err != nil {
+			return err
+		}
+		return encoder.Write(row)
+	})
+	if err == nil {
+		err = encoder.Close()
+	}
+
+	if err != nil {
+		if !c.Writer.Written() {
+			c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+			return
+		}
+		logger.Log.Errorf("Failed to export %s: %v", name, err)
+		c.Abort()
+		return
+	}
+
+	// An export without rows has written nothing yet
+	out.writeHeader()
+	c.Writer.WriteHeaderNow()
+}
+
+// analyticsWriter writes an export to the response, setting its headers on
+// the first write so that earlier errors can still be answered with JSON.
+type analyticsWriter struct {
+	c           *gin.Context
+	contentType string
+	filename    string
+}
+
+func (w *analyticsWriter) writeHeader() {
+	if w.c.Writer.Written() {
+		return
+	}
+	w.c.Header("Content-Type", w.contentType)
+	w.c.Header("Content-Disposition", `attachment; filename="`+w.filename+`"`)
+	w.c.Status(http.StatusOK)
+}
+
+func (w *analyticsWriter) Write(p []byte) (int, error) {
+	w.writeHeader()
+	return w.c.Writer.Write(p)
+}
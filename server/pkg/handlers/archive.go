@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/logger"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// exportMatchArchive downloads a match with its spreadsheets and events.
+// @Summary Export a match archive
+// @Description download a versioned JSON archive of a match, the spreadsheet of each third, the action log and the players they name, to import on another server
+// @Tags matches
+// @Produce  json
+// @Param id path string true "Match ID"
+// @Success 200 {object} models.MatchArchive "Match archive"
+// @Failure 404 {object} models.HTTPError "Match not found"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /matches/{id}/archive [get]
+func exportMatchArchive(c *gin.Context) {
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.Match{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Match not found"})
+		return
+	}
+
+	match := result.(*models.Match)
+	spreadsheets, err := findMatchSpreadsheets(c.Request.Context(), match)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	events, err := findMatchEvents(c.Request.Context(), match)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	archive := &models.MatchArchive{
+		Version:      models.ArchiveVersion,
+		ExportedAt:   time.Now().UTC(),
+		Match:        match,
+		Spreadsheets: spreadsheets,
+		Events:       events,
+		Players:      archivePlayers(match, spreadsheets, events),
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+attachmentName(match.Name)+`.json"`)
+	c.JSON(http.StatusOK, archive)
+}
+
+// importMatchArchive recreates a match from an archive.
+// @Summary Import a match archive
+// @Description create a match, its spreadsheets and its action log from an archive exported by this or another server. Every identifier is replaced. The match keeps its archived name unless another is given, and is refused if a match already uses that name.
+// @Tags matches
+// @Accept  json
+// @Produce  json
+// @Param name query string false "Name of the imported match, defaults to the archived name"
+// @Param archive body models.MatchArchive true "Match archive"
+// @Success 201 {object} models.Match "Match imported"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid JSON"
+// @Failure 409 {object} models.HTTPError "Match name already used"
+// @Failure 422 {object} models.HTTPError "Unsupported version or inconsistent archive"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /matches/import [post]
+func importMatchArchive(c *gin.Context) {
+	var archive models.MatchArchive
+	if err := c.ShouldBindJSON(&archive); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	if message := validateArchive(&archive); message != "" {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: message})
+		return
+	}
+
+	if name := c.Query("name"); name != "" {
+		archive.Match.Name = name
+	}
+
+	result, _ := database.FindByName(c.Request.Context(), &models.Match{}, archive.Match.Name)
+	if result != nil {
+		c.JSON(http.StatusConflict, models.HTTPError{Code: http.StatusConflict, Message: fmt.Sprintf("A match named %q already exists, import it under another name", archive.Match.Name)})
+		return
+	}
+
+	match, err := insertArchive(c.Request.Context(), &archive)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, match)
+}
+
+// validateArchive checks the version of an archive and that its match,
+// spreadsheets, events and players refer to each other, returning a message
+// describing the first problem found.
+func validateArchive(archive *models.MatchArchive) string {
+	if archive.Version != models.ArchiveVersion {
+		return fmt.Sprintf("Unsupported archive version %d, this server imports version %d", archive.Version, models.ArchiveVersion)
+	}
+
+	match := archive.Match
+	if match == nil || match.Name == "" {
+		return "Archive has no named match"
+	}
+
+	if len(match.Thirds) != len(archive.Spreadsheets) {
+		return "Archive must hold one spreadsheet for each third of the match"
+	}
+
+	spreadsheets := make(map[primitive.ObjectID]bool, len(archive.Spreadsheets))
+	for period, spreadsheet := range archive.Spreadsheets {
+		if !isPeriod(period) {
+			return fmt.Sprintf("Unknown period %q", period)
+		}
+		if spreadsheet == nil || match.Thirds[period] != spreadsheet.ID {
+			return fmt.Sprintf("Spreadsheet of the %s third does not belong to the match", period)
+		}
+		for i, player := range spreadsheet.Players {
+			if player == nil {
+				return fmt.Sprintf("Player %d of the %s third is empty", i+1, period)
+			}
+		}
+		spreadsheets[spreadsheet.ID] = true
+	}
+
+	for i, event := range archive.Events {
+		if event == nil {
+			return fmt.Sprintf("Event %d is empty", i+1)
+		}
+		if event.Period != "" && !isPeriod(event.Period) {
+			return fmt.Sprintf("Event %d has unknown period %q", i+1, event.Period)
+		}
+		if !event.Spreadsheet.IsZero() && !spreadsheets[event.Spreadsheet] {
+			return fmt.Sprintf("Event %d refers to a spreadsheet outside the archive", i+1)
+		}
+	}
+
+	listed := make(map[string]bool, len(archive.Players))
+	for _, player := range archive.Players {
+		listed[player] = true
+	}
+	for _, player := range archivePlayers(match, archive.Spreadsheets, archive.Events) {
+		if !listed[player] {
+			return fmt.Sprintf("Player %q is not listed in the archive", player)
+		}
+	}
+	return ""
+}
+
+// insertArchive stores the spreadsheets and events of an archive under new
+// identifiers and then stores the match itself, so that the match is only
+// listed once everything it refers to exists. If anything cannot be stored,
+// what was stored before it is deleted again.
+func insertArchive(ctx context.Context, archive *models.MatchArchive) (*models.Match, error) {
+	match := archive.Match
+	match.ID = primitive.NilObjectID
+	match.Players = nil
+
+	var inserted []models.DatabaseEntity
+	insert := func(entity models.DatabaseEntity) (models.DatabaseEntity, error) {
+		dbEntity, err := database.Insert(ctx, entity)
+		if err != nil {
+			discardArchive(ctx, inserted)
+			return nil, err
+		}
+		inserted = append(inserted, dbEntity)
+		return dbEntity, nil
+	}
+
+	spreadsheetIDs := make(map[primitive.ObjectID]primitive.ObjectID, len(archive.Spreadsheets))
+	for period, spreadsheet := range archive.Spreadsheets {
+		oldID := spreadsheet.ID
+		spreadsheet.ID = primitive.NilObjectID
+		spreadsheet.Name = match.Name + " - " + models.PeriodTitle(period)
+		if spreadsheet.Players == nil {
+			spreadsheet.Players = make([]*models.Player, 0)
+		}
+
+		dbSpreadsheet, err := insert(spreadsheet)
+		if err != nil {
+			return nil, err
+		}
+		spreadsheetIDs[oldID] = dbSpreadsheet.GetID()
+		match.Thirds[period] = dbSpreadsheet.GetID()
+	}
+
+	// The match ID is chosen up front so that the events can refer to it.
+	match.ID = primitive.NewObjectID()
+	for _, event := range archive.Events {
+		event.ID = primitive.NilObjectID
+		event.Match = match.ID
+		if !event.Spreadsheet.IsZero() {
+			event.Spreadsheet = spreadsheetIDs[event.Spreadsheet]
+		}
+		if _, err := insert(event); err != nil {
+			return nil, err
+		}
+	}
+
+	dbMatch, err := insert(match)
+	if err != nil {
+		return nil, err
+	}
+
+	dispatchWebhooks(models.WebhookMatchCreated, match, nil)
+	return dbMatch.(*models.Match), nil
+}
+
+// discardArchive deletes what was stored of an archive that could not be
+// imported in full.
+func discardArchive(ctx context.Context, inserted []models.DatabaseEntity) {
+	for _, entity := range inserted {
+		if _, err := database.Delete(ctx, entity); err != nil {
+			logger.Log.Errorf("Failed to discard %s %s of an archive: %v", entity.CollectionName(), entity.GetID().Hex(), err)
+		}
+	}
+}
+
+// archivePlayers returns the sorted names of every player in the spreadsheets,
+// lineups and events of a match.
+func archivePlayers(match *models.Match, spreadsheets map[string]*models.Spreadsheet, events []*models.Event) []string {
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if name != "" {
+			seen[name] = true
+		}
+	}
+
+	for _, spreadsheet := range spreadsheets {
+		for _, player := range spreadsheet.Players {
+			add(player.Name)
+		}
+	}
+	for _, lineup := range match.Lineups {
+		for _, player := range lineup {
+			add(player)
+		}
+	}
+	for _, event := range events {
+		add(event.Player)
+		add(event.PlayerIn)
+		add(event.PlayerOut)
+	}
+
+	players := make([]string, 0, len(seen))
+	for name := range seen {
+		players = append(players, name)
+	}
+	sort.Strings(players)
+	return players
+}
+
+// isPeriod reports whether period is one of the thirds of a match.
+func isPeriod(period string) bool {
+	for _, known := range models.Periods {
+		if known == period {
+			return true
+		}
+	}
+	return false
+}
@@ -3,22 +3,21 @@ package handlers
 
 import (
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/Tchoukball-Tracker/pkg/database"
+	middleware "github.com/Tchoukball-Tracker/pkg/middlewares"
 	"github.com/Tchoukball-Tracker/pkg/models"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var jwtKey = []byte(os.Getenv("JWT_SECRET_KEY"))
-
 func RegisterAuthRoutes(router *gin.RouterGroup) {
 	router.POST("/login", login)
 	router.POST("/logout", logout)
 	router.POST("/jwt", validateToken)
+	router.POST("/setup", setupAdmin)
 }
 
 type LoginRequest struct {
@@ -50,10 +49,16 @@ func login(c *gin.Context) {
 		return
 	}
 
+	if user.Disabled {
+		c.JSON(http.StatusForbidden, models.HTTPError{Code: http.StatusForbidden, Message: "This account has been disabled"})
+		return
+	}
+
 	// Set token expiration time
 	expirationTime := time.Now().Add(168 * time.Hour) // 7-day token validity
 	claims := &models.Claims{
 		Username: user.Name,
+		Role:     user.EffectiveRole(),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 		},
@@ -66,6 +71,11 @@ func login(c *gin.Context) {
 	}
 
 	// Generate JWT token
+	jwtKey, err := middleware.JWTKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: "Could not generate token"})
+		return
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString(jwtKey)
 	if err != nil {
@@ -89,7 +99,7 @@ func validateToken(c *gin.Context) {
 
 	claims := &models.Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
+		return middleware.JWTKey()
 	})
 
 	if err != nil || !token.Valid {
@@ -97,7 +107,13 @@ func validateToken(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"valid": true})
+	result, err := database.FindByName(c.Request.Context(), &models.User{}, claims.Username)
+	if err != nil || result.(*models.User).Disabled {
+		c.JSON(http.StatusUnauthorized, models.HTTPError{Code: http.StatusUnauthorized, Message: "Account disabled or removed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"valid": true, "role": result.(*models.User).EffectiveRole()})
 }
 
 // Logout handles the logout process
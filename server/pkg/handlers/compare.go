@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/metrics"
+	middleware "github.com/Tchoukball-Tracker/pkg/middlewares"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/stats"
+	"github.com/Tchoukball-Tracker/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterCompareRoutes registers comparison routes in the provided router group.
+func RegisterCompareRoutes(router *gin.RouterGroup) {
+	router.GET("", middleware.JWTAuthMiddleware(models.PermissionRead), getComparison)
+}
+
+// getComparison compares players or matches side by side.
+// @Summary Compare players or matches
+// @Description compare the counters and metrics of two or more players or matches, with differences from the first
+// @Tags compare
+// @Accept  json
+// @Produce  json
+// @Param players query string false "Comma separated player names"
+// @Param matches query string false "Comma separated match IDs"
+// @Param mode query string false "Compare players by total or per-match average" default(total)
+// @Param from query string false "Only matches on or after this date (YYYY-MM-DD), players only"
+// @Param to query string false "Only matches on or before this date (YYYY-MM-DD), players only"
+// @Param season query string false "Only matches from this season, players only"
+// @Param competition query string false "Only matches from this competition, players only"
+// @Param team query string false "Only matches this team was tracked in, players only"
+// @Param opponent query string false "Only matches where this team opposed the tracked players, players only"
+// @Success 200 {object} models.Comparison "Comparison"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid parameters"
+// @Failure 404 {object} models.HTTPError "Player or match not found"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /compare [get]
+func getComparison(c *gin.Context) {
+	players := splitList(c.Query("players"))
+	matches := splitList(c.Query("matches"))
+
+	if (len(players) > 0) == (len(matches) > 0) {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: "Please provide either players or matches to compare"})
+		return
+	}
+
+	if len(players) == 1 || len(matches) == 1 {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: "Please provide at least two subjects to compare"})
+		return
+	}
+
+	custom, err := loadCustomMetrics(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	if len(players) > 0 {
+		comparePlayers(c, players, custom)
+	} else {
+		compareMatches(c, matches, custom)
+	}
+}
+
+func comparePlayers(c *gin.Context, players []string, custom []*stats.CustomMetric) {
+	var filter models.StatsFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	mode := c.DefaultQuery("mode", models.LeaderboardTotal)
+	if mode != models.LeaderboardTotal && mode != models.LeaderboardAverage {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: "Unknown mode, use total or average"})
+		return
+	}
+
+	var totals []*models.PlayerTotals
+	if err := database.Aggregate(c.Request.Context(), &models.Match{}, stats.PlayerTotalsPipeline(filter, players...), &totals); err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	byName := make(map[string]*models.PlayerTotals, len(totals))
+	for _, t := range totals {
+		byName[t.Player] = t
+	}
+
+	subjects := make([]models.ComparisonSubject, 0, len(players))
+	values := make([]map[string]float64, 0, len(players))
+	for _, name := range players {
+		t, ok := byName[name]
+		if !ok {
+			c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "No matches found for player " + name})
+			return
+		}
+		subjects = append(subjects, models.ComparisonSubject{ID: name, Label: name, Matches: t.Matches})
+		values = append(values, stats.TotalsValues(t, mode))
+	}
+
+	comparison := stats.Compare("players", subjects, values, custom)
+	comparison.Mode = mode
+	c.JSON(http.StatusOK, comparison)
+}
+
+func compareMatches(c *gin.Context, matches []string, custom []*stats.CustomMetric) {
+	subjects := make([]models.ComparisonSubject, 0, len(matches))
+	values := make([]map[string]float64, 0, len(matches))
+	for _, hexID := range matches {
+		result, err := database.Find(c.Request.Context(), &models.Match{ID: utils.ConvertToMongoID(hexID)})
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Match not found: " + hexID})
+			return
+		}
+
+		match := result.(*models.Match)
+		matchStats, err := computeMatchStats(c.Request.Context(), match)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+			return
+		}
+
+		subjects = append(subjects, models.ComparisonSubject{ID: hexID, Label: match.Name, Matches: 1})
+		values = append(values, metrics.Values(matchStats.Totals))
+	}
+
+	c.JSON(http.StatusOK, stats.Compare("matches", subjects, values, custom))
+}
+
+// splitList splits a comma separated query value, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
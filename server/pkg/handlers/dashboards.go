@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Tchoukball-Tracker/pkg/dashboard"
+	"github.com/Tchoukball-Tracker/pkg/database"
+	middleware "github.com/Tchoukball-Tracker/pkg/middlewares"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RegisterDashboardsRoutes registers dashboard-related routes in the provided router group.
+func RegisterDashboardsRoutes(router *gin.RouterGroup) {
+	router.GET("", middleware.JWTAuthMiddleware(models.PermissionRead), getAllDashboards)
+	router.POST("", middleware.JWTAuthMiddleware(models.PermissionManage), createDashboard)
+	router.GET("/:id", middleware.JWTAuthMiddleware(models.PermissionRead), getDashboardByID)
+	router.PUT("/:id", middleware.JWTAuthMiddleware(models.PermissionManage), updateDashboard)
+	router.DELETE("/:id", middleware.JWTAuthMiddleware(models.PermissionManage), deleteDashboard)
+	router.POST("/:id/clone", middleware.JWTAuthMiddleware(models.PermissionManage), cloneDashboard)
+}
+
+// getAllDashboards retrieves the dashboards visible to the user.
+// @Summary Retrieve all dashboards
+// @Description get the dashboards owned by the user and those shared by other users
+// @Tags dashboards
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} models.Dashboard "List of dashboards"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /dashboards [get]
+func getAllDashboards(c *gin.Context) {
+	user := middleware.GetClaims(c).Username
+	dbDashboards, err := database.FindByValue(c.Request.Context(), &models.Dashboard{}, bson.M{"$or": bson.A{bson.M{"owner": user}, bson.M{"shared": true}}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dbDashboards)
+}
+
+// createDashboard creates a new dashboard owned by the user.
+// @Summary Create a new dashboard
+// @Description create a dashboard laying out saved graphs on a grid. Graphs without a position or size take the one saved on the graph, and must fit the columns of the grid without overlapping.
+// @Tags dashboards
+// @Accept json
+// @Produce json
+// @Param dashboard body models.Dashboard true "Dashboard Info"
+// @Success 201 {object} models.Dashboard "Successfully created"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid JSON"
+// @Failure 422 {object} models.HTTPError "Bad request - missing name, unknown or overlapping graphs"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /dashboards [post]
+func createDashboard(c *gin.Context) {
+	var newDashboard *models.Dashboard
+	if err := c.ShouldBindJSON(&newDashboard); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	if newDashboard.Name == "" {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "Please provide a name for the dashboard"})
+		return
+	}
+
+	if !layoutDashboard(c, newDashboard) {
+		return
+	}
+
+	newDashboard.ID = primitive.NilObjectID
+	newDashboard.Owner = middleware.GetClaims(c).Username
+	newDashboard.CreatedAt = time.Now().UTC()
+	newDashboard.UpdatedAt = newDashboard.CreatedAt
+	dbDashboard, err := database.Insert(c.Request.Context(), newDashboard)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbDashboard)
+}
+
+// getDashboardByID retrieves a dashboard by ID.
+// @Summary Retrieve a dashboard by ID
+// @Description get a dashboard owned by the user or shared by another user
+// @Tags dashboards
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Dashboard ID"
+// @Success 200 {object} models.Dashboard "Dashboard retrieved"
+// @Failure 404 {object} models.HTTPError "Dashboard not found"
+// @Router /dashboards/{id} [get]
+func getDashboardByID(c *gin.Context) {
+	if dashboard, ok := findViewableDashboard(c); ok {
+		c.JSON(http.StatusOK, dashboard)
+	}
+}
+
+// updateDashboard replaces the name, sharing and layout of a dashboard.
+// @Summary Update a dashboard
+// @Description replace the name, sharing, columns and graphs of a dashboard owned by the user
+// @Tags dashboards
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Dashboard ID"
+// @Param dashboard body models.Dashboard true "Dashboard info"
+// @Success 200 {object} models.Dashboard "Dashboard updated"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid JSON"
+// @Failure 403 {object} models.HTTPError "Dashboard owned by another user"
+// @Failure 404 {object} models.HTTPError "Dashboard not found"
+// @Failure 422 {object} models.HTTPError "Bad request - unknown or overlapping graphs"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /dashboards/{id} [put]
+func updateDashboard(c *gin.Context) {
+	var updatedDashboard *models.Dashboard
+	if err := c.ShouldBindJSON(&updatedDashboard); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	fetchedDashboard, ok := findViewableDashboard(c)
+	if !ok {
+		return
+	}
+
+	if fetchedDashboard.Owner != middleware.GetClaims(c).Username {
+		c.JSON(http.StatusForbidden, models.HTTPError{Code: http.StatusForbidden, Message: "Only the owner can change a dashboard"})
+		return
+	}
+
+	if updatedDashboard.Name == "" {
+		updatedDashboard.Name = fetchedDashboard.Name
+	}
+	if updatedDashboard.Graphs == nil {
+		updatedDashboard.Graphs = fetchedDashboard.Graphs
+	}
+
+	if !layoutDashboard(c, updatedDashboard) {
+		return
+	}
+
+	updatedDashboard.ID = fetchedDashboard.ID
+	updatedDashboard.Owner = fetchedDashboard.Owner
+	updatedDashboard.CreatedAt = fetchedDashboard.CreatedAt
+	updatedDashboard.UpdatedAt = time.Now().UTC()
+	if _, err := database.Update(c.Request.Context(), updatedDashboard); err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedDashboard)
+}
+
+// deleteDashboard deletes a dashboard by ID.
+// @Summary Delete a dashboard
+// @Description delete a dashboard owned by the user, or any dashboard for admins. The graphs on it are kept.
+// @Tags dashboards
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Dashboard ID"
+// @Success 200 {string} string "Successfully deleted"
+// @Failure 403 {object} models.HTTPError "Dashboard owned by another user"
+// @Failure 404 {object} models.HTTPError "Dashboard not found"
+// @Router /dashboards/{id} [delete]
+func deleteDashboard(c *gin.Context) {
+	claims := middleware.GetClaims(c)
+	admin := models.RoleCan(claims.Role, models.PermissionAdmin)
+
+	// Admins can delete dashboards they cannot otherwise see
+	result, err := database.Find(c.Request.Context(), &models.Dashboard{ID: utils.ConvertToMongoID(c.Param("id"))})
+	if err != nil || (!admin && !result.(*models.Dashboard).CanView(claims.Username)) {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Dashboard not found"})
+		return
+	}
+
+	fetchedDashboard := result.(*models.Dashboard)
+	if fetchedDashboard.Owner != claims.Username && !admin {
+		c.JSON(http.StatusForbidden, models.HTTPError{Code: http.StatusForbidden, Message: "Only the owner or an admin can delete a dashboard"})
+		return
+	}
+
+	if _, err := database.Delete(c.Request.Context(), fetchedDashboard); err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HTTPError{Code: http.StatusOK, Message: "Successfully Deleted"})
+}
+
+// cloneDashboard copies a dashboard for the user.
+// @Summary Clone a dashboard
+// @Description copy a dashboard owned by the user or shared by another user. The copy belongs to the user and is not shared.
+// @Tags dashboards
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Dashboard ID"
+// @Param name query string false "Name of the copy, defaults to the original name followed by (copy)"
+// @Success 201 {object} models.Dashboard "Successfully cloned"
+// @Failure 404 {object} models.HTTPError "Dashboard not found"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /dashboards/{id}/clone [post]
+func cloneDashboard(c *gin.Context) {
+	clone, ok := findViewableDashboard(c)
+	if !ok {
+		return
+	}
+
+	clone.ID = primitive.NilObjectID
+	clone.Name = c.DefaultQuery("name", clone.Name+" (copy)")
+	clone.Owner = middleware.GetClaims(c).Username
+	clone.Shared = false
+	clone.CreatedAt = time.Now().UTC()
+	clone.UpdatedAt = clone.CreatedAt
+	dbDashboard, err := database.Insert(c.Request.Context(), clone)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbDashboard)
+}
+
+// findViewableDashboard fetches the dashboard of the ID parameter, responding
+// with 404 if it does not exist or is private to another user.
+func findViewableDashboard(c *gin.Context) (*models.Dashboard, bool) {
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.Dashboard{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil || !result.(*models.Dashboard).CanView(middleware.GetClaims(c).Username) {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Dashboard not found"})
+		return nil, false
+	}
+	return result.(*models.Dashboard), true
+}
+
+// layoutDashboard places the graphs of the dashboard on its grid, responding
+// with the reason if they are missing or do not fit.
+func layoutDashboard(c *gin.Context, d *models.Dashboard) bool {
+	if d.Graphs == nil {
+		d.Graphs = []*models.DashboardGraph{}
+	}
+
+	graphs, err := findDashboardGraphs(c.Request.Context(), d)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return false
+	}
+
+	if err := dashboard.Layout(d, graphs); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: err.Error()})
+		return false
+	}
+	return true
+}
+
+// findDashboardGraphs fetches the saved graphs placed on a dashboard, keyed by ID.
+func findDashboardGraphs(ctx context.Context, d *models.Dashboard) (map[primitive.ObjectID]*models.Graph, error) {
+	ids := make([]primitive.ObjectID, 0, len(d.Graphs))
+	for _, placed := range d.Graphs {
+		ids = append(ids, placed.Graph)
+	}
+
+	results, err := database.FindByValue(ctx, &models.DBGraph{}, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+
+	graphs := make(map[primitive.ObjectID]*models.Graph, len(results))
+	for _, result := range results {
+		graphs[result.GetID()] = result.(*models.DBGraph).ToDomain()
+	}
+	return graphs, nil
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/gin-gonic/gin"
+)
+
+func TestDashboardPermissions(t *testing.T) {
+	useMemoryDatabase(t)
+	insertTestUser(t, "admin", models.RoleAdmin)
+	insertTestUser(t, "coach", models.RoleCoach)
+	insertTestUser(t, "other", models.RoleCoach)
+	insertTestUser(t, "viewer", models.RoleViewer)
+
+	router := gin.New()
+	RegisterAuthRoutes(router.Group("/auth"))
+	RegisterDashboardsRoutes(router.Group("/dashboards"))
+
+	insertDashboard := func(owner string, shared bool) string {
+		dashboard, err := database.Insert(context.Background(), &models.Dashboard{Name: owner, Owner: owner, Shared: shared})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "/dashboards/" + dashboard.GetID().Hex()
+	}
+	shared := insertDashboard("coach", true)
+	private := insertDashboard("coach", false)
+
+	tests := []struct {
+		user   string
+		method string
+		path   string
+		want   int
+	}{
+		{user: "viewer", method: http.MethodGet, path: shared, want: http.StatusOK},
+		{user: "viewer", method: http.MethodPost, path: "/dashboards", want: http.StatusForbidden},
+		{user: "viewer", method: http.MethodPut, path: shared, want: http.StatusForbidden},
+		{user: "viewer", method: http.MethodPost, path: shared + "/clone", want: http.StatusForbidden},
+		{user: "viewer", method: http.MethodDelete, path: shared, want: http.StatusForbidden},
+		{user: "other", method: http.MethodDelete, path: shared, want: http.StatusForbidden},
+		{user: "other", method: http.MethodDelete, path: private, want: http.StatusNotFound},
+		{user: "admin", method: http.MethodDelete, path: private, want: http.StatusOK},
+		{user: "coach", method: http.MethodDelete, path: shared, want: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		if code := serveAs(t, router, test.user, test.method, test.path); code != test.want {
+			t.Errorf("%s %s as %s = %d, want %d", test.method, test.path, test.user, code, test.want)
+		}
+	}
+}
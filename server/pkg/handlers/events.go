@@ -0,0 +1,425 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/logger"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/stats"
+	"github.com/Tchoukball-Tracker/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// setLineup sets the players on court at the start of a period.
+// @Summary Set a period lineup
+// @Description set the players on court at the start of a period of the match
+// @Tags matches
+// @Accept json
+// @Produce json
+// @Param id path string true "Match ID"
+// @Param period path string true "Period (first, second or third)"
+// @Param lineup body models.Lineup true "Starting players"
+// @Success 200 {object} models.Match "Lineup set"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid JSON"
+// @Failure 404 {object} models.HTTPError "Match or period not found"
+// @Failure 422 {object} models.HTTPError "Bad request - unknown player"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /matches/{id}/lineups/{period} [put]
+func setLineup(c *gin.Context) {
+	var lineup models.Lineup
+	if err := c.ShouldBindJSON(&lineup); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	match, spreadsheet, ok := findMatchPeriod(c, c.Param("period"))
+	if !ok {
+		return
+	}
+
+	for _, name := range lineup.Players {
+		if spreadsheet.FindPlayer(name) == nil {
+			c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "Failed to find player with name " + name})
+			return
+		}
+	}
+
+	if match.Lineups == nil {
+		match.Lineups = make(map[string][]string)
+	}
+	match.Lineups[c.Param("period")] = lineup.Players
+
+	if _, err := database.Update(c.Request.Context(), match); err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, match)
+}
+
+// createSubstitution records a substitution.
+// @Summary Record a substitution
+// @Description record a player coming on for another at the given game clock
+// @Tags matches
+// @Accept json
+// @Produce json
+// @Param id path string true "Match ID"
+// @Param substitution body models.Event true "Substitution with period, clock, player_in and player_out"
+// @Success 201 {object} models.Event "Successfully created"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid JSON"
+// @Failure 404 {object} models.HTTPError "Match or period not found"
+// @Failure 422 {object} models.HTTPError "Bad request - missing element"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /matches/{id}/substitutions [post]
+func createSubstitution(c *gin.Context) {
+	var event models.Event
+	if err := c.ShouldBindJSON(&event); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	match, spreadsheet, ok := findMatchPeriod(c, event.Period)
+	if !ok {
+		return
+	}
+
+	if event.PlayerIn == "" || event.PlayerOut == "" {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "Please provide the players coming on and off"})
+		return
+	}
+
+	for _, name := range []string{event.PlayerIn, event.PlayerOut} {
+		if spreadsheet.FindPlayer(name) == nil {
+			c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "Failed to find player with name " + name})
+			return
+		}
+	}
+
+	event.Type = models.EventSubstitution
+	event.Player, event.Action, event.Value = "", "", 0
+	createMatchEvent(c, match, spreadsheet, &event)
+}
+
+// createConceded records a point conceded to the opponent.
+// @Summary Record a point conceded
+// @Description record points scored by the opponent at the given game clock
+// @Tags matches
+// @Accept json
+// @Produce json
+// @Param id path string true "Match ID"
+// @Param conceded body models.Event true "Conceded point with period, clock and value"
+// @Success 201 {object} models.Event "Successfully created"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid JSON"
+// @Failure 404 {object} models.HTTPError "Match or period not found"
+// @Failure 422 {object} models.HTTPError "Bad request - missing element"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /matches/{id}/conceded [post]
+func createConceded(c *gin.Context) {
+	var event models.Event
+	if err := c.ShouldBindJSON(&event); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	match, spreadsheet, ok := findMatchPeriod(c, event.Period)
+	if !ok {
+		return
+	}
+
+	if event.Value == 0 {
+		event.Value = 1
+	}
+
+	event.Type = models.EventConceded
+	event.Player, event.Action, event.PlayerIn, event.PlayerOut = "", "", "", ""
+	if createMatchEvent(c, match, spreadsheet, &event) != nil {
+		dispatchWebhooks(models.WebhookPointRecorded, match, &event)
+		publishScore(c.Request.Context(), match)
+	}
+}
+
+// startPeriod records the start of a period.
+// @Summary Start a period
+// @Description record the start of a period of the match in the action log at game clock 0, marking a scheduled match as live
+// @Tags matches
+// @Accept json
+// @Produce json
+// @Param id path string true "Match ID"
+// @Param period path string true "Period (first, second or third)"
+// @Success 201 {object} models.Event "Successfully created"
+// @Failure 404 {object} models.HTTPError "Match or period not found"
+// @Failure 409 {object} models.HTTPError "Period already started"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /matches/{id}/periods/{period}/start [post]
+func startPeriod(c *gin.Context) {
+	match, spreadsheet, ok := findMatchPeriod(c, c.Param("period"))
+	if !ok {
+		return
+	}
+
+	started, _, err := periodState(c.Request.Context(), match, c.Param("period"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	if started {
+		c.JSON(http.StatusConflict, models.HTTPError{Code: http.StatusConflict, Message: "Period already started"})
+		return
+	}
+
+	if match.Status == models.MatchStatusScheduled || match.Status == "" {
+		match.Status = models.MatchStatusLive
+		if _, err := database.Update(c.Request.Context(), match); err != nil {
+			c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+			return
+		}
+	}
+
+	clock := 0
+	event := &models.Event{Period: c.Param("period"), Type: models.EventPeriodStart, Clock: &clock}
+	if createMatchEvent(c, match, spreadsheet, event) != nil {
+		dispatchWebhooks(models.WebhookPeriodStarted, match, event)
+	}
+}
+
+// endPeriod records the end of a period.
+// @Summary End a period
+// @Description record the end of a started period of the match in the action log at the full length of a period
+// @Tags matches
+// @Accept json
+// @Produce json
+// @Param id path string true "Match ID"
+// @Param period path string true "Period (first, second or third)"
+// @Success 201 {object} models.Event "Successfully created"
+// @Failure 404 {object} models.HTTPError "Match or period not found"
+// @Failure 409 {object} models.HTTPError "Period not started or already ended"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /matches/{id}/periods/{period}/end [post]
+func endPeriod(c *gin.Context) {
+	match, spreadsheet, ok := findMatchPeriod(c, c.Param("period"))
+	if !ok {
+		return
+	}
+
+	started, ended, err := periodState(c.Request.Context(), match, c.Param("period"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	if !started || ended {
+		c.JSON(http.StatusConflict, models.HTTPError{Code: http.StatusConflict, Message: "Period not started or already ended"})
+		return
+	}
+
+	clock := match.GetPeriodLength()
+	event := &models.Event{Period: c.Param("period"), Type: models.EventPeriodEnd, Clock: &clock}
+	if createMatchEvent(c, match, spreadsheet, event) != nil {
+		dispatchWebhooks(models.WebhookPeriodEnded, match, event)
+	}
+}
+
+// getMatchEvents retrieves the action log of a match.
+// @Summary Retrieve the action log
+// @Description get the player actions, substitutions and conceded points of a match in order
+// @Tags matches
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Match ID"
+// @Success 200 {array} models.Event "Action log"
+// @Failure 404 {object} models.HTTPError "Match not found"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /matches/{id}/events [get]
+func getMatchEvents(c *gin.Context) {
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.Match{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Match not found"})
+		return
+	}
+
+	events, err := findMatchEvents(c.Request.Context(), result.(*models.Match))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// getPlayingTime computes the playing time and plus/minus of every player.
+// @Summary Retrieve playing time
+// @Description compute the time on court and plus/minus per player per period from the lineups and timed events. While the match is live, a period that has not ended counts up to its last timed event
+// @Tags matches
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Match ID"
+// @Success 200 {array} models.PlayingTime "Playing time per player"
+// @Failure 404 {object} models.HTTPError "Match not found"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /matches/{id}/playing-time [get]
+func getPlayingTime(c *gin.Context) {
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.Match{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Match not found"})
+		return
+	}
+
+	match := result.(*models.Match)
+	events, err := findMatchEvents(c.Request.Context(), match)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats.PlayingTime(match, events))
+}
+
+// findMatchPeriod fetches the match from the path and the spreadsheet of the
+// given period, writing an error response if either cannot be found.
+func findMatchPeriod(c *gin.Context, period string) (*models.Match, *models.Spreadsheet, bool) {
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.Match{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Match not found"})
+		return nil, nil, false
+	}
+
+	match := result.(*models.Match)
+	spreadsheetID, ok := match.Thirds[period]
+	if !ok {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Period not found"})
+		return nil, nil, false
+	}
+
+	result, err = database.Find(c.Request.Context(), &models.Spreadsheet{ID: spreadsheetID})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Spreadsheet not found"})
+		return nil, nil, false
+	}
+
+	return match, result.(*models.Spreadsheet), true
+}
+
+// createMatchEvent validates the game clock of the event and adds it to the
+// action log, returning the event or nil if an error response was written.
+func createMatchEvent(c *gin.Context, match *models.Match, spreadsheet *models.Spreadsheet, event *models.Event) *models.Event {
+	if event.Clock == nil {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "Please provide the game clock"})
+		return nil
+	}
+
+	if *event.Clock < 0 {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "The game clock cannot be negative"})
+		return nil
+	}
+
+	event.ID = primitive.NilObjectID
+	event.Match = match.ID
+	event.Spreadsheet = spreadsheet.ID
+	event.CreatedAt = time.Now().UTC()
+
+	dbEvent, err := database.Insert(c.Request.Context(), event)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return nil
+	}
+
+	c.JSON(http.StatusCreated, dbEvent)
+	return event
+}
+
+// findMatchEvents fetches the action log of a match ordered by period, game clock and time recorded.
+func findMatchEvents(ctx context.Context, match *models.Match) ([]*models.Event, error) {
+	results, err := database.FindByValue(ctx, &models.Event{}, bson.M{"match": match.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	order := make(map[string]int, len(models.Periods))
+	for i, period := range models.Periods {
+		order[period] = i
+	}
+
+	events := make([]*models.Event, 0, len(results))
+	for _, result := range results {
+		events = append(events, result.(*models.Event))
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if a.Period != b.Period {
+			return order[a.Period] < order[b.Period]
+		}
+		if a.Clock != nil && b.Clock != nil && *a.Clock != *b.Clock {
+			return *a.Clock < *b.Clock
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+	return events, nil
+}
+
+// findMatchBySpreadsheet finds the match a spreadsheet is used for and the period it covers.
+func findMatchBySpreadsheet(ctx context.Context, spreadsheetID primitive.ObjectID) (*models.Match, string) {
+	filter := bson.A{}
+	for _, period := range models.Periods {
+		filter = append(filter, bson.M{"thirds." + period: spreadsheetID})
+	}
+
+	results, err := database.FindByValue(ctx, &models.Match{}, bson.M{"$or": filter})
+	if err != nil || len(results) == 0 {
+		return nil, ""
+	}
+
+	match := results[0].(*models.Match)
+	return match, match.PeriodOf(spreadsheetID)
+}
+
+// periodState reports whether the start and end of a period are in the action log.
+func periodState(ctx context.Context, match *models.Match, period string) (started bool, ended bool, err error) {
+	results, err := database.FindByValue(ctx, &models.Event{}, bson.M{
+		"match":  match.ID,
+		"period": period,
+		"type":   bson.M{"$in": bson.A{models.EventPeriodStart, models.EventPeriodEnd}},
+	})
+	for _, result := range results {
+		switch result.(*models.Event).Type {
+		case models.EventPeriodStart:
+			started = true
+		case models.EventPeriodEnd:
+			ended = true
+		}
+	}
+	return started, ended, err
+}
+
+// logPlayerAction adds a player action recorded on a spreadsheet to the action
+// log, returning the match the spreadsheet is used for, if any, and the event.
+func logPlayerAction(ctx context.Context, spreadsheet *models.Spreadsheet, player string, action models.PlayerAction) (*models.Match, *models.Event) {
+	event := &models.Event{
+		Spreadsheet: spreadsheet.ID,
+		Type:        models.EventAction,
+		Clock:       action.Clock,
+		Player:      player,
+		Action:      action.Type,
+		Value:       action.Value,
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	match, period := findMatchBySpreadsheet(ctx, spreadsheet.ID)
+	if match != nil {
+		event.Match = match.ID
+		event.Period = period
+	}
+
+	if _, err := database.Insert(ctx, event); err != nil {
+		logger.Log.Errorf("Failed to log action for player %s: %v", player, err)
+	}
+	return match, event
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/metrics"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/stats"
+	"github.com/Tchoukball-Tracker/pkg/utils"
+	"github.com/Tchoukball-Tracker/pkg/xlsx"
+	"github.com/gin-gonic/gin"
+)
+
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+var unsafeFileName = regexp.MustCompile(`[^A-Za-z0-9 ._-]+`)
+
+// exportSpreadsheet downloads a spreadsheet as an XLSX workbook.
+// @Summary Export a spreadsheet to XLSX
+// @Description download the attacking and defending counters of every player in a spreadsheet, with totals and metrics, as an Excel workbook
+// @Tags spreadsheets
+// @Produce  application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param id path string true "Spreadsheet ID"
+// @Success 200 {file} file "XLSX workbook"
+// @Failure 404 {object} models.HTTPError "Spreadsheet not found"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /spreadsheets/{id}/export.xlsx [get]
+func exportSpreadsheet(c *gin.Context) {
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.Spreadsheet{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Spreadsheet not found"})
+		return
+	}
+
+	spreadsheet := result.(*models.Spreadsheet)
+	var rows []namedCounters
+	var total models.Counters
+	for _, player := range spreadsheet.Players {
+		rows = append(rows, namedCounters{name: player.Name, counters: player.Counters()})
+		total.Add(player.Counters())
+	}
+
+	workbook := &xlsx.Workbook{}
+	addCountersSheet(workbook, spreadsheet.Name, rows, total)
+	writeWorkbook(c, spreadsheet.Name, workbook)
+}
+
+// exportMatch downloads a match as an XLSX workbook.
+// @Summary Export a match to XLSX
+// @Description download a match as an Excel workbook with a sheet of player counters for every third and a sheet of match totals
+// @Tags matches
+// @Produce  application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param id path string true "Match ID"
+// @Success 200 {file} file "XLSX workbook"
+// @Failure 404 {object} models.HTTPError "Match not found"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /matches/{id}/export.xlsx [get]
+func exportMatch(c *gin.Context) {
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.Match{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Match not found"})
+		return
+	}
+
+	match := result.(*models.Match)
+	spreadsheets, err := findMatchSpreadsheets(c.Request.Context(), match)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	matchStats := stats.FromSpreadsheets(match, spreadsheets)
+
+	workbook := &xlsx.Workbook{}
+	for _, period := range models.Periods {
+		var rows []namedCounters
+		for _, player := range matchStats.Players {
+			if counters, ok := player.Periods[period]; ok {
+				rows = append(rows, namedCounters{name: player.Name, counters: *counters})
+			}
+		}
+
+		var total models.Counters
+		if counters, ok := matchStats.Periods[period]; ok {
+			total = *counters
+		}
+		addCountersSheet(workbook, models.PeriodTitle(period), rows, total)
+	}
+
+	var rows []namedCounters
+	for _, player := range matchStats.Players {
+		rows = append(rows, namedCounters{name: player.Name, counters: player.Totals})
+	}
+	addCountersSheet(workbook, "Totals", rows, matchStats.Totals)
+
+	writeWorkbook(c, match.Name, workbook)
+}
+
+// namedCounters are the counters of a row of an exported sheet.
+type namedCounters struct {
+	name     string
+	counters models.Counters
+}
+
+// addCountersSheet adds a sheet with a row of counters and metrics for every
+// player followed by the totals. The attacking, defending and metric columns
+// are grouped under a merged header.
+func addCountersSheet(workbook *xlsx.Workbook, name string, rows []namedCounters, total models.Counters) {
+	sheet := workbook.AddSheet(name)
+
+	groups := []struct {
+		title   string
+		columns []string
+	}{
+		{"Attacking", models.AttackingCounters},
+		{"Defending", models.DefendingCounters},
+		{"Metrics", metricNames()},
+	}
+
+	titles := []xlsx.Cell{xlsx.Header("Player")}
+	columns := []xlsx.Cell{xlsx.Header("")}
+	var names []string
+	for _, group := range groups {
+		sheet.Merge(0, len(titles), 0, len(titles)+len(group.columns)-1)
+		for i, column := range group.columns {
+			if i == 0 {
+				titles = append(titles, xlsx.Header(group.title))
+			} else {
+				titles = append(titles, xlsx.Header(""))
+			}
+			columns = append(columns, xlsx.Header(column))
+			names = append(names, column)
+		}
+	}
+	sheet.Merge(0, 0, 1, 0)
+	sheet.AddRow(titles...)
+	sheet.AddRow(columns...)
+
+	addRow := func(first xlsx.Cell, counters models.Counters) {
+		values := metrics.Values(counters)
+		cells := []xlsx.Cell{first}
+		for _, name := range names {
+			cells = append(cells, xlsx.Number(values[name]))
+		}
+		sheet.AddRow(cells...)
+	}
+	for _, row := range rows {
+		addRow(xlsx.String(row.name), row.counters)
+	}
+	addRow(xlsx.Header("Total"), total)
+}
+
+func metricNames() []string {
+	names := make([]string, 0, len(metrics.Definitions))
+	for _, definition := range metrics.Definitions {
+		names = append(names, definition.Name)
+	}
+	return names
+}
+
+// writeWorkbook responds with the workbook as a file download.
+func writeWorkbook(c *gin.Context, name string, workbook *xlsx.Workbook) {
+	var file bytes.Buffer
+	if err := workbook.Write(&file); err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+attachmentName(name)+`.xlsx"`)
+	c.Data(http.StatusOK, xlsxContentType, file.Bytes())
+}
+
+// attachmentName makes a name safe to use as a downloaded file name.
+func attachmentName(name string) string {
+	name = strings.TrimSpace(unsafeFileName.ReplaceAllString(name, "_"))
+	if name == "" {
+		return "export"
+	}
+	return name
+}
@@ -0,0 +1,461 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/png"
+	"net/http"
+	"strconv"
+
+	"github.com/Tchoukball-Tracker/pkg/charts"
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/metrics"
+	middleware "github.com/Tchoukball-Tracker/pkg/middlewares"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/stats"
+	"github.com/Tchoukball-Tracker/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var (
+	errUnknownSourceType  = errors.New("Unknown source type, use spreadsheet, match or query")
+	errDataSourceNotFound = errors.New("Data source not found")
+)
+
+// RegisterGraphsRoutes registers graph-related routes in the provided router group.
+func RegisterGraphsRoutes(router *gin.RouterGroup) {
+	router.GET("", middleware.JWTAuthMiddleware(models.PermissionRead), getAllGraphs)
+	router.POST("", middleware.JWTAuthMiddleware(models.PermissionManage), createGraph)
+	router.GET("/:id", middleware.JWTAuthMiddleware(models.PermissionRead), getGraphByID)
+	router.PUT("/:id", middleware.JWTAuthMiddleware(models.PermissionManage), updateGraph)
+	router.DELETE("/:id", middleware.JWTAuthMiddleware(models.PermissionAdmin), deleteGraph)
+	router.GET("/:id/data", middleware.JWTAuthMiddleware(models.PermissionRead), getGraphData)
+	router.GET("/:id/render", middleware.JWTAuthMiddleware(models.PermissionRead), renderGraph)
+}
+
+// getAllGraphs retrieves all graphs.
+// @Summary Retrieve all graphs
+// @Description get all graphs from the database
+// @Tags graphs
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} models.Graph "List of graphs"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /graphs [get]
+func getAllGraphs(c *gin.Context) {
+	dbGraphs, err := database.FindAll(c.Request.Context(), &models.DBGraph{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	graphs := make([]*models.Graph, 0, len(dbGraphs))
+	for _, dbGraph := range dbGraphs {
+		graphs = append(graphs, dbGraph.(*models.DBGraph).ToDomain())
+	}
+	c.JSON(http.StatusOK, graphs)
+}
+
+// createGraph creates a new graph.
+// @Summary Create a new graph
+// @Description create a graph plotting counters or metrics from a spreadsheet, match or saved query
+// @Tags graphs
+// @Accept json
+// @Produce json
+// @Param graph body models.Graph true "Graph Info"
+// @Success 201 {object} models.Graph "Successfully created"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid JSON"
+// @Failure 422 {object} models.HTTPError "Bad request - invalid graph or unknown data source"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /graphs [post]
+func createGraph(c *gin.Context) {
+	var newGraph *models.Graph
+	if err := c.ShouldBindJSON(&newGraph); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	newGraph.ID = ""
+	if message := validateGraph(c.Request.Context(), newGraph); message != "" {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: message})
+		return
+	}
+
+	dbGraph, err := database.Insert(c.Request.Context(), newGraph.ToDatabase())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbGraph.(*models.DBGraph).ToDomain())
+}
+
+// getGraphByID retrieves a graph by ID.
+// @Summary Retrieve a graph by ID
+// @Description get graph by ID from the database
+// @Tags graphs
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Graph ID"
+// @Success 200 {object} models.Graph "Graph retrieved"
+// @Failure 404 {object} models.HTTPError "Graph not found"
+// @Router /graphs/{id} [get]
+func getGraphByID(c *gin.Context) {
+	hexID := c.Param("id")
+	dbGraph, err := database.Find(c.Request.Context(), &models.DBGraph{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Graph not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dbGraph.(*models.DBGraph).ToDomain())
+}
+
+// updateGraph updates a graph by ID.
+// @Summary Update a graph
+// @Description update the name, type, data source, metrics, players or default layout of a graph by ID
+// @Tags graphs
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Graph ID"
+// @Param graph body models.Graph true "Graph info"
+// @Success 200 {object} models.Graph "Graph updated"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid JSON"
+// @Failure 404 {object} models.HTTPError "Graph not found"
+// @Failure 422 {object} models.HTTPError "Bad request - invalid graph or unknown data source"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /graphs/{id} [put]
+func updateGraph(c *gin.Context) {
+	var updatedGraph *models.Graph
+	if err := c.ShouldBindJSON(&updatedGraph); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.DBGraph{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Graph not found"})
+		return
+	}
+
+	fetchedGraph := result.(*models.DBGraph).ToDomain()
+	if updatedGraph.Name == "" {
+		updatedGraph.Name = fetchedGraph.Name
+	}
+	if updatedGraph.Type == "" {
+		updatedGraph.Type = fetchedGraph.Type
+	}
+	if updatedGraph.DataSource == "" {
+		updatedGraph.DataSource = fetchedGraph.DataSource
+		updatedGraph.SourceType = fetchedGraph.SourceType
+	}
+	if updatedGraph.Metrics == nil {
+		updatedGraph.Metrics = fetchedGraph.Metrics
+	}
+	if updatedGraph.Players == nil {
+		updatedGraph.Players = fetchedGraph.Players
+	}
+	if updatedGraph.Position == nil {
+		updatedGraph.Position = fetchedGraph.Position
+	}
+	if updatedGraph.Size == nil {
+		updatedGraph.Size = fetchedGraph.Size
+	}
+	updatedGraph.ID = fetchedGraph.ID
+
+	if message := validateGraph(c.Request.Context(), updatedGraph); message != "" {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: message})
+		return
+	}
+
+	if _, err := database.Update(c.Request.Context(), updatedGraph.ToDatabase()); err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedGraph)
+}
+
+// deleteGraph deletes a graph by ID.
+// @Summary Delete a graph
+// @Description delete a graph by ID
+// @Tags graphs
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Graph ID"
+// @Success 200 {string} string "Successfully deleted"
+// @Failure 404 {object} models.HTTPError "Graph not found"
+// @Failure 409 {object} models.HTTPError "Graph placed on a dashboard"
+// @Router /graphs/{id} [delete]
+func deleteGraph(c *gin.Context) {
+	hexID := c.Param("id")
+	graphID := utils.ConvertToMongoID(hexID)
+
+	dashboards, err := database.FindByValue(c.Request.Context(), &models.Dashboard{}, bson.M{"graphs.graph": graphID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	if len(dashboards) > 0 {
+		c.JSON(http.StatusConflict, models.HTTPError{Code: http.StatusConflict, Message: "Graph is placed on dashboard " + dashboards[0].(*models.Dashboard).Name})
+		return
+	}
+
+	result, err := database.Delete(c.Request.Context(), &models.DBGraph{ID: graphID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Graph not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HTTPError{Code: http.StatusOK, Message: "Successfully Deleted"})
+}
+
+// getGraphData resolves a graph into the series it plots.
+// @Summary Retrieve the data of a graph
+// @Description resolve the data source of a graph into labels and one series per plotted counter or metric. Spreadsheet and match sources are labelled by player, saved queries by match.
+// @Tags graphs
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Graph ID"
+// @Success 200 {object} models.SeriesData "Series data"
+// @Failure 404 {object} models.HTTPError "Graph or data source not found"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /graphs/{id}/data [get]
+func getGraphData(c *gin.Context) {
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.DBGraph{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Graph not found"})
+		return
+	}
+
+	data, err := resolveGraph(c.Request.Context(), result.(*models.DBGraph).ToDomain())
+	if err == errDataSourceNotFound {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, data)
+}
+
+// renderGraph draws a graph as an image.
+// @Summary Render a graph
+// @Description draw the data of a graph as a bar, line, radar or stacked bar chart in SVG or PNG. The height is three fifths of the width.
+// @Tags graphs
+// @Produce  image/svg+xml
+// @Produce  image/png
+// @Param id path string true "Graph ID"
+// @Param format query string false "Image format: svg or png" default(svg)
+// @Param width query int false "Width of the image in pixels, between 200 and 2000" default(800)
+// @Success 200 {file} file "Rendered graph"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid format or width"
+// @Failure 404 {object} models.HTTPError "Graph or data source not found"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /graphs/{id}/render [get]
+func renderGraph(c *gin.Context) {
+	format := c.DefaultQuery("format", "svg")
+	if format != "svg" && format != "png" {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: "Unknown format, use svg or png"})
+		return
+	}
+
+	width, err := strconv.Atoi(c.DefaultQuery("width", "800"))
+	if err != nil || width < 200 || width > 2000 {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: "Width must be between 200 and 2000 pixels"})
+		return
+	}
+	height := width * 3 / 5
+
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.DBGraph{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Graph not found"})
+		return
+	}
+
+	graph := result.(*models.DBGraph).ToDomain()
+	data, err := resolveGraph(c.Request.Context(), graph)
+	if err == errDataSourceNotFound {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	var image bytes.Buffer
+	if format == "svg" {
+		canvas := charts.NewSVG(float64(width), float64(height))
+		err = charts.Draw(canvas, graph.Type, graph.Name, data)
+		if err == nil {
+			_, err = canvas.WriteTo(&image)
+		}
+	} else {
+		canvas := charts.NewRaster(width, height)
+		err = charts.Draw(canvas, graph.Type, graph.Name, data)
+		if err == nil {
+			err = png.Encode(&image, canvas.Image())
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	contentType := "image/svg+xml"
+	if format == "png" {
+		contentType = "image/png"
+	}
+	c.Data(http.StatusOK, contentType, image.Bytes())
+}
+
+// validateGraph checks the type, metrics and data source of a graph,
+// returning a message describing the first problem found.
+func validateGraph(ctx context.Context, graph *models.Graph) string {
+	if graph.Name == "" {
+		return "Please provide a name for the graph"
+	}
+
+	result, _ := database.FindByName(ctx, &models.DBGraph{}, graph.Name)
+	if result != nil && result.GetID() != utils.ConvertToMongoID(graph.ID) {
+		return "Graph name already used"
+	}
+
+	if !isGraphType(graph.Type) {
+		return "Unknown graph type, use bar, line, radar or stackedBar"
+	}
+
+	if len(graph.Metrics) == 0 {
+		return "Please provide at least one counter or metric to plot"
+	}
+
+	custom, err := loadCustomMetrics(ctx)
+	if err != nil {
+		return err.Error()
+	}
+	for _, metric := range graph.Metrics {
+		if _, ok := stats.Resolve(metric, custom); !ok {
+			return errUnknownMetric.Error() + ": " + metric
+		}
+	}
+
+	if _, err := findDataSource(ctx, graph); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func isGraphType(graphType string) bool {
+	for _, t := range models.GraphTypes {
+		if t == graphType {
+			return true
+		}
+	}
+	return false
+}
+
+// findDataSource fetches the spreadsheet, match or saved query a graph plots.
+func findDataSource(ctx context.Context, graph *models.Graph) (models.DatabaseEntity, error) {
+	id := utils.ConvertToMongoID(graph.DataSource)
+
+	var entity models.DatabaseEntity
+	switch graph.SourceType {
+	case models.DataSourceSpreadsheet:
+		entity = &models.Spreadsheet{ID: id}
+	case models.DataSourceMatch:
+		entity = &models.Match{ID: id}
+	case models.DataSourceQuery:
+		entity = &models.Query{ID: id}
+	default:
+		return nil, errUnknownSourceType
+	}
+
+	if id.IsZero() {
+		return nil, errDataSourceNotFound
+	}
+
+	result, err := database.Find(ctx, entity)
+	if err != nil {
+		return nil, errDataSourceNotFound
+	}
+	return result, nil
+}
+
+// resolveGraph computes the series of a graph from its data source.
+func resolveGraph(ctx context.Context, graph *models.Graph) (*models.SeriesData, error) {
+	source, err := findDataSource(ctx, graph)
+	if err != nil {
+		return nil, err
+	}
+
+	custom, err := loadCustomMetrics(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := []string{}
+	values := []map[string]float64{}
+	switch source := source.(type) {
+	case *models.Spreadsheet:
+		for _, player := range source.Players {
+			if plotsPlayer(graph, player.Name) {
+				labels = append(labels, player.Name)
+				values = append(values, metrics.Values(player.Counters()))
+			}
+		}
+	case *models.Match:
+		matchStats, err := computeMatchStats(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		for _, player := range matchStats.Players {
+			if plotsPlayer(graph, player.Name) {
+				labels = append(labels, player.Name)
+				values = append(values, metrics.Values(player.Totals))
+			}
+		}
+	case *models.Query:
+		players := source.Players
+		if len(graph.Players) > 0 {
+			players = graph.Players
+		}
+
+		var rows []*models.MatchTotals
+		if err := database.Aggregate(ctx, &models.Match{}, stats.MatchTotalsPipeline(source.Filter, players...), &rows); err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			labels = append(labels, row.Name)
+			values = append(values, metrics.Values(row.Counters))
+		}
+	}
+
+	return stats.Series(labels, values, graph.Metrics, custom), nil
+}
+
+// plotsPlayer reports whether a graph includes the player, every player being
+// plotted when the graph does not list any.
+func plotsPlayer(graph *models.Graph, name string) bool {
+	if len(graph.Players) == 0 {
+		return true
+	}
+	for _, player := range graph.Players {
+		if player == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/gin-gonic/gin"
+)
+
+func TestUpdateGraphPlayers(t *testing.T) {
+	tests := []struct {
+		name    string
+		players []string
+		want    []string
+	}{
+		{name: "missing players are kept", players: nil, want: []string{"Alex", "Sam"}},
+		{name: "new players replace the old ones", players: []string{"Kim"}, want: []string{"Kim"}},
+		{name: "no players go back to every player", players: []string{}, want: nil},
+	}
+
+	for _, test := range tests {
+		useMemoryDatabase(t)
+		insertTestUser(t, "coach", models.RoleCoach)
+
+		ctx := context.Background()
+		spreadsheet, err := database.Insert(ctx, &models.Spreadsheet{Name: "first"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		graph := &models.DBGraph{Name: "points", Type: models.GraphBar, DataSource: spreadsheet.GetID(), SourceType: models.DataSourceSpreadsheet, Metrics: []string{"point"}, Players: []string{"Alex", "Sam"}}
+		if _, err := database.Insert(ctx, graph); err != nil {
+			t.Fatal(err)
+		}
+
+		router := gin.New()
+		RegisterAuthRoutes(router.Group("/auth"))
+		RegisterGraphsRoutes(router.Group("/graphs"))
+
+		update := map[string]interface{}{"name": "points"}
+		if test.players != nil {
+			update["players"] = test.players
+		}
+		if response := serveJSONAs(t, router, "coach", http.MethodPut, "/graphs/"+graph.ID.Hex(), update); response.Code != http.StatusOK {
+			t.Fatalf("%s: update = %d, want %d: %s", test.name, response.Code, http.StatusOK, response.Body)
+		}
+
+		stored, err := database.Find(ctx, &models.DBGraph{ID: graph.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if players := stored.(*models.DBGraph).Players; len(players) != len(test.want) || (len(players) > 0 && !reflect.DeepEqual(players, test.want)) {
+			t.Errorf("%s: players = %v, want %v", test.name, players, test.want)
+		}
+	}
+}
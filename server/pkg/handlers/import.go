@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/importer"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/xlsx"
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportSize is the largest file accepted for import, in bytes.
+const maxImportSize = 10 << 20
+
+// importSpreadsheet creates a spreadsheet from an uploaded XLSX or CSV file.
+// @Summary Import a spreadsheet
+// @Description create a spreadsheet from the rows of an XLSX or CSV file with a player column and a column per counter. Headers are matched to counters by name or alias, ignoring case, spaces and punctuation. Rows with a missing or repeated player, or counters that are not whole non-negative numbers, are reported by row and prevent the import. A dry run reports the result without saving it.
+// @Tags spreadsheets
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param file formData file true "XLSX or CSV file"
+// @Param name formData string false "Name of the spreadsheet, defaults to the file name"
+// @Param sheet formData string false "Sheet to import from an XLSX file, defaults to the first"
+// @Param aliases formData string false "JSON object of extra headers for the name column and counters, such as {\"point\": [\"Goals\"]}"
+// @Param dry_run formData bool false "Preview the import without saving it"
+// @Success 200 {object} models.ImportReport "Dry run report"
+// @Success 201 {object} models.ImportReport "Spreadsheet imported"
+// @Failure 400 {object} models.HTTPError "Bad request - missing, unreadable or unsupported file, or invalid aliases"
+// @Failure 413 {object} models.HTTPError "File too large, uploaded or decompressed"
+// @Failure 422 {object} models.ImportReport "Rows with errors"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /spreadsheets/import [post]
+func importSpreadsheet(c *gin.Context) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: "Please upload an XLSX or CSV file"})
+		return
+	}
+	if header.Size > maxImportSize {
+		c.JSON(http.StatusRequestEntityTooLarge, models.HTTPError{Code: http.StatusRequestEntityTooLarge, Message: "File is larger than 10 MB"})
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultPostForm("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: "dry_run must be true or false"})
+		return
+	}
+
+	var extra map[string][]string
+	if value := c.PostForm("aliases"); value != "" {
+		if err := json.Unmarshal([]byte(value), &extra); err != nil {
+			c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: "Aliases must be a JSON object of header lists: " + err.Error()})
+			return
+		}
+	}
+	aliases, err := importer.MergeAliases(extra)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	defer file.Close()
+
+	extension := strings.ToLower(filepath.Ext(header.Filename))
+	var rows [][]string
+	switch extension {
+	case ".xlsx":
+		rows, err = xlsx.ReadSheet(file, header.Size, c.PostForm("sheet"))
+	case ".csv":
+		rows, err = importer.ReadCSV(file)
+	default:
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: "Unsupported file type, upload an .xlsx or .csv file, saving an .xls workbook as one of them"})
+		return
+	}
+	if errors.Is(err, xlsx.ErrTooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, models.HTTPError{Code: http.StatusRequestEntityTooLarge, Message: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	players, report := importer.Parse(rows, aliases)
+	report.DryRun = dryRun
+	if players == nil {
+		players = make([]*models.Player, 0)
+	}
+
+	name := c.PostForm("name")
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(header.Filename), filepath.Ext(header.Filename))
+	}
+	report.Spreadsheet = &models.Spreadsheet{Name: name, Players: players}
+
+	if dryRun {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	if len(report.Errors) > 0 {
+		report.Spreadsheet = nil
+		c.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+
+	dbSpreadsheet, err := database.Insert(c.Request.Context(), report.Spreadsheet)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	report.Spreadsheet = dbSpreadsheet.(*models.Spreadsheet)
+	c.JSON(http.StatusCreated, report)
+}
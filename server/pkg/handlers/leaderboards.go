@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	middleware "github.com/Tchoukball-Tracker/pkg/middlewares"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/stats"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterLeaderboardsRoutes registers leaderboard-related routes in the provided router group.
+func RegisterLeaderboardsRoutes(router *gin.RouterGroup) {
+	router.GET("", middleware.JWTAuthMiddleware(models.PermissionRead), getLeaderboard)
+}
+
+// getLeaderboard ranks players by a counter or metric.
+// @Summary Retrieve a leaderboard
+// @Description rank players by any counter, built-in or user-defined metric within a scope. Players with equal values share a rank and are listed by name
+// @Tags leaderboards
+// @Accept  json
+// @Produce  json
+// @Param metric query string true "Counter or metric to rank by"
+// @Param scope query string false "Comma separated scopes such as season:2024, competition:name, opponent:team or match:id" default(all)
+// @Param mode query string false "Rank by total or per-match average" default(total)
+// @Param order query string false "Rank highest first (desc) or lowest first (asc)" default(desc)
+// @Param min_matches query int false "Minimum number of matches played" default(0)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Entries per page" default(20)
+// @Success 200 {object} models.Leaderboard "Leaderboard"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid parameters"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /leaderboards [get]
+func getLeaderboard(c *gin.Context) {
+	metric := c.Query("metric")
+	if metric == "" {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: "Please provide a metric to rank by"})
+		return
+	}
+
+	mode := c.DefaultQuery("mode", models.LeaderboardTotal)
+	if mode != models.LeaderboardTotal && mode != models.LeaderboardAverage {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: "Unknown mode, use total or average"})
+		return
+	}
+
+	order := c.DefaultQuery("order", "desc")
+	if order != "desc" && order != "asc" {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: "Unknown order, use desc or asc"})
+		return
+	}
+
+	numbers := map[string]int{"min_matches": 0, "page": 1, "limit": 20}
+	for name := range numbers {
+		value, ok := c.GetQuery(name)
+		if !ok {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: "Invalid value for " + name})
+			return
+		}
+		numbers[name] = parsed
+	}
+
+	scope := c.DefaultQuery("scope", "all")
+	filter, err := stats.ParseScope(scope)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	custom, err := loadCustomMetrics(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	value, ok := stats.Resolve(metric, custom)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: "Unknown metric: " + metric})
+		return
+	}
+
+	var totals []*models.PlayerTotals
+	if err := database.Aggregate(c.Request.Context(), &models.Match{}, stats.PlayerTotalsPipeline(filter), &totals); err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	entries := stats.Rank(totals, value, mode, numbers["min_matches"], order == "asc")
+	page, err := stats.Paginate(entries, numbers["page"], numbers["limit"])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Leaderboard{
+		Metric:     metric,
+		Mode:       mode,
+		Scope:      scope,
+		MinMatches: numbers["min_matches"],
+		Page:       numbers["page"],
+		Limit:      numbers["limit"],
+		Total:      len(entries),
+		Entries:    page,
+	})
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/league"
+	middleware "github.com/Tchoukball-Tracker/pkg/middlewares"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// RegisterLeagueRoutes registers league-related routes in the provided router group.
+func RegisterLeagueRoutes(router *gin.RouterGroup) {
+	router.GET("/:competition", middleware.JWTAuthMiddleware(models.PermissionRead), getLeagueTable)
+}
+
+// getLeagueTable computes the league table for a competition.
+// @Summary Retrieve a league table
+// @Description compute the standings of a competition from its finished matches
+// @Tags league
+// @Accept  json
+// @Produce  json,text/csv
+// @Param competition path string true "Competition name"
+// @Param season query string false "Only count matches from this season"
+// @Param win query int false "League points for a win" default(3)
+// @Param draw query int false "League points for a draw" default(2)
+// @Param loss query int false "League points for a loss" default(1)
+// @Param forfeit query int false "League points for the team that forfeited" default(0)
+// @Param forfeit_score query int false "Score awarded to the opponent of a forfeit, 0 keeps the recorded score" default(0)
+// @Param tiebreak query string false "Comma separated tiebreak order: points_difference, head_to_head, points_scored"
+// @Param format query string false "Response format: json or csv" default(json)
+// @Success 200 {object} models.LeagueTable "League table"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid rules"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /league/{competition} [get]
+func getLeagueTable(c *gin.Context) {
+	rules, err := leagueRulesFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	competition := c.Param("competition")
+	filter := bson.M{"competition": competition, "status": models.MatchStatusFinished}
+	season := c.Query("season")
+	if season != "" {
+		filter["season"] = season
+	}
+
+	results, err := database.FindByValue(c.Request.Context(), &models.Match{}, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	matches := make([]*models.Match, 0, len(results))
+	for _, result := range results {
+		matches = append(matches, result.(*models.Match))
+	}
+
+	table := models.LeagueTable{
+		Competition: competition,
+		Season:      season,
+		Rules:       rules,
+		Standings:   league.Table(matches, rules),
+	}
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		c.JSON(http.StatusOK, table)
+	case "csv":
+		writeLeagueTableCSV(c, table)
+	default:
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: "Unsupported format, use json or csv"})
+	}
+}
+
+// leagueRulesFromQuery overrides the default league rules with any query parameters provided.
+func leagueRulesFromQuery(c *gin.Context) (models.LeagueRules, error) {
+	rules := models.DefaultLeagueRules()
+
+	params := map[string]*int{
+		"win":           &rules.PointsWin,
+		"draw":          &rules.PointsDraw,
+		"loss":          &rules.PointsLoss,
+		"forfeit":       &rules.PointsForfeit,
+		"forfeit_score": &rules.ForfeitScore,
+	}
+	// Sorted so that the first invalid parameter reported is always the same.
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field := params[name]
+		value, ok := c.GetQuery(name)
+		if !ok {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return rules, fmt.Errorf("Invalid value for %s: %s", name, value)
+		}
+		*field = parsed
+	}
+
+	if value, ok := c.GetQuery("tiebreak"); ok {
+		rules.Tiebreakers = []string{}
+		for _, tiebreaker := range strings.Split(value, ",") {
+			tiebreaker = strings.TrimSpace(tiebreaker)
+			if tiebreaker == "" {
+				continue
+			}
+			if !models.IsTiebreaker(tiebreaker) {
+				return rules, fmt.Errorf("Unknown tiebreaker: %s", tiebreaker)
+			}
+			rules.Tiebreakers = append(rules.Tiebreakers, tiebreaker)
+		}
+	}
+
+	return rules, nil
+}
+
+func writeLeagueTableCSV(c *gin.Context, table models.LeagueTable) {
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", table.Competition+".csv"))
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"Position", "Team", "Played", "Won", "Drawn", "Lost", "Forfeited", "Points For", "Points Against", "Points Difference", "League Points"})
+	for _, s := range table.Standings {
+		writer.Write([]string{
+			strconv.Itoa(s.Position),
+			s.Team,
+			strconv.Itoa(s.Played),
+			strconv.Itoa(s.Won),
+			strconv.Itoa(s.Drawn),
+			strconv.Itoa(s.Lost),
+			strconv.Itoa(s.Forfeited),
+			strconv.Itoa(s.PointsFor),
+			strconv.Itoa(s.PointsAgainst),
+			strconv.Itoa(s.PointsDifference),
+			strconv.Itoa(s.LeaguePoints),
+		})
+	}
+	writer.Flush()
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"time"
 
@@ -12,13 +14,33 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+var errMatchNameUsed = errors.New("Match name already used")
+
 // RegisterRoutes registers match-related routes in the provided router group.
 func RegisterMatchesRoutes(router *gin.RouterGroup) {
-	router.GET("", middleware.JWTAuthMiddleware(), getAllMatches)
-	router.POST("", middleware.JWTAuthMiddleware(), createMatch)
-	router.GET("/:id", middleware.JWTAuthMiddleware(), getMatchByID)
-	router.PUT("/:id", middleware.JWTAuthMiddleware(), updateMatch)
-	router.DELETE("/:id", middleware.JWTAuthMiddleware(), deleteMatch)
+	router.GET("", middleware.JWTAuthMiddleware(models.PermissionRead), getAllMatches)
+	router.POST("", middleware.JWTAuthMiddleware(models.PermissionManage), createMatch)
+	router.POST("/import", middleware.JWTAuthMiddleware(models.PermissionManage), importMatchArchive)
+	router.GET("/:id", middleware.JWTAuthMiddleware(models.PermissionRead), getMatchByID)
+	router.PUT("/:id", middleware.JWTAuthMiddleware(models.PermissionManage), updateMatch)
+	router.DELETE("/:id", middleware.JWTAuthMiddleware(models.PermissionAdmin), deleteMatch)
+	router.PUT("/:id/result", middleware.JWTAuthMiddleware(models.PermissionManage), setMatchResult)
+	router.PUT("/:id/lineups/:period", middleware.JWTAuthMiddleware(models.PermissionManage), setLineup)
+	router.POST("/:id/substitutions", middleware.JWTAuthMiddleware(models.PermissionTrack), createSubstitution)
+	router.POST("/:id/conceded", middleware.JWTAuthMiddleware(models.PermissionTrack), createConceded)
+	router.POST("/:id/periods/:period/start", middleware.JWTAuthMiddleware(models.PermissionTrack), startPeriod)
+	router.POST("/:id/periods/:period/end", middleware.JWTAuthMiddleware(models.PermissionTrack), endPeriod)
+	router.GET("/:id/events", middleware.JWTAuthMiddleware(models.PermissionRead), getMatchEvents)
+	router.GET("/:id/playing-time", middleware.JWTAuthMiddleware(models.PermissionRead), getPlayingTime)
+	router.GET("/:id/stats", middleware.JWTAuthMiddleware(models.PermissionRead), getMatchStats)
+	router.GET("/:id/export.xlsx", middleware.JWTAuthMiddleware(models.PermissionRead), exportMatch)
+	router.GET("/:id/report.pdf", middleware.JWTAuthMiddleware(models.PermissionRead), getMatchReport)
+	router.GET("/:id/archive", middleware.JWTAuthMiddleware(models.PermissionRead), exportMatchArchive)
+	router.GET("/:id/stream", middleware.JWTAuthMiddleware(models.PermissionRead), streamMatch)
+	router.GET("/:id/track", middleware.JWTAuthMiddleware(models.PermissionTrack), trackMatch)
+	router.GET("/:id/shares", middleware.JWTAuthMiddleware(models.PermissionManage), getMatchShares)
+	router.POST("/:id/shares", middleware.JWTAuthMiddleware(models.PermissionManage), createShare)
+	router.DELETE("/:id/shares/:share", middleware.JWTAuthMiddleware(models.PermissionManage), revokeShare)
 }
 
 // getAllMatches retrieves all matches.
@@ -63,12 +85,41 @@ func createMatch(c *gin.Context) {
 		return
 	}
 
-	result, err := database.FindByName(c.Request.Context(), newMatch, newMatch.Name)
-	if result != nil {
-		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "Match name already used"})
+	if newMatch.Status != "" && !isMatchStatus(newMatch.Status) {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "Unknown match status"})
+		return
+	}
+
+	if newMatch.TrackedSide != "" && !models.IsSide(newMatch.TrackedSide) {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "Tracked side must be either home or away"})
 		return
 	}
 
+	dbMatch, err := insertMatch(c.Request.Context(), newMatch)
+	if errors.Is(err, errMatchNameUsed) {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbMatch)
+}
+
+// insertMatch creates the spreadsheet for each third of a new match and then
+// stores the match itself.
+func insertMatch(ctx context.Context, newMatch *models.Match) (*models.Match, error) {
+	result, _ := database.FindByName(ctx, newMatch, newMatch.Name)
+	if result != nil {
+		return nil, errMatchNameUsed
+	}
+
+	if newMatch.Status == "" {
+		newMatch.Status = models.MatchStatusScheduled
+	}
+
 	if newMatch.CreatedAt.IsZero() {
 		newMatch.CreatedAt = time.Now().UTC()
 	}
@@ -88,20 +139,17 @@ func createMatch(c *gin.Context) {
 	second := &models.Spreadsheet{Name: newMatch.Name + " - Second Third", Players: players}
 	third := &models.Spreadsheet{Name: newMatch.Name + " - Third Third", Players: players}
 
-	dbFirst, err := database.Insert(c.Request.Context(), first)
+	dbFirst, err := database.Insert(ctx, first)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
-		return
+		return nil, err
 	}
-	dbSecond, err := database.Insert(c.Request.Context(), second)
+	dbSecond, err := database.Insert(ctx, second)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
-		return
+		return nil, err
 	}
-	dbThird, err := database.Insert(c.Request.Context(), third)
+	dbThird, err := database.Insert(ctx, third)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
-		return
+		return nil, err
 	}
 
 	newMatch.Thirds["first"] = dbFirst.GetID()
@@ -109,13 +157,13 @@ func createMatch(c *gin.Context) {
 	newMatch.Thirds["third"] = dbThird.GetID()
 
 	newMatch.Players = nil
-	dbMatch, err := database.Insert(c.Request.Context(), newMatch)
+	dbMatch, err := database.Insert(ctx, newMatch)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
-		return
+		return nil, err
 	}
 
-	c.JSON(http.StatusCreated, dbMatch)
+	dispatchWebhooks(models.WebhookMatchCreated, newMatch, nil)
+	return dbMatch.(*models.Match), nil
 }
 
 // getMatchByID retrieves a match by ID.
@@ -169,10 +217,47 @@ func updateMatch(c *gin.Context) {
 	}
 
 	fetchedMatch := result.(*models.Match)
+	wasFinished := fetchedMatch.IsFinished()
 	if updatedMatch.Name != "" {
 		fetchedMatch.Name = updatedMatch.Name
 	}
 
+	if updatedMatch.Competition != "" {
+		fetchedMatch.Competition = updatedMatch.Competition
+	}
+
+	if updatedMatch.Season != "" {
+		fetchedMatch.Season = updatedMatch.Season
+	}
+
+	if updatedMatch.HomeTeam != "" {
+		fetchedMatch.HomeTeam = updatedMatch.HomeTeam
+	}
+
+	if updatedMatch.AwayTeam != "" {
+		fetchedMatch.AwayTeam = updatedMatch.AwayTeam
+	}
+
+	if updatedMatch.PeriodLength > 0 {
+		fetchedMatch.PeriodLength = updatedMatch.PeriodLength
+	}
+
+	if updatedMatch.TrackedSide != "" {
+		if !models.IsSide(updatedMatch.TrackedSide) {
+			c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "Tracked side must be either home or away"})
+			return
+		}
+		fetchedMatch.TrackedSide = updatedMatch.TrackedSide
+	}
+
+	if updatedMatch.Status != "" {
+		if !isMatchStatus(updatedMatch.Status) {
+			c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "Unknown match status"})
+			return
+		}
+		fetchedMatch.Status = updatedMatch.Status
+	}
+
 	res, err := database.Update(c.Request.Context(), fetchedMatch)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
@@ -184,6 +269,12 @@ func updateMatch(c *gin.Context) {
 		return
 	}
 
+	if fetchedMatch.IsFinished() {
+		advanceTournaments(c.Request.Context(), fetchedMatch)
+		if !wasFinished {
+			dispatchWebhooks(models.WebhookMatchFinished, fetchedMatch, nil)
+		}
+	}
 	c.JSON(http.StatusOK, fetchedMatch)
 }
 
@@ -214,3 +305,73 @@ func deleteMatch(c *gin.Context) {
 
 	c.JSON(http.StatusOK, models.HTTPError{Code: http.StatusOK, Message: "Successfully Deleted"})
 }
+
+// setMatchResult records the final score of a match and marks it as finished.
+// @Summary Record a match result
+// @Description set the final score of a match, optionally as a forfeit, and mark it as finished
+// @Tags matches
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Match ID"
+// @Param result body models.MatchResult true "Final result"
+// @Success 200 {object} models.Match "Match updated"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid JSON"
+// @Failure 404 {object} models.HTTPError "Match not found"
+// @Failure 422 {object} models.HTTPError "Bad request - invalid result"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /matches/{id}/result [put]
+func setMatchResult(c *gin.Context) {
+	var matchResult models.MatchResult
+	if err := c.ShouldBindJSON(&matchResult); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	if matchResult.HomeScore < 0 || matchResult.AwayScore < 0 {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "Scores cannot be negative"})
+		return
+	}
+
+	if matchResult.Forfeit != "" && matchResult.Forfeit != models.SideHome && matchResult.Forfeit != models.SideAway {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "Forfeit must be either home or away"})
+		return
+	}
+
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.Match{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Match not found"})
+		return
+	}
+
+	fetchedMatch := result.(*models.Match)
+	if fetchedMatch.HomeTeam == "" || fetchedMatch.AwayTeam == "" {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "Please set both teams before recording a result"})
+		return
+	}
+
+	wasFinished := fetchedMatch.IsFinished()
+	fetchedMatch.HomeScore = matchResult.HomeScore
+	fetchedMatch.AwayScore = matchResult.AwayScore
+	fetchedMatch.Forfeit = matchResult.Forfeit
+	fetchedMatch.Status = models.MatchStatusFinished
+
+	if _, err := database.Update(c.Request.Context(), fetchedMatch); err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	advanceTournaments(c.Request.Context(), fetchedMatch)
+	if !wasFinished {
+		dispatchWebhooks(models.WebhookMatchFinished, fetchedMatch, nil)
+	}
+	c.JSON(http.StatusOK, fetchedMatch)
+}
+
+func isMatchStatus(status string) bool {
+	switch status {
+	case models.MatchStatusScheduled, models.MatchStatusLive, models.MatchStatusFinished:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/league"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/gin-gonic/gin"
+)
+
+func TestCorrectForfeitToPlayedResult(t *testing.T) {
+	useMemoryDatabase(t)
+	insertTestUser(t, "coach", models.RoleCoach)
+
+	ctx := context.Background()
+	match := &models.Match{Name: "A v B", HomeTeam: "A", AwayTeam: "B"}
+	if _, err := database.Insert(ctx, match); err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	RegisterAuthRoutes(router.Group("/auth"))
+	RegisterMatchesRoutes(router.Group("/matches"))
+	path := "/matches/" + match.ID.Hex() + "/result"
+
+	results := []models.MatchResult{
+		{HomeScore: 0, AwayScore: 0, Forfeit: models.SideHome},
+		{HomeScore: 15, AwayScore: 12},
+	}
+	for _, result := range results {
+		if response := serveJSONAs(t, router, "coach", http.MethodPut, path, result); response.Code != http.StatusOK {
+			t.Fatalf("result %+v = %d, want %d: %s", result, response.Code, http.StatusOK, response.Body)
+		}
+	}
+
+	stored, err := database.Find(ctx, &models.Match{ID: match.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forfeit := stored.(*models.Match).Forfeit; forfeit != "" {
+		t.Errorf("forfeit = %q after correcting the result, want none", forfeit)
+	}
+
+	for _, standing := range league.Table([]*models.Match{stored.(*models.Match)}, models.DefaultLeagueRules()) {
+		if standing.Forfeited != 0 {
+			t.Errorf("%s forfeited %d matches, want 0", standing.Team, standing.Forfeited)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/formula"
+	"github.com/Tchoukball-Tracker/pkg/metrics"
+	middleware "github.com/Tchoukball-Tracker/pkg/middlewares"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/stats"
+	"github.com/Tchoukball-Tracker/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+var metricNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// RegisterMetricsRoutes registers metric-related routes in the provided router group.
+func RegisterMetricsRoutes(router *gin.RouterGroup) {
+	router.GET("", middleware.JWTAuthMiddleware(models.PermissionRead), getAllMetrics)
+	router.POST("", middleware.JWTAuthMiddleware(models.PermissionManage), createMetric)
+	router.GET("/definitions", middleware.JWTAuthMiddleware(models.PermissionRead), getMetricDefinitions)
+	router.GET("/:id", middleware.JWTAuthMiddleware(models.PermissionRead), getMetricByID)
+	router.PUT("/:id", middleware.JWTAuthMiddleware(models.PermissionManage), updateMetric)
+	router.DELETE("/:id", middleware.JWTAuthMiddleware(models.PermissionAdmin), deleteMetric)
+}
+
+// getAllMetrics retrieves all user-defined metrics.
+// @Summary Retrieve all user-defined metrics
+// @Description get all saved metric formulas from the database
+// @Tags metrics
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} models.Metric "List of metrics"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /metrics [get]
+func getAllMetrics(c *gin.Context) {
+	dbMetrics, err := database.FindAll(c.Request.Context(), &models.Metric{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dbMetrics)
+}
+
+// getMetricDefinitions retrieves the built-in derived metrics.
+// @Summary Retrieve the built-in metrics
+// @Description get the formula and description of every built-in derived metric
+// @Tags metrics
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} metrics.Definition "List of built-in metrics"
+// @Router /metrics/definitions [get]
+func getMetricDefinitions(c *gin.Context) {
+	c.JSON(http.StatusOK, metrics.Definitions)
+}
+
+// createMetric saves a new user-defined metric.
+// @Summary Create a new user-defined metric
+// @Description save a named metric as an expression over the action counters and built-in metrics
+// @Tags metrics
+// @Accept json
+// @Produce json
+// @Param metric body models.Metric true "Metric Info"
+// @Success 201 {object} models.Metric "Successfully created"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid JSON"
+// @Failure 422 {object} models.HTTPError "Bad request - invalid name or expression"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /metrics [post]
+func createMetric(c *gin.Context) {
+	var newMetric *models.Metric
+	if err := c.ShouldBindJSON(&newMetric); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	if message := validateMetric(c.Request.Context(), newMetric); message != "" {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: message})
+		return
+	}
+
+	newMetric.CreatedAt = time.Now().UTC()
+	dbMetric, err := database.Insert(c.Request.Context(), newMetric)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbMetric)
+}
+
+// getMetricByID retrieves a user-defined metric by ID.
+// @Summary Retrieve a user-defined metric by ID
+// @Description get metric by ID from the database
+// @Tags metrics
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Metric ID"
+// @Success 200 {object} models.Metric "Metric retrieved"
+// @Failure 404 {object} models.HTTPError "Metric not found"
+// @Router /metrics/{id} [get]
+func getMetricByID(c *gin.Context) {
+	hexID := c.Param("id")
+	dbMetric, err := database.Find(c.Request.Context(), &models.Metric{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Metric not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dbMetric)
+}
+
+// updateMetric updates a user-defined metric by ID.
+// @Summary Update a user-defined metric
+// @Description update the name, expression or description of a metric by ID
+// @Tags metrics
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Metric ID"
+// @Param metric body models.Metric true "Metric info"
+// @Success 200 {object} models.Metric "Metric updated"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid JSON"
+// @Failure 404 {object} models.HTTPError "Metric not found"
+// @Failure 422 {object} models.HTTPError "Bad request - invalid name or expression"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /metrics/{id} [put]
+func updateMetric(c *gin.Context) {
+	var updatedMetric *models.Metric
+	if err := c.ShouldBindJSON(&updatedMetric); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.Metric{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Metric not found"})
+		return
+	}
+
+	fetchedMetric := result.(*models.Metric)
+	if updatedMetric.Name == "" {
+		updatedMetric.Name = fetchedMetric.Name
+	}
+	if updatedMetric.Expression == "" {
+		updatedMetric.Expression = fetchedMetric.Expression
+	}
+	if updatedMetric.Description == "" {
+		updatedMetric.Description = fetchedMetric.Description
+	}
+	updatedMetric.ID = fetchedMetric.ID
+	updatedMetric.CreatedAt = fetchedMetric.CreatedAt
+
+	if message := validateMetric(c.Request.Context(), updatedMetric); message != "" {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: message})
+		return
+	}
+
+	if _, err := database.Update(c.Request.Context(), updatedMetric); err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedMetric)
+}
+
+// deleteMetric deletes a user-defined metric by ID.
+// @Summary Delete a user-defined metric
+// @Description delete a metric by ID
+// @Tags metrics
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Metric ID"
+// @Success 200 {string} string "Successfully deleted"
+// @Failure 404 {object} models.HTTPError "Metric not found"
+// @Router /metrics/{id} [delete]
+func deleteMetric(c *gin.Context) {
+	hexID := c.Param("id")
+	result, err := database.Delete(c.Request.Context(), &models.Metric{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Metric not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HTTPError{Code: http.StatusOK, Message: "Successfully Deleted"})
+}
+
+// validateMetric checks the name and expression of a metric, returning a
+// message describing the first problem found.
+func validateMetric(ctx context.Context, metric *models.Metric) string {
+	if !metricNamePattern.MatchString(metric.Name) {
+		return "Metric names must start with a letter and contain only letters, digits and underscores"
+	}
+
+	for _, variable := range metrics.Variables() {
+		if variable == metric.Name {
+			return "Metric name is already used by a counter or built-in metric"
+		}
+	}
+
+	result, _ := database.FindByName(ctx, &models.Metric{}, metric.Name)
+	if result != nil && result.GetID() != metric.ID {
+		return "Metric name already used"
+	}
+
+	if _, err := formula.Compile(metric.Expression, metrics.Variables()); err != nil {
+		return "Invalid expression: " + err.Error()
+	}
+	return ""
+}
+
+// loadCustomMetrics fetches and compiles every user-defined metric.
+func loadCustomMetrics(ctx context.Context) ([]*stats.CustomMetric, error) {
+	results, err := database.FindAll(ctx, &models.Metric{})
+	if err != nil {
+		return nil, err
+	}
+
+	saved := make([]*models.Metric, 0, len(results))
+	for _, result := range results {
+		saved = append(saved, result.(*models.Metric))
+	}
+	return stats.CompileMetrics(saved), nil
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	middleware "github.com/Tchoukball-Tracker/pkg/middlewares"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/stats"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterPlayersRoutes registers player-related routes in the provided router group.
+func RegisterPlayersRoutes(router *gin.RouterGroup) {
+	router.GET("/:id/stats", middleware.JWTAuthMiddleware(models.PermissionRead), getPlayerStats)
+}
+
+// getPlayerStats aggregates the career statistics of a player.
+// @Summary Retrieve player career statistics
+// @Description aggregate a player's totals, per-match and per-period averages over the matches selected by the filters
+// @Tags players
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Player name"
+// @Param from query string false "Only matches on or after this date (YYYY-MM-DD)"
+// @Param to query string false "Only matches on or before this date (YYYY-MM-DD)"
+// @Param season query string false "Only matches from this season"
+// @Param competition query string false "Only matches from this competition"
+// @Param team query string false "Only matches this team was tracked in"
+// @Param opponent query string false "Only matches where this team opposed the tracked players"
+// @Success 200 {object} models.CareerStats "Player statistics"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid filter"
+// @Failure 404 {object} models.HTTPError "No matches found for the player"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /players/{id}/stats [get]
+func getPlayerStats(c *gin.Context) {
+	var filter models.StatsFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	name := c.Param("id")
+	var totals []*models.PlayerTotals
+	if err := database.Aggregate(c.Request.Context(), &models.Match{}, stats.PlayerTotalsPipeline(filter, name), &totals); err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	if len(totals) == 0 {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "No matches found for the player"})
+		return
+	}
+
+	custom, err := loadCustomMetrics(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	career := stats.Career(totals[0], filter)
+	career.Custom = stats.Evaluate(custom, career.Totals)
+	c.JSON(http.StatusOK, career)
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	middleware "github.com/Tchoukball-Tracker/pkg/middlewares"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterQueriesRoutes registers saved query routes in the provided router group.
+func RegisterQueriesRoutes(router *gin.RouterGroup) {
+	router.GET("", middleware.JWTAuthMiddleware(models.PermissionRead), getAllQueries)
+	router.POST("", middleware.JWTAuthMiddleware(models.PermissionManage), createQuery)
+	router.GET("/:id", middleware.JWTAuthMiddleware(models.PermissionRead), getQueryByID)
+	router.PUT("/:id", middleware.JWTAuthMiddleware(models.PermissionManage), updateQuery)
+	router.DELETE("/:id", middleware.JWTAuthMiddleware(models.PermissionAdmin), deleteQuery)
+}
+
+// getAllQueries retrieves all saved queries.
+// @Summary Retrieve all saved queries
+// @Description get all saved match and player selections from the database
+// @Tags queries
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} models.Query "List of queries"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /queries [get]
+func getAllQueries(c *gin.Context) {
+	dbQueries, err := database.FindAll(c.Request.Context(), &models.Query{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dbQueries)
+}
+
+// createQuery saves a new query.
+// @Summary Create a new saved query
+// @Description save a selection of matches and players that graphs can use as their data source
+// @Tags queries
+// @Accept json
+// @Produce json
+// @Param query body models.Query true "Query Info"
+// @Success 201 {object} models.Query "Successfully created"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid JSON"
+// @Failure 422 {object} models.HTTPError "Bad request - missing or duplicate name"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /queries [post]
+func createQuery(c *gin.Context) {
+	var newQuery *models.Query
+	if err := c.ShouldBindJSON(&newQuery); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	if newQuery.Name == "" {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "Please provide a name for the query"})
+		return
+	}
+
+	if result, _ := database.FindByName(c.Request.Context(), &models.Query{}, newQuery.Name); result != nil {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "Query name already used"})
+		return
+	}
+
+	newQuery.CreatedAt = time.Now().UTC()
+	dbQuery, err := database.Insert(c.Request.Context(), newQuery)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbQuery)
+}
+
+// getQueryByID retrieves a saved query by ID.
+// @Summary Retrieve a saved query by ID
+// @Description get saved query by ID from the database
+// @Tags queries
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Query ID"
+// @Success 200 {object} models.Query "Query retrieved"
+// @Failure 404 {object} models.HTTPError "Query not found"
+// @Router /queries/{id} [get]
+func getQueryByID(c *gin.Context) {
+	hexID := c.Param("id")
+	dbQuery, err := database.Find(c.Request.Context(), &models.Query{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Query not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dbQuery)
+}
+
+// updateQuery updates a saved query by ID.
+// @Summary Update a saved query
+// @Description update the name, description, filter or players of a saved query by ID. Fields left out keep their value and an empty list of players selects the whole team
+// @Tags queries
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Query ID"
+// @Param query body models.Query true "Query info"
+// @Success 200 {object} models.Query "Query updated"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid JSON"
+// @Failure 404 {object} models.HTTPError "Query not found"
+// @Failure 422 {object} models.HTTPError "Bad request - duplicate name"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /queries/{id} [put]
+func updateQuery(c *gin.Context) {
+	var updatedQuery *models.Query
+	if err := c.ShouldBindJSON(&updatedQuery); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.Query{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Query not found"})
+		return
+	}
+
+	fetchedQuery := result.(*models.Query)
+	if updatedQuery.Name != "" && updatedQuery.Name != fetchedQuery.Name {
+		if result, _ := database.FindByName(c.Request.Context(), &models.Query{}, updatedQuery.Name); result != nil {
+			c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "Query name already used"})
+			return
+		}
+		fetchedQuery.Name = updatedQuery.Name
+	}
+
+	if updatedQuery.Description != "" {
+		fetchedQuery.Description = updatedQuery.Description
+	}
+
+	if !updatedQuery.Filter.IsEmpty() {
+		fetchedQuery.Filter = updatedQuery.Filter
+	}
+
+	if updatedQuery.Players != nil {
+		fetchedQuery.Players = updatedQuery.Players
+	}
+
+	if _, err := database.Update(c.Request.Context(), fetchedQuery); err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, fetchedQuery)
+}
+
+// deleteQuery deletes a saved query by ID.
+// @Summary Delete a saved query
+// @Description delete a saved query by ID
+// @Tags queries
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Query ID"
+// @Success 200 {string} string "Successfully deleted"
+// @Failure 404 {object} models.HTTPError "Query not found"
+// @Router /queries/{id} [delete]
+func deleteQuery(c *gin.Context) {
+	hexID := c.Param("id")
+	result, err := database.Delete(c.Request.Context(), &models.Query{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Query not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HTTPError{Code: http.StatusOK, Message: "Successfully Deleted"})
+}
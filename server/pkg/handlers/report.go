@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/report"
+	"github.com/Tchoukball-Tracker/pkg/stats"
+	"github.com/Tchoukball-Tracker/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// getMatchReport downloads the printable report of a match.
+// @Summary Retrieve a match report
+// @Description generate an A4 PDF report of a match with its teams, date and competition, the score of every third, the attacking and defending counters and metrics of every player, and charts of shots, scores and metrics
+// @Tags matches
+// @Produce  application/pdf
+// @Param id path string true "Match ID"
+// @Success 200 {file} file "PDF report"
+// @Failure 404 {object} models.HTTPError "Match not found"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /matches/{id}/report.pdf [get]
+func getMatchReport(c *gin.Context) {
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.Match{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Match not found"})
+		return
+	}
+
+	match := result.(*models.Match)
+	spreadsheets, err := findMatchSpreadsheets(c.Request.Context(), match)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	events, err := findMatchEvents(c.Request.Context(), match)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	matchStats := stats.FromSpreadsheets(match, spreadsheets)
+	document, err := report.Match(match, matchStats, stats.PeriodScores(matchStats, events), time.Now().UTC())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	var file bytes.Buffer
+	if err := document.Write(&file); err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="`+attachmentName(match.Name)+`.pdf"`)
+	c.Data(http.StatusOK, "application/pdf", file.Bytes())
+}
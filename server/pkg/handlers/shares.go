@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/live"
+	middleware "github.com/Tchoukball-Tracker/pkg/middlewares"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/stats"
+	"github.com/Tchoukball-Tracker/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	defaultShareHours = 24
+	maxShareHours     = 30 * 24
+)
+
+// RegisterPublicRoutes registers the routes reached through share links,
+// which need no account, in the provided router group.
+func RegisterPublicRoutes(router *gin.RouterGroup) {
+	router.GET("/shares/:token", middleware.ShareAuthMiddleware(), getSharedScoreboard)
+	router.GET("/shares/:token/stats", middleware.ShareAuthMiddleware(), getSharedStats)
+	router.GET("/shares/:token/stream", middleware.ShareAuthMiddleware(), streamSharedScoreboard)
+}
+
+// getMatchShares lists the share links of a match.
+// @Summary Retrieve the share links of a match
+// @Description get every share link of a match, including expired and revoked ones. Tokens are only returned when a share is created
+// @Tags matches
+// @Produce json
+// @Param id path string true "Match ID"
+// @Success 200 {array} models.Share "List of shares"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /matches/{id}/shares [get]
+func getMatchShares(c *gin.Context) {
+	hexID := c.Param("id")
+	shares, err := database.FindByValue(c.Request.Context(), &models.Share{}, bson.M{"match": utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, shares)
+}
+
+// createShare creates a share link of a match.
+// @Summary Share a match
+// @Description create a public read-only link to the score and statistics of a match, valid for the given number of hours, 24 by default and 720 at most. Player names are replaced by numbers if hide_players is set
+// @Tags matches
+// @Accept json
+// @Produce json
+// @Param id path string true "Match ID"
+// @Param share body models.ShareRequest true "Share Info"
+// @Success 201 {object} models.CreatedShare "Successfully created"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid JSON"
+// @Failure 404 {object} models.HTTPError "Match not found"
+// @Failure 422 {object} models.HTTPError "Bad request - invalid expiry"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /matches/{id}/shares [post]
+func createShare(c *gin.Context) {
+	var request models.ShareRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	if request.ExpiresIn == 0 {
+		request.ExpiresIn = defaultShareHours
+	}
+	if request.ExpiresIn < 0 || request.ExpiresIn > maxShareHours {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: fmt.Sprintf("Please provide an expiry between 1 and %d hours", maxShareHours)})
+		return
+	}
+
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.Match{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Match not found"})
+		return
+	}
+
+	now := time.Now().UTC()
+	share := &models.Share{
+		Match:       result.(*models.Match).ID,
+		Label:       request.Label,
+		HidePlayers: request.HidePlayers,
+		ExpiresAt:   now.Add(time.Duration(request.ExpiresIn) * time.Hour),
+		CreatedBy:   middleware.GetClaims(c).Username,
+		CreatedAt:   now,
+	}
+	if _, err := database.Insert(c.Request.Context(), share); err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	token, err := middleware.NewShareToken(share)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.CreatedShare{Share: share, Token: token, Path: "/public/shares/" + token})
+}
+
+// revokeShare revokes a share link of a match.
+// @Summary Revoke a share link
+// @Description stop a share link of a match from working. The share is kept in the list of shares with the time it was revoked
+// @Tags matches
+// @Produce json
+// @Param id path string true "Match ID"
+// @Param share path string true "Share ID"
+// @Success 200 {object} models.HTTPError "Successfully revoked"
+// @Failure 404 {object} models.HTTPError "Share not found"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /matches/{id}/shares/{share} [delete]
+func revokeShare(c *gin.Context) {
+	result, err := database.Find(c.Request.Context(), &models.Share{ID: utils.ConvertToMongoID(c.Param("share"))})
+	if err != nil || result.(*models.Share).Match != utils.ConvertToMongoID(c.Param("id")) {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Share not found"})
+		return
+	}
+
+	share := result.(*models.Share)
+	if share.RevokedAt == nil {
+		now := time.Now().UTC()
+		share.RevokedAt = &now
+		if _, err := database.Update(c.Request.Context(), share); err != nil {
+			c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, models.HTTPError{Code: http.StatusOK, Message: "Successfully Revoked"})
+}
+
+// getSharedScoreboard retrieves the scoreboard of a shared match.
+// @Summary Retrieve a shared scoreboard
+// @Description get the teams, result, status and score of every third of the match of a share link, without an account
+// @Tags public
+// @Produce json
+// @Param token path string true "Share token"
+// @Success 200 {object} models.Scoreboard "Scoreboard"
+// @Failure 401 {object} models.HTTPError "Invalid, expired or revoked share link"
+// @Failure 404 {object} models.HTTPError "Match not found"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /public/shares/{token} [get]
+func getSharedScoreboard(c *gin.Context) {
+	share := middleware.GetShare(c)
+	scoreboard, status, err := sharedScoreboard(c, share)
+	if err != nil {
+		c.JSON(status, models.HTTPError{Code: status, Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, scoreboard)
+}
+
+// getSharedStats computes the statistics of a shared match.
+// @Summary Retrieve shared match statistics
+// @Description compute the per-player totals, per-period breakdowns and team totals of the match of a share link, without an account. Player names are replaced by numbers if the share hides them
+// @Tags public
+// @Produce json
+// @Param token path string true "Share token"
+// @Success 200 {object} models.MatchStats "Match statistics"
+// @Failure 401 {object} models.HTTPError "Invalid, expired or revoked share link"
+// @Failure 404 {object} models.HTTPError "Match not found"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /public/shares/{token}/stats [get]
+func getSharedStats(c *gin.Context) {
+	share := middleware.GetShare(c)
+	result, err := database.Find(c.Request.Context(), &models.Match{ID: share.Match})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Match not found"})
+		return
+	}
+
+	matchStats, err := computeMatchStats(c.Request.Context(), result.(*models.Match))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	custom, err := loadCustomMetrics(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	stats.ApplyCustom(matchStats, custom)
+
+	if share.HidePlayers {
+		for i, player := range matchStats.Players {
+			player.Name = fmt.Sprintf("Player %d", i+1)
+		}
+	}
+
+	c.JSON(http.StatusOK, matchStats)
+}
+
+// streamSharedScoreboard follows the score of a shared match as Server-Sent Events.
+// @Summary Follow a shared scoreboard live
+// @Description stream the score of the match of a share link as Server-Sent Events, without an account. A new client first receives a snapshot event with the scoreboard, then a score event each time a point is recorded. A client reconnecting with the Last-Event-ID header receives only the events it missed. The stream ends once the share expires or is revoked
+// @Tags public
+// @Produce text/event-stream
+// @Param token path string true "Share token"
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Success 200 {string} string "Stream of events"
+// @Failure 401 {object} models.HTTPError "Invalid, expired or revoked share link"
+// @Failure 404 {object} models.HTTPError "Match not found"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /public/shares/{token}/stream [get]
+func streamSharedScoreboard(c *gin.Context) {
+	share := middleware.GetShare(c)
+	subscription := live.Subscribe(share.Match, c.GetHeader("Last-Event-ID"))
+	defer subscription.Cancel()
+
+	first := subscription.Missed
+	if !subscription.Resumed {
+		scoreboard, status, err := sharedScoreboard(c, share)
+		if err != nil {
+			c.JSON(status, models.HTTPError{Code: status, Message: err.Error()})
+			return
+		}
+		data, err := json.Marshal(scoreboard)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+			return
+		}
+		first = []live.Update{{ID: subscription.LastID, Type: models.LiveSnapshot, Data: data}}
+	}
+
+	// Only the score is public, the other updates name players.
+	keep := func(update live.Update) bool {
+		return update.Type == models.LiveSnapshot || update.Type == models.LiveScore
+	}
+	alive := func() bool {
+		result, err := database.Find(c.Request.Context(), &models.Share{ID: share.ID})
+		return err == nil && result.(*models.Share).Active(time.Now())
+	}
+	serveEvents(c, subscription, first, keep, alive)
+}
+
+// sharedScoreboard reads the scoreboard of the match of a share, returning
+// the status to reply with on error.
+func sharedScoreboard(c *gin.Context, share *models.Share) (*models.Scoreboard, int, error) {
+	result, err := database.Find(c.Request.Context(), &models.Match{ID: share.Match})
+	if err != nil {
+		return nil, http.StatusNotFound, fmt.Errorf("Match not found")
+	}
+	match := result.(*models.Match)
+
+	spreadsheets, err := findMatchSpreadsheets(c.Request.Context(), match)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	score, err := liveScore(c.Request.Context(), match, spreadsheets)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return &models.Scoreboard{
+		Name:        match.Name,
+		Competition: match.Competition,
+		Season:      match.Season,
+		HomeTeam:    match.HomeTeam,
+		AwayTeam:    match.AwayTeam,
+		HomeScore:   match.HomeScore,
+		AwayScore:   match.AwayScore,
+		Status:      match.Status,
+		Forfeit:     match.Forfeit,
+		Score:       score,
+		ExpiresAt:   share.ExpiresAt,
+	}, http.StatusOK, nil
+}
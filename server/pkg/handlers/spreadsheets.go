@@ -2,25 +2,30 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"sync"
 
 	"github.com/Tchoukball-Tracker/pkg/database"
 	middleware "github.com/Tchoukball-Tracker/pkg/middlewares"
 	"github.com/Tchoukball-Tracker/pkg/models"
 	"github.com/Tchoukball-Tracker/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // RegisterRoutes registers spreadsheet-related routes in the provided router group.
 func RegisterSpreadsheetsRoutes(router *gin.RouterGroup) {
-	router.GET("", middleware.JWTAuthMiddleware(), getAllSpreadsheets)
-	router.POST("", middleware.JWTAuthMiddleware(), createSpreadsheet)
-	router.GET("/:id", middleware.JWTAuthMiddleware(), getSpreadsheetByID)
-	router.PUT("/:id", middleware.JWTAuthMiddleware(), updateSpreadsheet)
-	router.DELETE("/:id", middleware.JWTAuthMiddleware(), deleteSpreadsheet)
-	router.POST("/:id/player", middleware.JWTAuthMiddleware(), createPlayer)
-	router.DELETE("/:id/player", middleware.JWTAuthMiddleware(), removePlayer)
-	router.POST("/:id/player/:player/action", middleware.JWTAuthMiddleware(), createPlayerAction)
+	router.GET("", middleware.JWTAuthMiddleware(models.PermissionRead), getAllSpreadsheets)
+	router.POST("", middleware.JWTAuthMiddleware(models.PermissionManage), createSpreadsheet)
+	router.POST("/import", middleware.JWTAuthMiddleware(models.PermissionManage), importSpreadsheet)
+	router.GET("/:id", middleware.JWTAuthMiddleware(models.PermissionRead), getSpreadsheetByID)
+	router.PUT("/:id", middleware.JWTAuthMiddleware(models.PermissionManage), updateSpreadsheet)
+	router.DELETE("/:id", middleware.JWTAuthMiddleware(models.PermissionAdmin), deleteSpreadsheet)
+	router.POST("/:id/player", middleware.JWTAuthMiddleware(models.PermissionManage), createPlayer)
+	router.DELETE("/:id/player", middleware.JWTAuthMiddleware(models.PermissionManage), removePlayer)
+	router.POST("/:id/player/:player/action", middleware.JWTAuthMiddleware(models.PermissionTrack), createPlayerAction)
+	router.GET("/:id/export.xlsx", middleware.JWTAuthMiddleware(models.PermissionRead), exportSpreadsheet)
 }
 
 // GetAllSpreadsheets retrieves all spreadsheets.
@@ -122,6 +127,9 @@ func updateSpreadsheet(c *gin.Context) {
 	hexID := c.Param("id")
 	updatedSpreadsheet.ID = utils.ConvertToMongoID(hexID)
 
+	unlock := lockSpreadsheet(updatedSpreadsheet.ID)
+	defer unlock()
+
 	result, err := database.Find(c.Request.Context(), updatedSpreadsheet)
 	if err != nil {
 		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Spreadsheet not found"})
@@ -200,6 +208,9 @@ func createPlayer(c *gin.Context) {
 		return
 	}
 
+	unlock := lockSpreadsheet(utils.ConvertToMongoID(hexID))
+	defer unlock()
+
 	dbResult, err := database.Find(c.Request.Context(), &models.Spreadsheet{ID: utils.ConvertToMongoID(hexID)})
 	if err != nil {
 		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Spreadsheet not found"})
@@ -215,6 +226,7 @@ func createPlayer(c *gin.Context) {
 		return
 	}
 
+	publishPlayerUpdate(c.Request.Context(), nil, models.LivePlayerAdded, spreadsheet, spreadsheet.Players[len(spreadsheet.Players)-1], nil, nil)
 	c.JSON(http.StatusCreated, spreadsheet)
 }
 
@@ -239,6 +251,9 @@ func removePlayer(c *gin.Context) {
 		return
 	}
 
+	unlock := lockSpreadsheet(utils.ConvertToMongoID(hexID))
+	defer unlock()
+
 	dbResult, err := database.Find(c.Request.Context(), &models.Spreadsheet{ID: utils.ConvertToMongoID(hexID)})
 	if err != nil {
 		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Spreadsheet not found"})
@@ -259,6 +274,8 @@ func removePlayer(c *gin.Context) {
 		return
 	}
 
+	publishPlayerUpdate(c.Request.Context(), nil, models.LivePlayerRemoved, spreadsheet, existing, nil, nil)
+
 	c.JSON(http.StatusCreated, spreadsheet)
 }
 
@@ -285,29 +302,86 @@ func createPlayerAction(c *gin.Context) {
 		return
 	}
 
-	if newAction.Type == "" {
-		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "Please provide an action type"})
+	player, httpErr := recordPlayerAction(c.Request.Context(), utils.ConvertToMongoID(hexID), c.Param("player"), newAction, nil)
+	if httpErr != nil {
+		c.JSON(httpErr.Code, httpErr)
 		return
 	}
 
-	dbResult, err := database.Find(c.Request.Context(), &models.Spreadsheet{ID: utils.ConvertToMongoID(hexID)})
+	c.JSON(http.StatusCreated, player)
+}
+
+// recordPlayerAction adds an action to the counters of a player on a
+// spreadsheet, logs it and sends it to webhooks and live clients. The tracker
+// is set when the action comes from a tracking session.
+func recordPlayerAction(ctx context.Context, spreadsheetID primitive.ObjectID, name string, action models.PlayerAction, tracker *models.Tracker) (*models.Player, *models.HTTPError) {
+	if action.Type == "" {
+		return nil, &models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "Please provide an action type"}
+	}
+
+	unlock := lockSpreadsheet(spreadsheetID)
+	defer unlock()
+
+	dbResult, err := database.Find(ctx, &models.Spreadsheet{ID: spreadsheetID})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
-		return
+		return nil, &models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()}
 	}
 
 	spreadsheet := dbResult.(*models.Spreadsheet)
-	player := spreadsheet.FindPlayer(c.Param("player"))
+	player := spreadsheet.FindPlayer(name)
 	if player == nil {
-		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Failed to find player with that name"})
+		return nil, &models.HTTPError{Code: http.StatusNotFound, Message: "Failed to find player with that name"}
 	}
-	player.AddAction(newAction)
+	player.AddAction(action)
 
-	_, err = database.Update(c.Request.Context(), spreadsheet)
+	_, err = database.Update(ctx, spreadsheet)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
-		return
+		return nil, &models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()}
 	}
 
-	c.JSON(http.StatusCreated, player)
+	match, event := logPlayerAction(ctx, spreadsheet, player.Name, action)
+	if match != nil && action.Type == "point" && action.Value > 0 {
+		dispatchWebhooks(models.WebhookPointRecorded, match, event)
+	}
+	if match != nil {
+		publishPlayerUpdate(ctx, match, models.LiveAction, spreadsheet, player, &action, tracker)
+	}
+	return player, nil
+}
+
+// spreadsheetLocks serialises the writes to each spreadsheet, which read the
+// whole document, change it and write it back, so that concurrent writes do
+// not overwrite each other.
+var spreadsheetLocks = struct {
+	sync.Mutex
+	held map[primitive.ObjectID]*spreadsheetLock
+}{held: make(map[primitive.ObjectID]*spreadsheetLock)}
+
+type spreadsheetLock struct {
+	sync.Mutex
+	users int // Writers holding or waiting for the lock
+}
+
+// lockSpreadsheet waits until no other write to a spreadsheet is in progress
+// and returns the function that ends the write.
+func lockSpreadsheet(id primitive.ObjectID) (unlock func()) {
+	spreadsheetLocks.Lock()
+	lock, ok := spreadsheetLocks.held[id]
+	if !ok {
+		lock = &spreadsheetLock{}
+		spreadsheetLocks.held[id] = lock
+	}
+	lock.users++
+	spreadsheetLocks.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		spreadsheetLocks.Lock()
+		lock.users--
+		if lock.users == 0 {
+			delete(spreadsheetLocks.held, id)
+		}
+		spreadsheetLocks.Unlock()
+	}
 }
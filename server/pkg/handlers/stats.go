@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/stats"
+	"github.com/Tchoukball-Tracker/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// getMatchStats computes the aggregate statistics of a match.
+// @Summary Retrieve match statistics
+// @Description compute per-player totals, per-period breakdowns and team totals across all thirds of a match, from the spreadsheet counters, or from its action log when the logged actions add up to the same counters. The source used is reported.
+// @Tags matches
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Match ID"
+// @Success 200 {object} models.MatchStats "Match statistics"
+// @Failure 404 {object} models.HTTPError "Match not found"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /matches/{id}/stats [get]
+func getMatchStats(c *gin.Context) {
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.Match{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Match not found"})
+		return
+	}
+
+	match := result.(*models.Match)
+	matchStats, err := computeMatchStats(c.Request.Context(), match)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	custom, err := loadCustomMetrics(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	stats.ApplyCustom(matchStats, custom)
+
+	c.JSON(http.StatusOK, matchStats)
+}
+
+// computeMatchStats totals the spreadsheets of a match, replaying its action
+// log instead when the log holds player actions that add up to the same
+// counters. Counters entered or corrected on the spreadsheets directly are
+// therefore never hidden by a partial log. The source used is reported on
+// the statistics.
+func computeMatchStats(ctx context.Context, match *models.Match) (*models.MatchStats, error) {
+	spreadsheets, err := findMatchSpreadsheets(ctx, match)
+	if err != nil {
+		return nil, err
+	}
+	fromCounters := stats.FromSpreadsheets(match, spreadsheets)
+
+	events, err := findMatchEvents(ctx, match)
+	if err != nil {
+		return nil, err
+	}
+	if stats.HasActions(events) {
+		if fromEvents := stats.FromEvents(match, events); stats.Reconciles(fromEvents, fromCounters) {
+			return fromEvents, nil
+		}
+	}
+	return fromCounters, nil
+}
+
+// findMatchSpreadsheets fetches the spreadsheet of every third of the match, keyed by period.
+func findMatchSpreadsheets(ctx context.Context, match *models.Match) (map[string]*models.Spreadsheet, error) {
+	ids := make([]primitive.ObjectID, 0, len(match.Thirds))
+	for _, id := range match.Thirds {
+		ids = append(ids, id)
+	}
+
+	results, err := database.FindByValue(ctx, &models.Spreadsheet{}, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+
+	spreadsheets := make(map[string]*models.Spreadsheet, len(results))
+	for _, result := range results {
+		spreadsheet := result.(*models.Spreadsheet)
+		spreadsheets[match.PeriodOf(spreadsheet.ID)] = spreadsheet
+	}
+	return spreadsheets, nil
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMatchStatsSource(t *testing.T) {
+	action := func(value int) *models.Event {
+		return &models.Event{Period: "first", Type: models.EventAction, Player: "Alex", Action: "point", Value: value}
+	}
+	periodStart := &models.Event{Period: "first", Type: models.EventPeriodStart}
+
+	tests := []struct {
+		name       string
+		events     []*models.Event
+		wantSource string
+	}{
+		{name: "no events", events: nil, wantSource: models.StatsSourceCounters},
+		{name: "only a period start", events: []*models.Event{periodStart}, wantSource: models.StatsSourceCounters},
+		{name: "actions short of the spreadsheet", events: []*models.Event{periodStart, action(1)}, wantSource: models.StatsSourceCounters},
+		{name: "actions adding up to the spreadsheet", events: []*models.Event{periodStart, action(2), action(1)}, wantSource: models.StatsSourceEvents},
+	}
+
+	for _, test := range tests {
+		useMemoryDatabase(t)
+		insertTestUser(t, "viewer", models.RoleViewer)
+		ctx := context.Background()
+
+		spreadsheet, err := database.Insert(ctx, &models.Spreadsheet{Name: "first", Players: []*models.Player{
+			{Name: "Alex", Attacking: models.Attacking{Point: 3}},
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		match, err := database.Insert(ctx, &models.Match{Name: "match", Thirds: map[string]primitive.ObjectID{"first": spreadsheet.GetID()}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, event := range test.events {
+			event := *event
+			event.Match = match.GetID()
+			if _, err := database.Insert(ctx, &event); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		router := gin.New()
+		RegisterAuthRoutes(router.Group("/auth"))
+		RegisterMatchesRoutes(router.Group("/matches"))
+
+		recorder := serveJSONAs(t, router, "viewer", http.MethodGet, "/matches/"+match.GetID().Hex()+"/stats", nil)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("%s: stats = %d, want %d", test.name, recorder.Code, http.StatusOK)
+		}
+
+		var matchStats models.MatchStats
+		if err := json.Unmarshal(recorder.Body.Bytes(), &matchStats); err != nil {
+			t.Fatal(err)
+		}
+		if matchStats.Source != test.wantSource || matchStats.Totals.Attacking.Point != 3 {
+			t.Errorf("%s: source %s with %d points, want %s with 3", test.name, matchStats.Source, matchStats.Totals.Attacking.Point, test.wantSource)
+		}
+	}
+}
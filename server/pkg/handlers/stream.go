@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/live"
+	"github.com/Tchoukball-Tracker/pkg/logger"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/stats"
+	"github.com/Tchoukball-Tracker/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// heartbeatInterval is how often a comment is sent on an idle stream so that
+// proxies keep the connection open and clients notice when it drops.
+var heartbeatInterval = 15 * time.Second
+
+// streamRetry is the delay in milliseconds clients wait before reconnecting.
+const streamRetry = 3000
+
+// streamMatch follows the changes to a match as Server-Sent Events.
+// @Summary Follow a match live
+// @Description stream the changes to a match as Server-Sent Events. A new client first receives a snapshot event with the match, its spreadsheets, score and trackers, then action, player_added, player_removed, score and presence events as they happen. A client reconnecting with the Last-Event-ID header receives only the events it missed, or a new snapshot if they are no longer available. A comment is sent every 15 seconds while the stream is idle
+// @Tags matches
+// @Produce text/event-stream
+// @Param id path string true "Match ID"
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Success 200 {string} string "Stream of events"
+// @Failure 404 {object} models.HTTPError "Match not found"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /matches/{id}/stream [get]
+func streamMatch(c *gin.Context) {
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.Match{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Match not found"})
+		return
+	}
+	match := result.(*models.Match)
+
+	// Subscribe before reading the snapshot so that no change made while it
+	// is read is missed. Such a change may then be sent twice, which is
+	// harmless as every event carries the full state of what it changed.
+	subscription := live.Subscribe(match.ID, c.GetHeader("Last-Event-ID"))
+	defer subscription.Cancel()
+
+	first := subscription.Missed
+	if !subscription.Resumed {
+		snapshot, err := liveMatch(c.Request.Context(), match)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+			return
+		}
+		data, err := json.Marshal(snapshot)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+			return
+		}
+		first = []live.Update{{ID: subscription.LastID, Type: models.LiveSnapshot, Data: data}}
+	}
+
+	serveEvents(c, subscription, first, nil, nil)
+}
+
+// serveEvents writes the first updates then those of a subscription as
+// Server-Sent Events until the client leaves or falls behind, sending a
+// heartbeat while idle. Updates for which keep returns false are left out,
+// and the stream ends at a heartbeat if alive returns false. Either may be
+// nil.
+func serveEvents(c *gin.Context, subscription *live.Subscription, first []live.Update, keep func(live.Update) bool, alive func() bool) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetry)
+
+	for _, update := range first {
+		if keep == nil || keep(update) {
+			writeEvent(c, update)
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case update, ok := <-subscription.Updates:
+			if !ok {
+				// The client fell behind and was dropped, it resumes on reconnecting.
+				return
+			}
+			if keep != nil && !keep(update) {
+				continue
+			}
+			writeEvent(c, update)
+		case <-heartbeat.C:
+			if alive != nil && !alive() {
+				return
+			}
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+		case <-c.Request.Context().Done():
+			return
+		}
+		c.Writer.Flush()
+	}
+}
+
+// writeEvent writes an update in the Server-Sent Events format.
+func writeEvent(c *gin.Context, update live.Update) {
+	fmt.Fprintf(c.Writer, "id: %s\nevent: %s\n", update.ID, update.Type)
+	for _, line := range strings.Split(string(update.Data), "\n") {
+		fmt.Fprintf(c.Writer, "data: %s\n", line)
+	}
+	fmt.Fprint(c.Writer, "\n")
+}
+
+// liveMatch reads the current state of a match for a new client.
+func liveMatch(ctx context.Context, match *models.Match) (*models.LiveMatch, error) {
+	spreadsheets, err := findMatchSpreadsheets(ctx, match)
+	if err != nil {
+		return nil, err
+	}
+	score, err := liveScore(ctx, match, spreadsheets)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LiveMatch{Match: match, Spreadsheets: spreadsheets, Score: score, Trackers: matchTrackers(match.ID)}, nil
+}
+
+// liveScore computes the score of a match from its spreadsheets and the
+// points conceded in its action log.
+func liveScore(ctx context.Context, match *models.Match, spreadsheets map[string]*models.Spreadsheet) (*models.LiveScoreUpdate, error) {
+	events, err := findMatchEvents(ctx, match)
+	if err != nil {
+		return nil, err
+	}
+
+	score := &models.LiveScoreUpdate{Periods: stats.PeriodScores(stats.FromSpreadsheets(match, spreadsheets), events)}
+	for _, period := range score.Periods {
+		score.Scored += period.Scored
+		score.Conceded += period.Conceded
+	}
+	return score, nil
+}
+
+// publishPlayerUpdate sends a change to a player of a spreadsheet to the
+// clients following the match the spreadsheet is used for, if any. Point
+// actions, including corrections taking points away, and removing a player,
+// whose points no longer count, also send the recomputed score.
+func publishPlayerUpdate(ctx context.Context, match *models.Match, updateType string, spreadsheet *models.Spreadsheet, player *models.Player, action *models.PlayerAction, tracker *models.Tracker) {
+	if match == nil {
+		match, _ = findMatchBySpreadsheet(ctx, spreadsheet.ID)
+		if match == nil {
+			return
+		}
+	}
+
+	update := &models.LivePlayerUpdate{
+		Spreadsheet: spreadsheet.ID.Hex(),
+		Period:      match.PeriodOf(spreadsheet.ID),
+		Player:      player,
+		Action:      action,
+		Tracker:     tracker,
+	}
+	if err := live.Publish(match.ID, updateType, update); err != nil {
+		logger.Log.Errorf("Failed to publish %s of match %s: %v", updateType, match.ID.Hex(), err)
+	}
+
+	pointAction := action != nil && action.Type == "point" && action.Value != 0
+	if pointAction || updateType == models.LivePlayerRemoved {
+		publishScore(ctx, match)
+	}
+}
+
+// publishScore sends the current score of a match to the clients following it.
+func publishScore(ctx context.Context, match *models.Match) {
+	spreadsheets, err := findMatchSpreadsheets(ctx, match)
+	if err != nil {
+		logger.Log.Errorf("Failed to find spreadsheets of match %s: %v", match.ID.Hex(), err)
+		return
+	}
+	score, err := liveScore(ctx, match, spreadsheets)
+	if err != nil {
+		logger.Log.Errorf("Failed to compute score of match %s: %v", match.ID.Hex(), err)
+		return
+	}
+	if err := live.Publish(match.ID, models.LiveScore, score); err != nil {
+		logger.Log.Errorf("Failed to publish score of match %s: %v", match.ID.Hex(), err)
+	}
+}
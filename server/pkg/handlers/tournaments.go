@@ -0,0 +1,411 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/logger"
+	middleware "github.com/Tchoukball-Tracker/pkg/middlewares"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/tournament"
+	"github.com/Tchoukball-Tracker/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RegisterTournamentsRoutes registers tournament-related routes in the provided router group.
+func RegisterTournamentsRoutes(router *gin.RouterGroup) {
+	router.GET("", middleware.JWTAuthMiddleware(models.PermissionRead), getAllTournaments)
+	router.POST("", middleware.JWTAuthMiddleware(models.PermissionManage), createTournament)
+	router.GET("/:id", middleware.JWTAuthMiddleware(models.PermissionRead), getTournamentByID)
+	router.DELETE("/:id", middleware.JWTAuthMiddleware(models.PermissionAdmin), deleteTournament)
+	router.GET("/:id/standings", middleware.JWTAuthMiddleware(models.PermissionRead), getTournamentStandings)
+	router.POST("/:id/knockout", middleware.JWTAuthMiddleware(models.PermissionManage), seedTournamentKnockout)
+}
+
+// getAllTournaments retrieves all tournaments.
+// @Summary Retrieve all tournaments
+// @Description get all tournaments from the database
+// @Tags tournaments
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} models.Tournament "List of tournaments"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /tournaments [get]
+func getAllTournaments(c *gin.Context) {
+	dbTournaments, err := database.FindAll(c.Request.Context(), &models.Tournament{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dbTournaments)
+}
+
+// createTournament creates a new tournament and a match for every pool fixture.
+// @Summary Create a new tournament
+// @Description draw the registered teams into pools and create a match for every round robin fixture
+// @Tags tournaments
+// @Accept json
+// @Produce json
+// @Param tournament body models.Tournament true "Tournament Info"
+// @Success 201 {object} models.Tournament "Successfully created"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid JSON"
+// @Failure 422 {object} models.HTTPError "Bad request - missing element"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /tournaments [post]
+func createTournament(c *gin.Context) {
+	var newTournament *models.Tournament
+	if err := c.ShouldBindJSON(&newTournament); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	if newTournament.Name == "" {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "Please provide a name for the Tournament"})
+		return
+	}
+
+	result, _ := database.FindByName(c.Request.Context(), newTournament, newTournament.Name)
+	if result != nil {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "Tournament name already used"})
+		return
+	}
+
+	if err := validateTournament(newTournament); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: err.Error()})
+		return
+	}
+
+	newTournament.Pools = tournament.DrawPools(newTournament.Teams, newTournament.PoolCount)
+	newTournament.Knockout = []*models.Round{}
+	newTournament.Status = models.TournamentStatusPools
+	newTournament.Winner = ""
+	newTournament.CreatedAt = time.Now().UTC()
+
+	matches, err := createFixtureMatches(c.Request.Context(), newTournament)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	dbTournament, err := database.Insert(c.Request.Context(), newTournament)
+	if err != nil {
+		discardMatches(c.Request.Context(), matches)
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbTournament)
+}
+
+// getTournamentByID retrieves a tournament by ID.
+// @Summary Retrieve a tournament by ID
+// @Description get tournament by ID from the database
+// @Tags tournaments
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Tournament ID"
+// @Success 200 {object} models.Tournament "Tournament retrieved"
+// @Failure 404 {object} models.HTTPError "Tournament not found"
+// @Router /tournaments/{id} [get]
+func getTournamentByID(c *gin.Context) {
+	hexID := c.Param("id")
+	dbTournament, err := database.Find(c.Request.Context(), &models.Tournament{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Tournament not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dbTournament)
+}
+
+// deleteTournament deletes a tournament by ID. The fixture matches are kept.
+// @Summary Delete a tournament
+// @Description delete a tournament by ID, the matches played in it are kept
+// @Tags tournaments
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Tournament ID"
+// @Success 200 {string} string "Successfully deleted"
+// @Failure 404 {object} models.HTTPError "Tournament not found"
+// @Router /tournaments/{id} [delete]
+func deleteTournament(c *gin.Context) {
+	hexID := c.Param("id")
+	result, err := database.Delete(c.Request.Context(), &models.Tournament{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Tournament not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HTTPError{Code: http.StatusOK, Message: "Successfully Deleted"})
+}
+
+// getTournamentStandings retrieves the pool standings of a tournament.
+// @Summary Retrieve pool standings
+// @Description compute the standings of every pool from the finished pool matches
+// @Tags tournaments
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Tournament ID"
+// @Success 200 {array} models.PoolStandings "Pool standings"
+// @Failure 404 {object} models.HTTPError "Tournament not found"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /tournaments/{id}/standings [get]
+func getTournamentStandings(c *gin.Context) {
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.Tournament{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Tournament not found"})
+		return
+	}
+
+	t := result.(*models.Tournament)
+	matches, err := findPoolMatches(c.Request.Context(), t)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	standings := make([]models.PoolStandings, 0, len(t.Pools))
+	for _, pool := range t.Pools {
+		standings = append(standings, models.PoolStandings{Pool: pool.Name, Standings: tournament.PoolStandings(pool, matches)})
+	}
+
+	c.JSON(http.StatusOK, standings)
+}
+
+// seedTournamentKnockout seeds the knockout rounds from the pool standings.
+// @Summary Seed the knockout rounds
+// @Description seed the knockout bracket from the pool standings once every pool match is finished
+// @Tags tournaments
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Tournament ID"
+// @Success 200 {object} models.Tournament "Knockout seeded"
+// @Failure 404 {object} models.HTTPError "Tournament not found"
+// @Failure 422 {object} models.HTTPError "Pools unfinished or knockout already seeded"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /tournaments/{id}/knockout [post]
+func seedTournamentKnockout(c *gin.Context) {
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.Tournament{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Tournament not found"})
+		return
+	}
+
+	t := result.(*models.Tournament)
+	err = seedKnockout(c.Request.Context(), t)
+	if errors.Is(err, tournament.ErrPoolsUnfinished) || errors.Is(err, tournament.ErrAlreadySeeded) {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	if _, err := database.Update(c.Request.Context(), t); err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, t)
+}
+
+func validateTournament(t *models.Tournament) error {
+	seen := make(map[string]bool, len(t.Teams))
+	for _, team := range t.Teams {
+		if team == "" {
+			return errors.New("Team names cannot be empty")
+		}
+		if seen[team] {
+			return fmt.Errorf("Team %s is registered more than once", team)
+		}
+		seen[team] = true
+	}
+
+	if t.PoolCount == 0 {
+		t.PoolCount = 1
+	}
+	if t.PoolCount < 0 || len(t.Teams) < 2*t.PoolCount {
+		return errors.New("Every pool needs at least two teams")
+	}
+
+	if t.QualifiersPerPool == 0 {
+		t.QualifiersPerPool = 2
+	}
+	if t.QualifiersPerPool < 0 || t.QualifiersPerPool > len(t.Teams)/t.PoolCount {
+		return errors.New("Qualifiers per pool cannot exceed the size of the smallest pool")
+	}
+	return nil
+}
+
+// seedKnockout seeds the knockout bracket and creates the matches for the first round.
+func seedKnockout(ctx context.Context, t *models.Tournament) error {
+	matches, err := findPoolMatches(ctx, t)
+	if err != nil {
+		return err
+	}
+
+	if err := tournament.Seed(t, matches); err != nil {
+		return err
+	}
+
+	_, err = createFixtureMatches(ctx, t)
+	return err
+}
+
+// createFixtureMatches creates a match for every fixture whose teams are known,
+// returning the matches created. If one cannot be created, those created
+// before it are discarded.
+func createFixtureMatches(ctx context.Context, t *models.Tournament) ([]*models.Match, error) {
+	competition := t.Competition
+	if competition == "" {
+		competition = t.Name
+	}
+
+	ready := tournament.ReadyFixtures(t)
+	created := make([]*models.Match, 0, len(ready))
+	for _, fixture := range ready {
+		match, err := insertMatch(ctx, &models.Match{
+			Name:        fixtureMatchName(t, fixture),
+			Competition: competition,
+			Season:      t.Season,
+			HomeTeam:    fixture.HomeTeam,
+			AwayTeam:    fixture.AwayTeam,
+		})
+		if err != nil {
+			discardMatches(ctx, created)
+			for _, fixture := range ready[:len(created)] {
+				fixture.Match = primitive.NilObjectID
+			}
+			return nil, err
+		}
+		fixture.Match = match.ID
+		created = append(created, match)
+	}
+	return created, nil
+}
+
+// fixtureMatchName names the match of a fixture after the tournament, its
+// stage and its teams.
+func fixtureMatchName(t *models.Tournament, fixture *models.Fixture) string {
+	return fmt.Sprintf("%s - %s - %s vs %s", t.Name, tournament.Stage(t, fixture), fixture.HomeTeam, fixture.AwayTeam)
+}
+
+// discardMatches deletes matches created for a tournament that could not be
+// saved, along with the spreadsheets of their thirds.
+func discardMatches(ctx context.Context, matches []*models.Match) {
+	for _, match := range matches {
+		for _, id := range match.Thirds {
+			if _, err := database.Delete(ctx, &models.Spreadsheet{ID: id}); err != nil {
+				logger.Log.Errorf("Failed to discard spreadsheet %s: %v", id.Hex(), err)
+			}
+		}
+		if _, err := database.Delete(ctx, match); err != nil {
+			logger.Log.Errorf("Failed to discard match %s: %v", match.Name, err)
+		}
+	}
+}
+
+// syncKnockoutMatches updates the teams and name of the knockout matches already
+// created when a corrected result has moved another team into their fixture.
+// Matches that have finished are left as they are.
+func syncKnockoutMatches(ctx context.Context, t *models.Tournament) error {
+	for _, round := range t.Knockout {
+		for _, fixture := range round.Fixtures {
+			if fixture.Match.IsZero() || fixture.Finished {
+				continue
+			}
+
+			result, err := database.Find(ctx, &models.Match{ID: fixture.Match})
+			if err != nil {
+				return err
+			}
+			match := result.(*models.Match)
+			if match.HomeTeam == fixture.HomeTeam && match.AwayTeam == fixture.AwayTeam {
+				continue
+			}
+			if match.IsFinished() {
+				logger.Log.Warnf("Match %s has finished, so it keeps its teams after a corrected result", match.Name)
+				continue
+			}
+
+			match.Name = fixtureMatchName(t, fixture)
+			match.HomeTeam = fixture.HomeTeam
+			match.AwayTeam = fixture.AwayTeam
+			if _, err := database.Update(ctx, match); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// findPoolMatches fetches the matches played in the pools of the tournament, keyed by ID.
+func findPoolMatches(ctx context.Context, t *models.Tournament) (map[primitive.ObjectID]*models.Match, error) {
+	var ids []primitive.ObjectID
+	for _, pool := range t.Pools {
+		for _, fixture := range pool.Fixtures {
+			ids = append(ids, fixture.Match)
+		}
+	}
+
+	results, err := database.FindByValue(ctx, &models.Match{}, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make(map[primitive.ObjectID]*models.Match, len(results))
+	for _, result := range results {
+		match := result.(*models.Match)
+		matches[match.ID] = match
+	}
+	return matches, nil
+}
+
+// advanceTournaments records the result of a finished match in any tournament
+// it is a fixture of, seeding the knockout once the pools are complete and
+// creating the matches for fixtures that are now ready.
+func advanceTournaments(ctx context.Context, match *models.Match) {
+	results, err := database.FindByValue(ctx, &models.Tournament{}, bson.M{"$or": bson.A{
+		bson.M{"pools.fixtures.match": match.ID},
+		bson.M{"knockout.fixtures.match": match.ID},
+	}})
+	if err != nil {
+		logger.Log.Errorf("Failed to find tournaments for match %s: %v", match.ID.Hex(), err)
+		return
+	}
+
+	for _, result := range results {
+		t := result.(*models.Tournament)
+		if !tournament.RecordResult(t, match) {
+			continue
+		}
+
+		var err error
+		if t.Status == models.TournamentStatusPools && tournament.PoolsFinished(t) {
+			err = seedKnockout(ctx, t)
+		} else if err = syncKnockoutMatches(ctx, t); err == nil {
+			_, err = createFixtureMatches(ctx, t)
+		}
+		if err != nil {
+			logger.Log.Errorf("Failed to advance tournament %s: %v", t.Name, err)
+		}
+
+		if _, err := database.Update(ctx, t); err != nil {
+			logger.Log.Errorf("Failed to update tournament %s: %v", t.Name, err)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/live"
+	"github.com/Tchoukball-Tracker/pkg/logger"
+	middleware "github.com/Tchoukball-Tracker/pkg/middlewares"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// trackingPingInterval is how often a tracker is pinged, which it must
+	// answer within trackingPongWait.
+	trackingPingInterval = 20 * time.Second
+	trackingPongWait     = 30 * time.Second
+	trackingWriteWait    = 10 * time.Second
+	// trackingMessageLimit is the largest message accepted from a tracker, in bytes.
+	trackingMessageLimit = 4096
+)
+
+// In release mode only the page served from the same host may open a session,
+// so other sites cannot use the user's cookie. The development proxy rewrites
+// the host, so any origin is accepted outside release mode.
+var trackingUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin: func(r *http.Request) bool {
+		if gin.Mode() != gin.ReleaseMode {
+			return true
+		}
+		origin, err := url.Parse(r.Header.Get("Origin"))
+		return err == nil && strings.EqualFold(origin.Host, r.Host)
+	},
+}
+
+// trackingSessions holds the trackers connected to each match.
+var trackingSessions = struct {
+	sync.Mutex
+	trackers map[primitive.ObjectID]map[*models.Tracker]bool
+}{trackers: make(map[primitive.ObjectID]map[*models.Tracker]bool)}
+
+// trackMatch opens a tracking session on a match.
+// @Summary Track a match with other trackers
+// @Description open a WebSocket on which a tracker records the actions of one side of the play, attacking or defending, alongside other trackers. The tracker first receives a snapshot of the match, then every live update of the match, including the actions recorded by the others and presence messages listing the connected trackers. A tracker records an action by sending {"type": "action", "ref": "...", "period": "first", "player": "...", "action": {"type": "point", "value": 1}}, answered by an ack with the player's counters or an error, both carrying the same ref. Writes to a spreadsheet are applied one at a time. The user is checked again before each message and the session is closed once they are disabled, removed or no longer allowed to track
+// @Tags matches
+// @Param id path string true "Match ID"
+// @Param role query string true "Tracker role (attacking or defending)"
+// @Success 101 "Switching to the WebSocket protocol"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid role"
+// @Failure 404 {object} models.HTTPError "Match not found"
+// @Router /matches/{id}/track [get]
+func trackMatch(c *gin.Context) {
+	role := c.Query("role")
+	if _, ok := models.TrackerActions[role]; !ok {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: "Please provide a role, attacking or defending"})
+		return
+	}
+
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.Match{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Match not found"})
+		return
+	}
+	match := result.(*models.Match)
+
+	conn, err := trackingUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already replied with the error.
+		return
+	}
+	defer conn.Close()
+
+	tracker := &models.Tracker{Name: middleware.GetClaims(c).Username, Role: role, JoinedAt: time.Now().UTC()}
+	subscription := live.Subscribe(match.ID, "")
+	defer subscription.Cancel()
+
+	snapshot, err := liveMatch(c.Request.Context(), match)
+	if err != nil {
+		logger.Log.Errorf("Failed to read match %s for tracker %s: %v", hexID, tracker.Name, err)
+		return
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		logger.Log.Errorf("Failed to encode snapshot of match %s: %v", hexID, err)
+		return
+	}
+
+	replies := make(chan models.TrackingMessage, 16)
+	done := make(chan struct{})
+	defer close(done)
+	go writeTracking(conn, subscription, replies, done, models.TrackingMessage{Type: models.LiveSnapshot, ID: subscription.LastID, Data: data})
+
+	joinTracking(match.ID, tracker)
+	defer leaveTracking(match.ID, tracker)
+
+	conn.SetReadLimit(trackingMessageLimit)
+	conn.SetReadDeadline(time.Now().Add(trackingPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(trackingPongWait))
+	})
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		// A session can outlast the user's access, so the user is checked
+		// again before each message like the middleware does on each request.
+		if reason := trackingRefusal(c.Request.Context(), tracker.Name); reason != "" {
+			conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason), time.Now().Add(trackingWriteWait))
+			return
+		}
+
+		var reply models.TrackingMessage
+		var request models.TrackingRequest
+		if err := json.Unmarshal(message, &request); err != nil {
+			reply = models.TrackingMessage{Type: models.TrackingError, Message: err.Error()}
+		} else {
+			reply = handleTrackingRequest(c, match, tracker, &request)
+		}
+
+		select {
+		case replies <- reply:
+		case <-done:
+			return
+		}
+	}
+}
+
+// handleTrackingRequest records an action sent by a tracker and returns the
+// reply to send back.
+func handleTrackingRequest(c *gin.Context, match *models.Match, tracker *models.Tracker, request *models.TrackingRequest) models.TrackingMessage {
+	reply := models.TrackingMessage{Type: models.TrackingError, Ref: request.Ref}
+	if request.Type != models.TrackingAction {
+		reply.Message = "Unknown message type, use action"
+		return reply
+	}
+	if !isTrackerAction(tracker.Role, request.Action.Type) {
+		reply.Message = "The " + tracker.Role + " role cannot record " + request.Action.Type + " actions"
+		return reply
+	}
+	spreadsheetID, ok := match.Thirds[request.Period]
+	if !ok {
+		reply.Message = "Period not found"
+		return reply
+	}
+
+	player, httpErr := recordPlayerAction(c.Request.Context(), spreadsheetID, request.Player, request.Action, tracker)
+	if httpErr != nil {
+		reply.Message = httpErr.Message
+		return reply
+	}
+
+	data, err := json.Marshal(player)
+	if err != nil {
+		reply.Message = err.Error()
+		return reply
+	}
+	return models.TrackingMessage{Type: models.TrackingAck, Ref: request.Ref, Data: data}
+}
+
+// trackingRefusal returns why a user may no longer track, or an empty string
+// if they still may.
+func trackingRefusal(ctx context.Context, name string) string {
+	result, err := database.FindByName(ctx, &models.User{}, name)
+	if err != nil || result.(*models.User).Disabled {
+		return "Account disabled or removed"
+	}
+	if role := result.(*models.User).EffectiveRole(); !models.RoleCan(role, models.PermissionTrack) {
+		return "The " + role + " role is not allowed to do this"
+	}
+	return ""
+}
+
+// writeTracking sends the first message, then the live updates of the match
+// and the replies to the tracker, pinging it while idle. It is the only
+// writer of the connection and closes it on any error so that the reading
+// loop ends too.
+func writeTracking(conn *websocket.Conn, subscription *live.Subscription, replies <-chan models.TrackingMessage, done <-chan struct{}, first models.TrackingMessage) {
+	ping := time.NewTicker(trackingPingInterval)
+	defer ping.Stop()
+	defer conn.Close()
+
+	write := func(message models.TrackingMessage) bool {
+		conn.SetWriteDeadline(time.Now().Add(trackingWriteWait))
+		return conn.WriteJSON(message) == nil
+	}
+
+	if !write(first) {
+		return
+	}
+	for {
+		select {
+		case update, ok := <-subscription.Updates:
+			if !ok || !write(models.TrackingMessage{Type: update.Type, ID: update.ID, Data: update.Data}) {
+				return
+			}
+		case reply := <-replies:
+			if !write(reply) {
+				return
+			}
+		case <-ping.C:
+			if conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(trackingWriteWait)) != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+func isTrackerAction(role, actionType string) bool {
+	for _, allowed := range models.TrackerActions[role] {
+		if allowed == actionType {
+			return true
+		}
+	}
+	return false
+}
+
+// joinTracking adds a tracker to the session of a match and tells everyone
+// following the match.
+func joinTracking(match primitive.ObjectID, tracker *models.Tracker) {
+	trackingSessions.Lock()
+	defer trackingSessions.Unlock()
+
+	if trackingSessions.trackers[match] == nil {
+		trackingSessions.trackers[match] = make(map[*models.Tracker]bool)
+	}
+	trackingSessions.trackers[match][tracker] = true
+	publishPresence(match)
+}
+
+// leaveTracking removes a tracker from the session of a match and tells
+// everyone following the match.
+func leaveTracking(match primitive.ObjectID, tracker *models.Tracker) {
+	trackingSessions.Lock()
+	defer trackingSessions.Unlock()
+
+	delete(trackingSessions.trackers[match], tracker)
+	if len(trackingSessions.trackers[match]) == 0 {
+		delete(trackingSessions.trackers, match)
+	}
+	publishPresence(match)
+}
+
+// publishPresence sends the trackers of a match to everyone following it.
+// The caller holds the lock of trackingSessions.
+func publishPresence(match primitive.ObjectID) {
+	presence := models.TrackingPresenceUpdate{Trackers: sessionTrackers(match)}
+	if err := live.Publish(match, models.TrackingPresence, presence); err != nil {
+		logger.Log.Errorf("Failed to publish trackers of match %s: %v", match.Hex(), err)
+	}
+}
+
+// matchTrackers returns the trackers of a match in the order they joined.
+func matchTrackers(match primitive.ObjectID) []*models.Tracker {
+	trackingSessions.Lock()
+	defer trackingSessions.Unlock()
+	return sessionTrackers(match)
+}
+
+func sessionTrackers(match primitive.ObjectID) []*models.Tracker {
+	trackers := []*models.Tracker{}
+	for tracker := range trackingSessions.trackers[match] {
+		trackers = append(trackers, tracker)
+	}
+	sort.Slice(trackers, func(i, j int) bool {
+		return trackers[i].JoinedAt.Before(trackers[j].JoinedAt)
+	})
+	return trackers
+}
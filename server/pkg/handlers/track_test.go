@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+func TestTrackingChecksUserOnEachMessage(t *testing.T) {
+	tests := []struct {
+		name   string
+		revoke func(user *models.User) error
+	}{
+		{name: "disabled", revoke: func(user *models.User) error {
+			user.Disabled = true
+			_, err := database.Update(context.Background(), user)
+			return err
+		}},
+		{name: "role changed", revoke: func(user *models.User) error {
+			user.Role = models.RoleViewer
+			_, err := database.Update(context.Background(), user)
+			return err
+		}},
+		{name: "removed", revoke: func(user *models.User) error {
+			_, err := database.Delete(context.Background(), user)
+			return err
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useMemoryDatabase(t)
+			insertTestUser(t, "tracker", models.RoleTracker)
+			match := &models.Match{Name: "A v B", HomeTeam: "A", AwayTeam: "B"}
+			if _, err := database.Insert(context.Background(), match); err != nil {
+				t.Fatal(err)
+			}
+
+			router := gin.New()
+			RegisterAuthRoutes(router.Group("/auth"))
+			RegisterMatchesRoutes(router.Group("/matches"))
+			server := httptest.NewServer(router)
+			defer server.Close()
+
+			header := http.Header{}
+			for _, cookie := range loginAs(router, "tracker").Result().Cookies() {
+				header.Add("Cookie", cookie.String())
+			}
+			url := "ws" + strings.TrimPrefix(server.URL, "http") + "/matches/" + match.ID.Hex() + "/track?role=" + models.TrackerAttacking
+			conn, _, err := websocket.DefaultDialer.Dial(url, header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			request := models.TrackingRequest{Type: models.TrackingAction, Ref: "1", Period: "first", Player: "Alex", Action: models.PlayerAction{Type: "point", Value: 1}}
+			if err := conn.WriteJSON(request); err != nil {
+				t.Fatal(err)
+			}
+			if reply := readTrackingReply(t, conn, "1"); reply.Type != models.TrackingError {
+				t.Fatalf("reply to an allowed tracker = %+v, want the match's missing period refused", reply)
+			}
+
+			result, err := database.FindByName(context.Background(), &models.User{}, "tracker")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := test.revoke(result.(*models.User)); err != nil {
+				t.Fatal(err)
+			}
+
+			request.Ref = "2"
+			if err := conn.WriteJSON(request); err != nil {
+				t.Fatal(err)
+			}
+			for {
+				var message models.TrackingMessage
+				err := conn.ReadJSON(&message)
+				var closeErr *websocket.CloseError
+				if errors.As(err, &closeErr) {
+					if closeErr.Code != websocket.ClosePolicyViolation {
+						t.Errorf("close code = %d, want %d", closeErr.Code, websocket.ClosePolicyViolation)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("read = %v, want the session closed", err)
+				}
+				if message.Ref == "2" {
+					t.Fatalf("reply once revoked = %+v, want the session closed", message)
+				}
+			}
+		})
+	}
+}
+
+// readTrackingReply reads messages until the reply to a ref, skipping live
+// updates.
+func readTrackingReply(t *testing.T, conn *websocket.Conn, ref string) models.TrackingMessage {
+	for {
+		var message models.TrackingMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			t.Fatal(err)
+		}
+		if message.Ref == ref {
+			return message
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	middleware "github.com/Tchoukball-Tracker/pkg/middlewares"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/stats"
+	"github.com/gin-gonic/gin"
+)
+
+var errUnknownMetric = errors.New("Unknown metric")
+
+// RegisterTrendsRoutes registers trend-related routes in the provided router group.
+func RegisterTrendsRoutes(router *gin.RouterGroup) {
+	router.GET("", middleware.JWTAuthMiddleware(models.PermissionRead), getTrend)
+}
+
+// getTrend computes the form of a player or the team over consecutive matches.
+// @Summary Retrieve a trend
+// @Description compute a metric for a player or the team across consecutive matches with a rolling average, flagging significant changes against their own baseline
+// @Tags trends
+// @Accept  json
+// @Produce  json
+// @Param metric query string true "Counter or metric to follow"
+// @Param player query string false "Player name, the whole team if omitted"
+// @Param team query string false "Only matches this team was tracked in, naming the trend after it when no player is given"
+// @Param window query int false "Number of matches in the rolling average" default(3)
+// @Param threshold query number false "Standard errors from the baseline a rolling average must move to be flagged" default(2)
+// @Param order query string false "Whether higher (desc) or lower (asc) values are better" default(desc)
+// @Param from query string false "Only matches on or after this date (YYYY-MM-DD)"
+// @Param to query string false "Only matches on or before this date (YYYY-MM-DD)"
+// @Param season query string false "Only matches from this season"
+// @Param competition query string false "Only matches from this competition"
+// @Param opponent query string false "Only matches where this team opposed the tracked players"
+// @Success 200 {object} models.Trend "Trend"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid parameters"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /trends [get]
+func getTrend(c *gin.Context) {
+	var filter models.StatsFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	metric := c.Query("metric")
+	if metric == "" {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: "Please provide a metric to follow"})
+		return
+	}
+
+	window, err := strconv.Atoi(c.DefaultQuery("window", "3"))
+	if err != nil || window < 1 {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: "Window must be a positive number of matches"})
+		return
+	}
+
+	threshold, err := strconv.ParseFloat(c.DefaultQuery("threshold", "2"), 64)
+	if err != nil || threshold < 0 {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: "Threshold must be a non-negative number"})
+		return
+	}
+
+	order := c.DefaultQuery("order", "desc")
+	if order != "desc" && order != "asc" {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: "Unknown order, use desc or asc"})
+		return
+	}
+
+	trend, err := computeTrend(c.Request.Context(), filter, c.Query("player"), metric, window, threshold, order == "asc")
+	if err == errUnknownMetric {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error() + ": " + metric})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, trend)
+}
+
+// computeTrend aggregates the per-match counters of the player, or the team
+// if player is empty, and follows the metric across them. The team is the one
+// named by the filter, or every team tracked if it names none.
+func computeTrend(ctx context.Context, filter models.StatsFilter, player, metric string, window int, threshold float64, lowerIsBetter bool) (*models.Trend, error) {
+	custom, err := loadCustomMetrics(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := stats.Resolve(metric, custom)
+	if !ok {
+		return nil, errUnknownMetric
+	}
+
+	subject := "team"
+	if filter.Team != "" {
+		subject = filter.Team
+	}
+	var players []string
+	if player != "" {
+		subject = player
+		players = append(players, player)
+	}
+
+	var rows []*models.MatchTotals
+	if err := database.Aggregate(ctx, &models.Match{}, stats.MatchTotalsPipeline(filter, players...), &rows); err != nil {
+		return nil, err
+	}
+
+	return stats.Trend(subject, metric, rows, value, window, threshold, lowerIsBetter), nil
+}
@@ -0,0 +1,428 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/logger"
+	middleware "github.com/Tchoukball-Tracker/pkg/middlewares"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// setup holds the one-time token that creates the first admin. It is only
+// set while the database has no admin.
+var setup struct {
+	sync.Mutex
+	token string
+}
+
+// RegisterUsersRoutes registers user management routes in the provided router group.
+func RegisterUsersRoutes(router *gin.RouterGroup) {
+	router.GET("", middleware.JWTAuthMiddleware(models.PermissionAdmin), getAllUsers)
+	router.POST("", middleware.JWTAuthMiddleware(models.PermissionAdmin), createUser)
+	router.DELETE("/:id", middleware.JWTAuthMiddleware(models.PermissionAdmin), deleteUser)
+	router.POST("/:id/disable", middleware.JWTAuthMiddleware(models.PermissionAdmin), disableUser)
+	router.POST("/:id/enable", middleware.JWTAuthMiddleware(models.PermissionAdmin), enableUser)
+	router.PUT("/:id/password", middleware.JWTAuthMiddleware(models.PermissionAdmin), resetPassword)
+	router.PUT("/:id/role", middleware.JWTAuthMiddleware(models.PermissionAdmin), setUserRole)
+}
+
+// BootstrapAdmin makes sure the first admin can be created. If there is no
+// admin yet, one is created from ADMIN_USERNAME and ADMIN_PASSWORD when both
+// are set, otherwise a one-time setup token is generated and logged for
+// POST /auth/setup.
+func BootstrapAdmin(ctx context.Context) error {
+	admins, err := database.FindByValue(ctx, &models.User{}, bson.M{"role": models.RoleAdmin})
+	if err != nil {
+		return err
+	}
+	if len(admins) > 0 {
+		return nil
+	}
+
+	name, password := os.Getenv("ADMIN_USERNAME"), os.Getenv("ADMIN_PASSWORD")
+	if name != "" && password != "" {
+		request := &models.UserRequest{Name: name, Password: password, Role: models.RoleAdmin}
+		if _, httpErr := insertUser(ctx, request); httpErr != nil {
+			logger.Log.Errorf("Failed to create admin %s from ADMIN_USERNAME: %s", name, httpErr.Message)
+		} else {
+			logger.Log.Infof("Created admin %s from ADMIN_USERNAME", name)
+			return nil
+		}
+	}
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(random)
+
+	setup.Lock()
+	setup.token = token
+	setup.Unlock()
+	logger.Log.Warnf("No admin exists, create one with POST /auth/setup and the setup token %s", token)
+	return nil
+}
+
+// setupAdmin creates the first admin with the one-time setup token.
+// @Summary Create the first admin
+// @Description create the first admin with the one-time setup token logged by the server when it starts without any admin. The token stops working once it has been used
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param setup body models.SetupRequest true "Setup token and admin details"
+// @Success 201 {object} models.User "Successfully created"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid JSON"
+// @Failure 401 {object} models.HTTPError "Invalid setup token"
+// @Failure 403 {object} models.HTTPError "Setup already done"
+// @Failure 409 {object} models.HTTPError "Name already used"
+// @Failure 422 {object} models.HTTPError "Bad request - missing name or short password"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /auth/setup [post]
+func setupAdmin(c *gin.Context) {
+	var request models.SetupRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	setup.Lock()
+	defer setup.Unlock()
+
+	if setup.token == "" {
+		c.JSON(http.StatusForbidden, models.HTTPError{Code: http.StatusForbidden, Message: "Setup has already been done"})
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(request.Token), []byte(setup.token)) != 1 {
+		c.JSON(http.StatusUnauthorized, models.HTTPError{Code: http.StatusUnauthorized, Message: "Invalid setup token"})
+		return
+	}
+
+	user, httpErr := insertUser(c.Request.Context(), &models.UserRequest{Name: request.Name, Password: request.Password, Role: models.RoleAdmin})
+	if httpErr != nil {
+		c.JSON(httpErr.Code, httpErr)
+		return
+	}
+
+	setup.token = ""
+	logger.Log.Infof("Created admin %s with the setup token", user.Name)
+	c.JSON(http.StatusCreated, user)
+}
+
+// getAllUsers retrieves all users.
+// @Summary Retrieve all users
+// @Description get every user with their role and whether they are disabled, without their password. Admins only
+// @Tags users
+// @Produce json
+// @Success 200 {array} models.User "List of users"
+// @Failure 403 {object} models.HTTPError "Not an admin"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /users [get]
+func getAllUsers(c *gin.Context) {
+	dbUsers, err := database.FindAll(c.Request.Context(), &models.User{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	for _, dbUser := range dbUsers {
+		dbUser.(*models.User).Password = ""
+	}
+	c.JSON(http.StatusOK, dbUsers)
+}
+
+// createUser creates a new user.
+// @Summary Create a user
+// @Description create a user with the given name, password and role, admin, coach, tracker or viewer, which is viewer if not given. Admins only
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param user body models.UserRequest true "User Info"
+// @Success 201 {object} models.User "Successfully created"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid JSON"
+// @Failure 403 {object} models.HTTPError "Not an admin"
+// @Failure 409 {object} models.HTTPError "Name already used"
+// @Failure 422 {object} models.HTTPError "Bad request - missing name, short password or unknown role"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /users [post]
+func createUser(c *gin.Context) {
+	var request models.UserRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	user, httpErr := insertUser(c.Request.Context(), &request)
+	if httpErr != nil {
+		c.JSON(httpErr.Code, httpErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// deleteUser deletes a user by ID.
+// @Summary Delete a user
+// @Description delete a user by ID, who is logged out straight away. The last active admin cannot be deleted. Admins only
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.HTTPError "Successfully deleted"
+// @Failure 403 {object} models.HTTPError "Not an admin"
+// @Failure 404 {object} models.HTTPError "User not found"
+// @Failure 409 {object} models.HTTPError "Last active admin"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /users/{id} [delete]
+func deleteUser(c *gin.Context) {
+	user, ok := findUser(c)
+	if !ok {
+		return
+	}
+	if !keepsAnAdmin(c, user) {
+		return
+	}
+
+	if _, err := database.Delete(c.Request.Context(), user); err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HTTPError{Code: http.StatusOK, Message: "Successfully Deleted"})
+}
+
+// disableUser stops a user from logging in.
+// @Summary Disable a user
+// @Description stop a user from logging in and log them out straight away. The last active admin cannot be disabled. Admins only
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.User "User disabled"
+// @Failure 403 {object} models.HTTPError "Not an admin"
+// @Failure 404 {object} models.HTTPError "User not found"
+// @Failure 409 {object} models.HTTPError "Last active admin"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /users/{id}/disable [post]
+func disableUser(c *gin.Context) {
+	user, ok := findUser(c)
+	if !ok {
+		return
+	}
+	if !keepsAnAdmin(c, user) {
+		return
+	}
+
+	user.Disabled = true
+	updateUser(c, user)
+}
+
+// enableUser lets a disabled user log in again.
+// @Summary Enable a user
+// @Description let a disabled user log in again. Admins only
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.User "User enabled"
+// @Failure 403 {object} models.HTTPError "Not an admin"
+// @Failure 404 {object} models.HTTPError "User not found"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /users/{id}/enable [post]
+func enableUser(c *gin.Context) {
+	user, ok := findUser(c)
+	if !ok {
+		return
+	}
+
+	user.Disabled = false
+	updateUser(c, user)
+}
+
+// resetPassword sets a new password for a user.
+// @Summary Reset the password of a user
+// @Description set a new password for a user. Admins only
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param password body models.PasswordRequest true "New password"
+// @Success 200 {object} models.User "Password reset"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid JSON"
+// @Failure 403 {object} models.HTTPError "Not an admin"
+// @Failure 404 {object} models.HTTPError "User not found"
+// @Failure 422 {object} models.HTTPError "Bad request - short password"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /users/{id}/password [put]
+func resetPassword(c *gin.Context) {
+	var request models.PasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+	if message := validatePassword(request.Password); message != "" {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: message})
+		return
+	}
+
+	user, ok := findUser(c)
+	if !ok {
+		return
+	}
+
+	hash, err := utils.HashPassword(request.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	user.Password = hash
+	updateUser(c, user)
+}
+
+// setUserRole changes the role of a user.
+// @Summary Change the role of a user
+// @Description give a user the admin, coach, tracker or viewer role, which applies to their next request. The last active admin cannot lose the admin role. Admins only
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param role body models.RoleRequest true "New role"
+// @Success 200 {object} models.User "Role changed"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid JSON"
+// @Failure 403 {object} models.HTTPError "Not an admin"
+// @Failure 404 {object} models.HTTPError "User not found"
+// @Failure 409 {object} models.HTTPError "Last active admin"
+// @Failure 422 {object} models.HTTPError "Bad request - unknown role"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /users/{id}/role [put]
+func setUserRole(c *gin.Context) {
+	var request models.RoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+	if !isRole(request.Role) {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: "Unknown role " + request.Role + ", use " + strings.Join(models.Roles, ", ")})
+		return
+	}
+
+	user, ok := findUser(c)
+	if !ok {
+		return
+	}
+	if request.Role != models.RoleAdmin && !keepsAnAdmin(c, user) {
+		return
+	}
+
+	user.Role = request.Role
+	updateUser(c, user)
+}
+
+// insertUser validates and creates a user, hashing their password.
+func insertUser(ctx context.Context, request *models.UserRequest) (*models.User, *models.HTTPError) {
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Role == "" {
+		request.Role = models.RoleViewer
+	}
+	if message := validateUserRequest(request); message != "" {
+		return nil, &models.HTTPError{Code: http.StatusUnprocessableEntity, Message: message}
+	}
+
+	if _, err := database.FindByName(ctx, &models.User{}, request.Name); err == nil {
+		return nil, &models.HTTPError{Code: http.StatusConflict, Message: "A user with this name already exists"}
+	}
+
+	hash, err := utils.HashPassword(request.Password)
+	if err != nil {
+		return nil, &models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()}
+	}
+
+	user := &models.User{Name: request.Name, Password: hash, Role: request.Role, CreatedAt: time.Now().UTC()}
+	if _, err := database.Insert(ctx, user); err != nil {
+		return nil, &models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()}
+	}
+
+	user.Password = ""
+	return user, nil
+}
+
+// validateUserRequest returns why a user cannot be created, or an empty string.
+func validateUserRequest(request *models.UserRequest) string {
+	if request.Name == "" {
+		return "Please provide a name"
+	}
+	if message := validatePassword(request.Password); message != "" {
+		return message
+	}
+	if !isRole(request.Role) {
+		return "Unknown role " + request.Role + ", use " + strings.Join(models.Roles, ", ")
+	}
+	return ""
+}
+
+func validatePassword(password string) string {
+	if len(password) < models.MinPasswordLength {
+		return fmt.Sprintf("Please provide a password of at least %d characters", models.MinPasswordLength)
+	}
+	return ""
+}
+
+func isRole(role string) bool {
+	for _, known := range models.Roles {
+		if known == role {
+			return true
+		}
+	}
+	return false
+}
+
+// findUser fetches the user in the id path parameter, replying with an error
+// if there is none.
+func findUser(c *gin.Context) (*models.User, bool) {
+	result, err := database.Find(c.Request.Context(), &models.User{ID: utils.ConvertToMongoID(c.Param("id"))})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "User not found"})
+		return nil, false
+	}
+	return result.(*models.User), true
+}
+
+// keepsAnAdmin reports whether another active admin remains if the user is
+// disabled, deleted or given another role, replying with an error if not.
+func keepsAnAdmin(c *gin.Context, user *models.User) bool {
+	if user.Role != models.RoleAdmin || user.Disabled {
+		return true
+	}
+
+	admins, err := database.FindByValue(c.Request.Context(), &models.User{}, bson.M{"role": models.RoleAdmin, "disabled": bson.M{"$ne": true}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return false
+	}
+	if len(admins) <= 1 {
+		c.JSON(http.StatusConflict, models.HTTPError{Code: http.StatusConflict, Message: "The last active admin cannot be disabled, deleted or given another role"})
+		return false
+	}
+	return true
+}
+
+// updateUser saves a user and replies with it.
+func updateUser(c *gin.Context, user *models.User) {
+	if _, err := database.Update(c.Request.Context(), user); err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	user.Password = ""
+	c.JSON(http.StatusOK, user)
+}
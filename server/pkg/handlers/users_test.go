@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryDatabase keeps documents in memory. Updates $set the encoded entity
+// over the stored document like MongoDB does, so fields left out when
+// encoding keep their stored value.
+type memoryDatabase struct {
+	models.Database
+	mu          sync.Mutex
+	collections map[string]map[primitive.ObjectID]bson.M
+}
+
+func newMemoryDatabase() *memoryDatabase {
+	return &memoryDatabase{collections: map[string]map[primitive.ObjectID]bson.M{}}
+}
+
+func (db *memoryDatabase) encode(entity models.DatabaseEntity) (bson.M, error) {
+	data, err := bson.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	var document bson.M
+	return document, bson.Unmarshal(data, &document)
+}
+
+func (db *memoryDatabase) decode(document bson.M, entity models.DatabaseEntity) error {
+	data, err := bson.Marshal(document)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(data, entity)
+}
+
+func (db *memoryDatabase) Insert(ctx context.Context, entity models.DatabaseEntity) (models.DatabaseEntity, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if entity.GetID().IsZero() {
+		entity.SetID(primitive.NewObjectID())
+	}
+	document, err := db.encode(entity)
+	if err != nil {
+		return entity, err
+	}
+	if db.collections[entity.CollectionName()] == nil {
+		db.collections[entity.CollectionName()] = map[primitive.ObjectID]bson.M{}
+	}
+	db.collections[entity.CollectionName()][entity.GetID()] = document
+	return entity, nil
+}
+
+func (db *memoryDatabase) Find(ctx context.Context, entity models.DatabaseEntity) (models.DatabaseEntity, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	document, ok := db.collections[entity.CollectionName()][entity.GetID()]
+	if !ok {
+		return entity, mongo.ErrNoDocuments
+	}
+	return entity, db.decode(document, entity)
+}
+
+func (db *memoryDatabase) FindByName(ctx context.Context, entity models.DatabaseEntity, name string) (models.DatabaseEntity, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, document := range db.collections[entity.CollectionName()] {
+		if document["name"] == name {
+			return entity, db.decode(document, entity)
+		}
+	}
+	return nil, errors.New("Failed to find result with this name")
+}
+
+func (db *memoryDatabase) FindAll(ctx context.Context, entity models.DatabaseEntity) ([]models.DatabaseEntity, error) {
+	return db.FindByValue(ctx, entity, bson.M{})
+}
+
+// FindByValue supports filters on equal values and $in of IDs. Documents
+// never match other operators.
+func (db *memoryDatabase) FindByValue(ctx context.Context, entity models.DatabaseEntity, filter bson.M) ([]models.DatabaseEntity, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var results []models.DatabaseEntity
+	for _, document := range db.collections[entity.CollectionName()] {
+		if !matchesFilter(document, filter) {
+			continue
+		}
+		result := entity.New()
+		if err := db.decode(document, result); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func matchesFilter(document bson.M, filter bson.M) bool {
+	for key, want := range filter {
+		if condition, ok := want.(bson.M); ok {
+			in, _ := condition["$in"].([]primitive.ObjectID)
+			found := false
+			for _, value := range in {
+				found = found || document[key] == value
+			}
+			if !found {
+				return false
+			}
+		} else if document[key] != want {
+			return false
+		}
+	}
+	return true
+}
+
+func (db *memoryDatabase) Update(ctx context.Context, entity models.DatabaseEntity) (*mongo.UpdateResult, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	stored, ok := db.collections[entity.CollectionName()][entity.GetID()]
+	if !ok {
+		return &mongo.UpdateResult{}, nil
+	}
+	document, err := db.encode(entity)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range document {
+		stored[key] = value
+	}
+	return &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil
+}
+
+func (db *memoryDatabase) Delete(ctx context.Context, entity models.DatabaseEntity) (*mongo.DeleteResult, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if _, ok := db.collections[entity.CollectionName()][entity.GetID()]; !ok {
+		return &mongo.DeleteResult{}, nil
+	}
+	delete(db.collections[entity.CollectionName()], entity.GetID())
+	return &mongo.DeleteResult{DeletedCount: 1}, nil
+}
+
+// useMemoryDatabase runs the handlers against an empty memoryDatabase.
+func useMemoryDatabase(t *testing.T) {
+	t.Setenv("JWT_SECRET_KEY", "test-secret")
+	gin.SetMode(gin.TestMode)
+	database.Use(newMemoryDatabase())
+}
+
+// insertTestUser stores a user whose password is password1, returning its ID.
+func insertTestUser(t *testing.T, name, role string) string {
+	hash, err := utils.HashPassword("password1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := database.Insert(context.Background(), &models.User{Name: name, Password: hash, Role: role})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user.GetID().Hex()
+}
+
+// loginAs logs a user in with the password given by insertTestUser.
+func loginAs(router *gin.Engine, name string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(LoginRequest{Username: name, Password: "password1"})
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(body)))
+	return recorder
+}
+
+// serveAs logs a user in and makes a request with their cookie, returning
+// the response status code.
+func serveAs(t *testing.T, router *gin.Engine, name, method, path string) int {
+	return serveJSONAs(t, router, name, method, path, nil).Code
+}
+
+// serveJSONAs logs a user in and makes a request with their cookie and the
+// body encoded as JSON, if any.
+func serveJSONAs(t *testing.T, router *gin.Engine, name, method, path string, body interface{}) *httptest.ResponseRecorder {
+	response := loginAs(router, name)
+	if response.Code != http.StatusOK {
+		t.Fatalf("login as %s = %d, want %d", name, response.Code, http.StatusOK)
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	request := httptest.NewRequest(method, path, reader)
+	for _, cookie := range response.Result().Cookies() {
+		request.AddCookie(cookie)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestEnableDisabledUser(t *testing.T) {
+	useMemoryDatabase(t)
+	insertTestUser(t, "admin", models.RoleAdmin)
+	coachID := insertTestUser(t, "coach", models.RoleCoach)
+
+	router := gin.New()
+	RegisterAuthRoutes(router.Group("/auth"))
+	RegisterUsersRoutes(router.Group("/users"))
+
+	if code := serveAs(t, router, "admin", http.MethodPost, "/users/"+coachID+"/disable"); code != http.StatusOK {
+		t.Fatalf("disable = %d, want %d", code, http.StatusOK)
+	}
+	if code := loginAs(router, "coach").Code; code != http.StatusForbidden {
+		t.Fatalf("login while disabled = %d, want %d", code, http.StatusForbidden)
+	}
+
+	if code := serveAs(t, router, "admin", http.MethodPost, "/users/"+coachID+"/enable"); code != http.StatusOK {
+		t.Fatalf("enable = %d, want %d", code, http.StatusOK)
+	}
+	if code := loginAs(router, "coach").Code; code != http.StatusOK {
+		t.Fatalf("login once enabled = %d, want %d", code, http.StatusOK)
+	}
+}
@@ -0,0 +1,446 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/logger"
+	middleware "github.com/Tchoukball-Tracker/pkg/middlewares"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/utils"
+	"github.com/Tchoukball-Tracker/pkg/webhook"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// RegisterWebhooksRoutes registers webhook-related routes in the provided router group.
+func RegisterWebhooksRoutes(router *gin.RouterGroup) {
+	router.GET("", middleware.JWTAuthMiddleware(models.PermissionManage), getAllWebhooks)
+	router.POST("", middleware.JWTAuthMiddleware(models.PermissionManage), createWebhook)
+	router.GET("/:id", middleware.JWTAuthMiddleware(models.PermissionManage), getWebhookByID)
+	router.PUT("/:id", middleware.JWTAuthMiddleware(models.PermissionManage), updateWebhook)
+	router.DELETE("/:id", middleware.JWTAuthMiddleware(models.PermissionAdmin), deleteWebhook)
+	router.GET("/:id/deliveries", middleware.JWTAuthMiddleware(models.PermissionManage), getWebhookDeliveries)
+	router.POST("/:id/deliveries/:delivery/replay", middleware.JWTAuthMiddleware(models.PermissionManage), replayWebhookDelivery)
+}
+
+// getAllWebhooks retrieves all webhooks.
+// @Summary Retrieve all webhooks
+// @Description get all registered webhooks from the database
+// @Tags webhooks
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} models.Webhook "List of webhooks"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /webhooks [get]
+func getAllWebhooks(c *gin.Context) {
+	dbWebhooks, err := database.FindAll(c.Request.Context(), &models.Webhook{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dbWebhooks)
+}
+
+// createWebhook registers a new webhook.
+// @Summary Register a webhook
+// @Description register a URL to receive a signed JSON POST for each of the given events, or for every event if none are given. A signing secret is generated if none is given, and is only returned in this response. The URL must not resolve to a loopback, private or link-local address, though loopback and private addresses are allowed when WEBHOOK_ALLOW_PRIVATE is true.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body models.WebhookWithSecret true "Webhook Info"
+// @Success 201 {object} models.WebhookWithSecret "Successfully created, with the secret which is not returned again"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid JSON"
+// @Failure 422 {object} models.HTTPError "Bad request - missing name, invalid or internal URL or unknown event"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /webhooks [post]
+func createWebhook(c *gin.Context) {
+	request := models.WebhookWithSecret{Webhook: &models.Webhook{}}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+	newWebhook := request.Webhook
+	newWebhook.Secret = request.Secret
+
+	if message := validateWebhook(c.Request.Context(), newWebhook); message != "" {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: message})
+		return
+	}
+
+	if newWebhook.Secret == "" {
+		secret, err := webhook.NewSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+			return
+		}
+		newWebhook.Secret = secret
+	}
+
+	newWebhook.CreatedAt = time.Now().UTC()
+	if _, err := database.Insert(c.Request.Context(), newWebhook); err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.WebhookWithSecret{Webhook: newWebhook, Secret: newWebhook.Secret})
+}
+
+// getWebhookByID retrieves a webhook by ID.
+// @Summary Retrieve a webhook by ID
+// @Description get webhook by ID from the database
+// @Tags webhooks
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} models.Webhook "Webhook retrieved"
+// @Failure 404 {object} models.HTTPError "Webhook not found"
+// @Router /webhooks/{id} [get]
+func getWebhookByID(c *gin.Context) {
+	hexID := c.Param("id")
+	dbWebhook, err := database.Find(c.Request.Context(), &models.Webhook{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Webhook not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dbWebhook)
+}
+
+// updateWebhook updates a webhook by ID.
+// @Summary Update a webhook
+// @Description update the name, URL, secret, events or disabled flag of a webhook by ID. Missing fields keep their value. Retries of pending deliveries stop once the webhook is disabled.
+// @Tags webhooks
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Webhook ID"
+// @Param webhook body models.WebhookWithSecret true "Webhook info"
+// @Success 200 {object} models.Webhook "Webhook updated"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid JSON"
+// @Failure 404 {object} models.HTTPError "Webhook not found"
+// @Failure 422 {object} models.HTTPError "Bad request - invalid or internal URL or unknown event"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /webhooks/{id} [put]
+func updateWebhook(c *gin.Context) {
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.Webhook{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Webhook not found"})
+		return
+	}
+	fetchedWebhook := result.(*models.Webhook)
+
+	// Disabled keeps its stored value unless the request sets it, as false
+	// cannot be told apart from a missing field once decoded.
+	request := models.WebhookWithSecret{Webhook: &models.Webhook{Disabled: fetchedWebhook.Disabled}}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+	updatedWebhook := request.Webhook
+	updatedWebhook.Secret = request.Secret
+
+	if updatedWebhook.Name == "" {
+		updatedWebhook.Name = fetchedWebhook.Name
+	}
+	if updatedWebhook.URL == "" {
+		updatedWebhook.URL = fetchedWebhook.URL
+	}
+	if updatedWebhook.Secret == "" {
+		updatedWebhook.Secret = fetchedWebhook.Secret
+	}
+	if updatedWebhook.Events == nil {
+		updatedWebhook.Events = fetchedWebhook.Events
+	}
+	updatedWebhook.ID = fetchedWebhook.ID
+	updatedWebhook.CreatedAt = fetchedWebhook.CreatedAt
+
+	if message := validateWebhook(c.Request.Context(), updatedWebhook); message != "" {
+		c.JSON(http.StatusUnprocessableEntity, models.HTTPError{Code: http.StatusUnprocessableEntity, Message: message})
+		return
+	}
+
+	if _, err := database.Update(c.Request.Context(), updatedWebhook); err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedWebhook)
+}
+
+// deleteWebhook deletes a webhook by ID.
+// @Summary Delete a webhook
+// @Description delete a webhook by ID so that it receives no new events and retries of its pending deliveries stop
+// @Tags webhooks
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Webhook ID"
+// @Success 200 {string} string "Successfully deleted"
+// @Failure 404 {object} models.HTTPError "Webhook not found"
+// @Router /webhooks/{id} [delete]
+func deleteWebhook(c *gin.Context) {
+	hexID := c.Param("id")
+	result, err := database.Delete(c.Request.Context(), &models.Webhook{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Webhook not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HTTPError{Code: http.StatusOK, Message: "Successfully Deleted"})
+}
+
+// getWebhookDeliveries retrieves the delivery log of a webhook.
+// @Summary Retrieve the delivery log of a webhook
+// @Description get the most recent deliveries to a webhook, newest first, with the payload and every attempt made
+// @Tags webhooks
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Webhook ID"
+// @Param status query string false "Only deliveries with this status (pending, delivered or failed)"
+// @Param limit query int false "Number of deliveries (default 50, at most 500)"
+// @Success 200 {array} models.WebhookDelivery "Delivery log"
+// @Failure 400 {object} models.HTTPError "Bad request - invalid limit"
+// @Failure 404 {object} models.HTTPError "Webhook not found"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /webhooks/{id}/deliveries [get]
+func getWebhookDeliveries(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, models.HTTPError{Code: http.StatusBadRequest, Message: "Limit must be between 1 and 500"})
+		return
+	}
+
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.Webhook{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Webhook not found"})
+		return
+	}
+
+	filter := bson.M{"webhook": result.GetID()}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+	results, err := database.FindByValue(c.Request.Context(), &models.WebhookDelivery{}, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	deliveries := make([]*models.WebhookDelivery, 0, len(results))
+	for _, result := range results {
+		deliveries = append(deliveries, result.(*models.WebhookDelivery))
+	}
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// replayWebhookDelivery sends a past delivery again.
+// @Summary Replay a webhook delivery
+// @Description send the payload of a past delivery to the webhook again as a new delivery, retried like any other
+// @Tags webhooks
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Webhook ID"
+// @Param delivery path string true "Delivery ID"
+// @Success 202 {object} models.WebhookDelivery "Replay queued"
+// @Failure 404 {object} models.HTTPError "Webhook or delivery not found"
+// @Failure 500 {object} models.HTTPError "Internal server error"
+// @Router /webhooks/{id}/deliveries/{delivery}/replay [post]
+func replayWebhookDelivery(c *gin.Context) {
+	hexID := c.Param("id")
+	result, err := database.Find(c.Request.Context(), &models.Webhook{ID: utils.ConvertToMongoID(hexID)})
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Webhook not found"})
+		return
+	}
+	hook := result.(*models.Webhook)
+
+	result, err = database.Find(c.Request.Context(), &models.WebhookDelivery{ID: utils.ConvertToMongoID(c.Param("delivery"))})
+	if err != nil || result.(*models.WebhookDelivery).Webhook != hook.ID {
+		c.JSON(http.StatusNotFound, models.HTTPError{Code: http.StatusNotFound, Message: "Delivery not found"})
+		return
+	}
+	original := result.(*models.WebhookDelivery)
+
+	replay := &models.WebhookDelivery{
+		Webhook:   hook.ID,
+		Event:     original.Event,
+		Payload:   original.Payload,
+		Status:    models.DeliveryPending,
+		Attempts:  []*models.DeliveryAttempt{},
+		ReplayOf:  &original.ID,
+		CreatedAt: time.Now().UTC(),
+	}
+	if _, err := database.Insert(c.Request.Context(), replay); err != nil {
+		c.JSON(http.StatusInternalServerError, models.HTTPError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, replay)
+	go deliverWebhook(hook, replay)
+}
+
+// validateWebhook checks the name, URL and events of a webhook, returning a
+// message describing the first problem found. The URL must only resolve to
+// public addresses so webhooks cannot be used to reach internal services.
+func validateWebhook(ctx context.Context, hook *models.Webhook) string {
+	if hook.Name == "" {
+		return "Please provide a name for the Webhook"
+	}
+
+	if err := webhook.CheckURL(ctx, hook.URL); err != nil {
+		return err.Error()
+	}
+
+	for _, event := range hook.Events {
+		known := false
+		for _, webhookEvent := range models.WebhookEvents {
+			known = known || event == webhookEvent
+		}
+		if !known {
+			return "Unknown webhook event: " + event
+		}
+	}
+	return ""
+}
+
+// dispatchWebhooks delivers an event to every webhook subscribed to it. The
+// deliveries are made in the background so the request that caused the
+// event is not held up by slow or failing receivers.
+func dispatchWebhooks(event string, match *models.Match, entry *models.Event) {
+	payload, err := json.Marshal(models.WebhookPayload{
+		Event:     event,
+		CreatedAt: time.Now().UTC(),
+		Match:     match,
+		Entry:     entry,
+	})
+	if err != nil {
+		logger.Log.Errorf("Failed to encode %s webhook: %v", event, err)
+		return
+	}
+
+	go func() {
+		ctx := context.Background()
+		results, err := database.FindAll(ctx, &models.Webhook{})
+		if err != nil {
+			logger.Log.Errorf("Failed to find webhooks for %s: %v", event, err)
+			return
+		}
+
+		for _, result := range results {
+			hook := result.(*models.Webhook)
+			if !hook.Subscribes(event) {
+				continue
+			}
+
+			delivery := &models.WebhookDelivery{
+				Webhook:   hook.ID,
+				Event:     event,
+				Payload:   payload,
+				Status:    models.DeliveryPending,
+				Attempts:  []*models.DeliveryAttempt{},
+				CreatedAt: time.Now().UTC(),
+			}
+			if _, err := database.Insert(ctx, delivery); err != nil {
+				logger.Log.Errorf("Failed to log %s delivery to webhook %s: %v", event, hook.Name, err)
+				continue
+			}
+			go deliverWebhook(hook, delivery)
+		}
+	}()
+}
+
+// ResumeWebhookDeliveries carries on with the deliveries left pending when the
+// server last stopped, so that retries waiting in the background are not lost.
+// Deliveries to webhooks that were deleted since are marked failed.
+func ResumeWebhookDeliveries(ctx context.Context) error {
+	results, err := database.FindByValue(ctx, &models.WebhookDelivery{}, bson.M{"status": models.DeliveryPending})
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		delivery := result.(*models.WebhookDelivery)
+		hook, err := database.Find(ctx, &models.Webhook{ID: delivery.Webhook})
+		if err != nil {
+			delivery.Status = models.DeliveryFailed
+			if _, err := database.Update(ctx, delivery); err != nil {
+				return err
+			}
+			continue
+		}
+		go deliverWebhook(hook.(*models.Webhook), delivery)
+	}
+
+	if len(results) > 0 {
+		logger.Log.Infof("Resuming %d pending webhook deliveries", len(results))
+	}
+	return nil
+}
+
+// deliverWebhook sends a delivery until it is accepted or it has been tried
+// webhook.MaxAttempts times, waiting longer after each failure and recording
+// every attempt in the delivery log. Attempts already recorded count towards
+// the limit, so a resumed delivery carries on where it stopped. The webhook
+// is read again before each attempt so that changes made while waiting apply,
+// and the delivery is marked failed once the webhook is deleted or disabled.
+func deliverWebhook(hook *models.Webhook, delivery *models.WebhookDelivery) {
+	ctx := context.Background()
+	for attempt := len(delivery.Attempts) + 1; attempt <= webhook.MaxAttempts; attempt++ {
+		result, err := database.Find(ctx, &models.Webhook{ID: hook.ID})
+		if err != nil || result.(*models.Webhook).Disabled {
+			logger.Log.Infof("Stopping %s delivery to webhook %s, which was deleted or disabled", delivery.Event, hook.Name)
+			break
+		}
+		hook = result.(*models.Webhook)
+
+		start := time.Now()
+		status, err := webhook.Send(ctx, hook.URL, hook.Secret, delivery.Event, delivery.ID.Hex(), delivery.Payload)
+
+		record := &models.DeliveryAttempt{At: start.UTC(), StatusCode: status, Duration: time.Since(start).Milliseconds()}
+		if err != nil {
+			record.Error = err.Error()
+		}
+		delivery.Attempts = append(delivery.Attempts, record)
+
+		switch {
+		case err == nil:
+			delivery.Status = models.DeliveryDelivered
+		case attempt == webhook.MaxAttempts:
+			delivery.Status = models.DeliveryFailed
+			logger.Log.Warnf("Giving up %s delivery to webhook %s: %v", delivery.Event, hook.Name, err)
+		}
+
+		if _, err := database.Update(ctx, delivery); err != nil {
+			logger.Log.Errorf("Failed to record %s delivery to webhook %s: %v", delivery.Event, hook.Name, err)
+		}
+		if delivery.Status != models.DeliveryPending {
+			return
+		}
+		time.Sleep(webhook.Backoff(attempt))
+	}
+
+	// Only reached once the webhook is gone or by a resumed delivery that had
+	// no attempts left
+	delivery.Status = models.DeliveryFailed
+	if _, err := database.Update(ctx, delivery); err != nil {
+		logger.Log.Errorf("Failed to record %s delivery to webhook %s: %v", delivery.Event, hook.Name, err)
+	}
+}
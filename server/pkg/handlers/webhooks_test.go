@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/webhook"
+	"github.com/gin-gonic/gin"
+)
+
+func TestDeliverWebhookRetries(t *testing.T) {
+	useMemoryDatabase(t)
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE", "true")
+	defer func(delay time.Duration) { webhook.BaseDelay = delay }(webhook.BaseDelay)
+	webhook.BaseDelay = time.Millisecond
+
+	// Fail twice, then accept the delivery if it is signed with the secret
+	requests := 0
+	payload := []byte(`{"event":"match.created"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		timestamp, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		switch {
+		case requests <= 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.Header.Get(webhook.HeaderSignature) != webhook.Sign("secret", timestamp, payload):
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	hook := &models.Webhook{Name: "scoreboard", URL: server.URL, Secret: "secret"}
+	if _, err := database.Insert(ctx, hook); err != nil {
+		t.Fatal(err)
+	}
+	delivery := &models.WebhookDelivery{Webhook: hook.ID, Event: models.WebhookMatchCreated, Payload: payload, Status: models.DeliveryPending, Attempts: []*models.DeliveryAttempt{}}
+	if _, err := database.Insert(ctx, delivery); err != nil {
+		t.Fatal(err)
+	}
+
+	deliverWebhook(hook, delivery)
+
+	result, err := database.Find(ctx, &models.WebhookDelivery{ID: delivery.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logged := result.(*models.WebhookDelivery)
+	if logged.Status != models.DeliveryDelivered {
+		t.Errorf("status = %s, want %s", logged.Status, models.DeliveryDelivered)
+	}
+
+	want := []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK}
+	if len(logged.Attempts) != len(want) {
+		t.Fatalf("%d attempts logged, want %d", len(logged.Attempts), len(want))
+	}
+	for i, attempt := range logged.Attempts {
+		if attempt.StatusCode != want[i] || (attempt.Error == "") != (want[i] == http.StatusOK) {
+			t.Errorf("attempt %d = %d %q, want %d", i+1, attempt.StatusCode, attempt.Error, want[i])
+		}
+		if i > 0 && attempt.At.Sub(logged.Attempts[i-1].At) < webhook.Backoff(i) {
+			t.Errorf("attempt %d made %v after the previous one, want at least %v", i+1, attempt.At.Sub(logged.Attempts[i-1].At), webhook.Backoff(i))
+		}
+	}
+}
+
+func TestDeliverWebhookStopsOnceRevoked(t *testing.T) {
+	tests := []struct {
+		name   string
+		revoke func(hook *models.Webhook)
+	}{
+		{name: "disabled", revoke: func(hook *models.Webhook) {
+			hook.Disabled = true
+			database.Update(context.Background(), hook)
+		}},
+		{name: "deleted", revoke: func(hook *models.Webhook) {
+			database.Delete(context.Background(), hook)
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useMemoryDatabase(t)
+			t.Setenv("WEBHOOK_ALLOW_PRIVATE", "true")
+			defer func(delay time.Duration) { webhook.BaseDelay = delay }(webhook.BaseDelay)
+			webhook.BaseDelay = time.Millisecond
+
+			// Fail the first attempt and revoke the webhook meanwhile
+			ctx := context.Background()
+			hook := &models.Webhook{Name: "scoreboard", Secret: "secret"}
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				test.revoke(&models.Webhook{ID: hook.ID, Name: hook.Name, URL: hook.URL, Secret: hook.Secret})
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			hook.URL = server.URL
+			if _, err := database.Insert(ctx, hook); err != nil {
+				t.Fatal(err)
+			}
+			delivery := &models.WebhookDelivery{Webhook: hook.ID, Event: models.WebhookMatchCreated, Payload: []byte(`{}`), Status: models.DeliveryPending, Attempts: []*models.DeliveryAttempt{}}
+			if _, err := database.Insert(ctx, delivery); err != nil {
+				t.Fatal(err)
+			}
+
+			deliverWebhook(hook, delivery)
+
+			result, err := database.Find(ctx, &models.WebhookDelivery{ID: delivery.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			logged := result.(*models.WebhookDelivery)
+			if requests != 1 || len(logged.Attempts) != 1 {
+				t.Errorf("%d requests and %d attempts logged, want 1", requests, len(logged.Attempts))
+			}
+			if logged.Status != models.DeliveryFailed {
+				t.Errorf("status = %s, want %s", logged.Status, models.DeliveryFailed)
+			}
+		})
+	}
+}
+
+func TestUpdateWebhookKeepsDisabled(t *testing.T) {
+	useMemoryDatabase(t)
+	insertTestUser(t, "coach", models.RoleCoach)
+
+	ctx := context.Background()
+	hook := &models.Webhook{Name: "scoreboard", URL: "https://203.0.113.7/hook", Secret: "secret", Disabled: true}
+	if _, err := database.Insert(ctx, hook); err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	RegisterAuthRoutes(router.Group("/auth"))
+	RegisterWebhooksRoutes(router.Group("/webhooks"))
+	path := "/webhooks/" + hook.ID.Hex()
+
+	tests := []struct {
+		body map[string]interface{}
+		want bool
+	}{
+		{body: map[string]interface{}{"name": "renamed"}, want: true},
+		{body: map[string]interface{}{"disabled": false}, want: false},
+		{body: map[string]interface{}{"name": "scoreboard"}, want: false},
+		{body: map[string]interface{}{"disabled": true}, want: true},
+	}
+
+	for _, test := range tests {
+		if response := serveJSONAs(t, router, "coach", http.MethodPut, path, test.body); response.Code != http.StatusOK {
+			t.Fatalf("update %v = %d, want %d: %s", test.body, response.Code, http.StatusOK, response.Body)
+		}
+		result, err := database.Find(ctx, &models.Webhook{ID: hook.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if disabled := result.(*models.Webhook).Disabled; disabled != test.want {
+			t.Errorf("disabled = %t after update %v, want %t", disabled, test.body, test.want)
+		}
+	}
+}
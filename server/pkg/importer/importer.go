@@ -0,0 +1,296 @@
+// Package importer reads player counters from the rows of an uploaded sheet,
+// matching its headers to counters through aliases.
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Tchoukball-Tracker/pkg/models"
+)
+
+// NameColumn is the alias key of the column holding player names.
+const NameColumn = "name"
+
+// headerRows is how many rows are searched for the header, so that titles
+// and grouped headers above it are skipped.
+const headerRows = 5
+
+// DefaultAliases are the headers recognised for the player name and each
+// counter besides the counter name itself. Headers are compared ignoring
+// case, spaces and punctuation.
+var DefaultAliases = map[string][]string{
+	NameColumn: {"player", "player name", "name"},
+	"point":    {"points", "pts", "goal", "goals"},
+	"caught":   {"catch", "catches"},
+	"short":    {"shorts"},
+	"frame":    {"frames"},
+	"footing":  {"footings", "foot fault", "foot faults"},
+	"landed":   {"landing", "landings"},
+	"badPass":  {"bad passes"},
+	"dropPass": {"dropped pass", "dropped passes", "drop passes"},
+	"first":    {"first line", "1st line"},
+	"second":   {"second line", "2nd line"},
+	"drop":     {"drops"},
+	"gap":      {"gaps"},
+	"dig":      {"digs"},
+}
+
+// totalNames are player names of summary rows, such as those of exported
+// workbooks, which are skipped rather than imported.
+var totalNames = []string{"total", "totals"}
+
+// Keys returns the name column and every counter, the keys aliases can be given for.
+func Keys() []string {
+	keys := []string{NameColumn}
+	keys = append(keys, models.AttackingCounters...)
+	return append(keys, models.DefendingCounters...)
+}
+
+// MergeAliases adds extra aliases to the defaults, reporting an unknown key.
+func MergeAliases(extra map[string][]string) (map[string][]string, error) {
+	aliases := make(map[string][]string, len(DefaultAliases))
+	for key, values := range DefaultAliases {
+		aliases[key] = append([]string{}, values...)
+	}
+
+	for key, values := range extra {
+		if _, ok := aliases[key]; !ok {
+			return nil, fmt.Errorf("Unknown alias key %q, use name or a counter name", key)
+		}
+		aliases[key] = append(aliases[key], values...)
+	}
+	return aliases, nil
+}
+
+// ReadCSV returns the rows of a CSV file, detecting whether its fields are
+// separated by commas or semicolons.
+func ReadCSV(r io.Reader) ([][]string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	firstLine, _, _ := bytes.Cut(content, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("Invalid CSV file: %w", err)
+	}
+	return rows, nil
+}
+
+// Parse builds players from the rows of a sheet. The header is the first row
+// naming the player column and at least one counter. Rows with a problem are
+// left out of the players and reported in the errors.
+func Parse(rows [][]string, aliases map[string][]string) ([]*models.Player, *models.ImportReport) {
+	report := &models.ImportReport{Columns: map[string]string{}, Errors: []models.ImportError{}}
+
+	lookup, err := aliasLookup(aliases)
+	if err != nil {
+		report.Errors = append(report.Errors, models.ImportError{Message: err.Error()})
+		return nil, report
+	}
+
+	headerRow, header, columns := findHeader(rows, lookup)
+	if headerRow < 0 {
+		report.Errors = append(report.Errors, models.ImportError{Message: "No header naming the player column and at least one counter was found"})
+		return nil, report
+	}
+
+	nameColumn := -1
+	for column, title := range header {
+		key, ok := columns[column]
+		if !ok {
+			if strings.TrimSpace(title) != "" {
+				report.Ignored = append(report.Ignored, title)
+			}
+			continue
+		}
+		if previous, used := report.Columns[key]; used {
+			report.Errors = append(report.Errors, models.ImportError{Row: headerRow + 1, Column: title, Message: fmt.Sprintf("Column maps to %s, already mapped from %q", key, previous)})
+			delete(columns, column)
+			continue
+		}
+		report.Columns[key] = title
+		if key == NameColumn {
+			nameColumn = column
+		}
+	}
+
+	var players []*models.Player
+	seen := make(map[string]int)
+	for i := headerRow + 1; i < len(rows); i++ {
+		row := rows[i]
+		if isBlank(row) {
+			continue
+		}
+
+		name := strings.TrimSpace(cell(row, nameColumn))
+		if isTotal(name) {
+			continue
+		}
+		report.Rows++
+
+		var rowErrors []models.ImportError
+		if name == "" {
+			rowErrors = append(rowErrors, models.ImportError{Row: i + 1, Column: header[nameColumn], Message: "Missing player name"})
+		} else if first, ok := seen[name]; ok {
+			rowErrors = append(rowErrors, models.ImportError{Row: i + 1, Column: header[nameColumn], Message: fmt.Sprintf("Player %s already imported from row %d", name, first)})
+		}
+
+		player := &models.Player{Name: name}
+		for _, column := range sortedColumns(columns) {
+			key := columns[column]
+			if key == NameColumn {
+				continue
+			}
+
+			value, err := parseCount(cell(row, column))
+			if err != nil {
+				rowErrors = append(rowErrors, models.ImportError{Row: i + 1, Column: header[column], Message: err.Error()})
+				continue
+			}
+			player.SetCounter(key, value)
+		}
+
+		if len(rowErrors) > 0 {
+			report.Errors = append(report.Errors, rowErrors...)
+			continue
+		}
+		seen[name] = i + 1
+		players = append(players, player)
+	}
+	return players, report
+}
+
+// normalise reduces a header to its lower case letters and digits.
+func normalise(header string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, header)
+}
+
+// aliasLookup maps every normalised alias to its key, reporting an alias
+// given for more than one key.
+func aliasLookup(aliases map[string][]string) (map[string]string, error) {
+	lookup := make(map[string]string)
+	for _, key := range Keys() {
+		for _, alias := range append([]string{key}, aliases[key]...) {
+			normalised := normalise(alias)
+			if other, ok := lookup[normalised]; ok && other != key {
+				return nil, fmt.Errorf("Alias %q is given for both %s and %s", alias, other, key)
+			}
+			lookup[normalised] = key
+		}
+	}
+	return lookup, nil
+}
+
+// findHeader returns the index of the header row, its titles and the key of
+// each of its mapped columns, or -1 if no row names the player column and a
+// counter. Blank titles are taken from the row above, as left by headers
+// merged across two rows.
+func findHeader(rows [][]string, lookup map[string]string) (int, []string, map[int]string) {
+	for i := 0; i < len(rows) && i < headerRows; i++ {
+		header := append([]string{}, rows[i]...)
+		if i > 0 {
+			for column, title := range rows[i-1] {
+				if column >= len(header) {
+					header = append(header, "")
+				}
+				if strings.TrimSpace(header[column]) == "" {
+					header[column] = title
+				}
+			}
+		}
+
+		columns := make(map[int]string)
+		hasName, hasCounter := false, false
+		for column, title := range header {
+			if key, ok := lookup[normalise(title)]; ok {
+				columns[column] = key
+				hasName = hasName || key == NameColumn
+				hasCounter = hasCounter || key != NameColumn
+			}
+		}
+		if hasName && hasCounter {
+			return i, header, columns
+		}
+	}
+	return -1, nil, nil
+}
+
+func sortedColumns(columns map[int]string) []int {
+	sorted := make([]int, 0, len(columns))
+	for column := range columns {
+		sorted = append(sorted, column)
+	}
+	sort.Ints(sorted)
+	return sorted
+}
+
+// parseCount reads a counter, treating an empty cell as 0. Whole numbers
+// written with decimals, as spreadsheet applications store them, are accepted.
+func parseCount(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsInf(number, 0) || math.IsNaN(number) {
+		return 0, fmt.Errorf("%q is not a number", value)
+	}
+	if number != math.Trunc(number) {
+		return 0, fmt.Errorf("%q is not a whole number", value)
+	}
+	if number < 0 {
+		return 0, fmt.Errorf("%q is negative", value)
+	}
+	if number > math.MaxInt32 {
+		return 0, fmt.Errorf("%q is too large", value)
+	}
+	return int(number), nil
+}
+
+func cell(row []string, column int) string {
+	if column < 0 || column >= len(row) {
+		return ""
+	}
+	return row[column]
+}
+
+func isBlank(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func isTotal(name string) bool {
+	for _, total := range totalNames {
+		if strings.EqualFold(name, total) {
+			return true
+		}
+	}
+	return false
+}
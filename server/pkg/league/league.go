@@ -0,0 +1,201 @@
+// Package league builds league tables from finished match results.
+package league
+
+import (
+	"sort"
+
+	"github.com/Tchoukball-Tracker/pkg/models"
+)
+
+// result is the outcome of a single counted match after forfeits are applied.
+type result struct {
+	home, away           string
+	homeScore, awayScore int
+	forfeit              string
+}
+
+// rankKey returns the value each team in group is ordered by, higher first.
+type rankKey func(group []*models.Standing) map[string]int
+
+// Table computes the standings for the finished matches using the given rules.
+// Matches that are unfinished or missing a team are ignored.
+func Table(matches []*models.Match, rules models.LeagueRules) []*models.Standing {
+	standings := make(map[string]*models.Standing)
+	standing := func(team string) *models.Standing {
+		if _, ok := standings[team]; !ok {
+			standings[team] = &models.Standing{Team: team}
+		}
+		return standings[team]
+	}
+
+	var results []result
+	for _, match := range matches {
+		if !match.IsFinished() || match.HomeTeam == "" || match.AwayTeam == "" {
+			continue
+		}
+
+		res := result{
+			home:      match.HomeTeam,
+			away:      match.AwayTeam,
+			homeScore: match.HomeScore,
+			awayScore: match.AwayScore,
+			forfeit:   match.Forfeit,
+		}
+		if rules.ForfeitScore > 0 {
+			switch res.forfeit {
+			case models.SideHome:
+				res.homeScore, res.awayScore = 0, rules.ForfeitScore
+			case models.SideAway:
+				res.homeScore, res.awayScore = rules.ForfeitScore, 0
+			}
+		}
+		results = append(results, res)
+
+		home, away := standing(res.home), standing(res.away)
+		homeOutcome, awayOutcome := outcome(res)
+		record(home, res.homeScore, res.awayScore, homeOutcome, res.forfeit == models.SideHome, rules)
+		record(away, res.awayScore, res.homeScore, awayOutcome, res.forfeit == models.SideAway, rules)
+	}
+
+	table := make([]*models.Standing, 0, len(standings))
+	for _, s := range standings {
+		table = append(table, s)
+	}
+
+	keys := []rankKey{func(group []*models.Standing) map[string]int {
+		values := make(map[string]int, len(group))
+		for _, s := range group {
+			values[s.Team] = s.LeaguePoints
+		}
+		return values
+	}}
+	for _, tiebreaker := range rules.Tiebreakers {
+		keys = append(keys, tiebreakKey(tiebreaker, results, rules))
+	}
+
+	table = order(table, keys)
+	for i, s := range table {
+		s.Position = i + 1
+	}
+	return table
+}
+
+const (
+	loss = iota
+	draw
+	win
+)
+
+// outcome returns whether the home and away team won, drew or lost.
+func outcome(res result) (int, int) {
+	switch {
+	case res.forfeit == models.SideHome:
+		return loss, win
+	case res.forfeit == models.SideAway:
+		return win, loss
+	case res.homeScore > res.awayScore:
+		return win, loss
+	case res.homeScore < res.awayScore:
+		return loss, win
+	}
+	return draw, draw
+}
+
+// points returns the league points earned by the home and away team.
+func points(res result, rules models.LeagueRules) (int, int) {
+	home, away := outcome(res)
+	return leaguePoints(home, res.forfeit == models.SideHome, rules), leaguePoints(away, res.forfeit == models.SideAway, rules)
+}
+
+func leaguePoints(outcome int, forfeited bool, rules models.LeagueRules) int {
+	switch {
+	case forfeited:
+		return rules.PointsForfeit
+	case outcome == win:
+		return rules.PointsWin
+	case outcome == draw:
+		return rules.PointsDraw
+	}
+	return rules.PointsLoss
+}
+
+func record(s *models.Standing, scored, conceded, outcome int, forfeited bool, rules models.LeagueRules) {
+	s.Played++
+	s.PointsFor += scored
+	s.PointsAgainst += conceded
+	s.PointsDifference = s.PointsFor - s.PointsAgainst
+	s.LeaguePoints += leaguePoints(outcome, forfeited, rules)
+
+	switch outcome {
+	case win:
+		s.Won++
+	case draw:
+		s.Drawn++
+	default:
+		s.Lost++
+	}
+
+	if forfeited {
+		s.Forfeited++
+	}
+}
+
+func tiebreakKey(tiebreaker string, results []result, rules models.LeagueRules) rankKey {
+	return func(group []*models.Standing) map[string]int {
+		values := make(map[string]int, len(group))
+		switch tiebreaker {
+		case models.TiebreakPointsDifference:
+			for _, s := range group {
+				values[s.Team] = s.PointsDifference
+			}
+		case models.TiebreakPointsScored:
+			for _, s := range group {
+				values[s.Team] = s.PointsFor
+			}
+		case models.TiebreakHeadToHead:
+			// League points earned only in the matches played between the tied teams
+			tied := make(map[string]bool, len(group))
+			for _, s := range group {
+				tied[s.Team] = true
+				values[s.Team] = 0
+			}
+			for _, res := range results {
+				if !tied[res.home] || !tied[res.away] {
+					continue
+				}
+				homePoints, awayPoints := points(res, rules)
+				values[res.home] += homePoints
+				values[res.away] += awayPoints
+			}
+		}
+		return values
+	}
+}
+
+// order sorts the group by the first key and recursively breaks ties between
+// teams that share a value with the remaining keys. Teams that are still level
+// after every key are ordered by name so the table is stable.
+func order(group []*models.Standing, keys []rankKey) []*models.Standing {
+	if len(group) < 2 || len(keys) == 0 {
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].Team < group[j].Team
+		})
+		return group
+	}
+
+	values := keys[0](group)
+	sort.SliceStable(group, func(i, j int) bool {
+		return values[group[i].Team] > values[group[j].Team]
+	})
+
+	ordered := make([]*models.Standing, 0, len(group))
+	for start := 0; start < len(group); {
+		end := start + 1
+		for end < len(group) && values[group[end].Team] == values[group[start].Team] {
+			end++
+		}
+		ordered = append(ordered, order(group[start:end], keys[1:])...)
+		start = end
+	}
+	return ordered
+}
@@ -0,0 +1,112 @@
+package league
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Tchoukball-Tracker/pkg/models"
+)
+
+func finished(home, away string, homeScore, awayScore int) *models.Match {
+	return &models.Match{
+		HomeTeam:  home,
+		AwayTeam:  away,
+		HomeScore: homeScore,
+		AwayScore: awayScore,
+		Status:    models.MatchStatusFinished,
+	}
+}
+
+func TestTableOrder(t *testing.T) {
+	// A and B both win twice and lose once. A has the better points
+	// difference but B won the match between them.
+	level := []*models.Match{
+		finished("B", "A", 11, 10),
+		finished("A", "C", 30, 10),
+		finished("A", "D", 30, 10),
+		finished("B", "C", 11, 10),
+		finished("D", "B", 11, 10),
+	}
+
+	forfeited := finished("A", "B", 20, 5)
+	forfeited.Forfeit = models.SideHome
+
+	tests := []struct {
+		name        string
+		matches     []*models.Match
+		tiebreakers []string
+		want        []string
+	}{
+		{
+			name:        "points difference first",
+			matches:     level,
+			tiebreakers: []string{models.TiebreakPointsDifference, models.TiebreakHeadToHead},
+			want:        []string{"A", "B", "D", "C"},
+		},
+		{
+			name:        "head to head first",
+			matches:     level,
+			tiebreakers: []string{models.TiebreakHeadToHead, models.TiebreakPointsDifference},
+			want:        []string{"B", "A", "D", "C"},
+		},
+		{
+			name:        "head to head only counts matches between tied teams",
+			matches:     level,
+			tiebreakers: []string{models.TiebreakHeadToHead},
+			want:        []string{"B", "A", "D", "C"},
+		},
+		{
+			name:        "level teams ordered by name",
+			matches:     []*models.Match{finished("Y", "X", 10, 10), finished("Z", "W", 10, 10)},
+			tiebreakers: []string{models.TiebreakPointsDifference, models.TiebreakHeadToHead, models.TiebreakPointsScored},
+			want:        []string{"W", "X", "Y", "Z"},
+		},
+		{
+			name:        "forfeit loses despite the score",
+			matches:     []*models.Match{forfeited},
+			tiebreakers: []string{models.TiebreakPointsDifference},
+			want:        []string{"B", "A"},
+		},
+		{
+			name:        "unfinished matches ignored",
+			matches:     []*models.Match{finished("A", "B", 10, 5), {HomeTeam: "C", AwayTeam: "A", HomeScore: 20, Status: models.MatchStatusLive}},
+			tiebreakers: []string{models.TiebreakPointsDifference},
+			want:        []string{"A", "B"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rules := models.DefaultLeagueRules()
+			rules.Tiebreakers = test.tiebreakers
+
+			var got []string
+			for i, standing := range Table(test.matches, rules) {
+				if standing.Position != i+1 {
+					t.Errorf("%s has position %d, want %d", standing.Team, standing.Position, i+1)
+				}
+				got = append(got, standing.Team)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got order %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTableForfeitScore(t *testing.T) {
+	match := finished("A", "B", 20, 5)
+	match.Forfeit = models.SideHome
+
+	rules := models.DefaultLeagueRules()
+	rules.ForfeitScore = 10
+
+	table := Table([]*models.Match{match}, rules)
+	winner, loser := table[0], table[1]
+	if winner.Team != "B" || winner.PointsFor != 10 || winner.PointsAgainst != 0 || winner.LeaguePoints != rules.PointsWin {
+		t.Errorf("got winner %+v", winner)
+	}
+	if loser.Team != "A" || loser.Forfeited != 1 || loser.LeaguePoints != rules.PointsForfeit || loser.PointsDifference != -10 {
+		t.Errorf("got loser %+v", loser)
+	}
+}
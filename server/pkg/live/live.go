@@ -0,0 +1,192 @@
+// Package live fans out updates of a match to the clients following it.
+//
+// Every update gets an ID made of the hub's epoch, which changes each time the
+// server starts, and a sequence number counted across all matches. The last
+// HistorySize updates of each match are kept so that a client reconnecting
+// with the ID of the last update it saw is sent only what it missed. A client
+// whose ID is unknown, from an earlier epoch or too old has to start over
+// from a fresh snapshot.
+package live
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// HistorySize is the number of updates kept per match for resuming clients.
+	HistorySize = 256
+	// SubscriberBuffer is the number of updates queued for a subscriber before
+	// it is considered too slow and dropped.
+	SubscriberBuffer = 64
+	// IdleTimeout is how long a match without subscribers or updates keeps its history.
+	IdleTimeout = time.Hour
+)
+
+// Update is a change to a match, with its data encoded as JSON.
+type Update struct {
+	ID   string
+	Type string
+	Data json.RawMessage
+	seq  uint64
+}
+
+// Subscription receives the updates of a match published after it was made.
+// Updates is closed if the subscriber falls too far behind.
+type Subscription struct {
+	Updates <-chan Update
+	Missed  []Update // Updates published since the resumed ID
+	Resumed bool     // Whether the resumed ID was found, otherwise a snapshot is needed
+	LastID  string   // ID of the last update published before the subscription
+	cancel  func()
+}
+
+// Cancel stops the subscription.
+func (s *Subscription) Cancel() {
+	s.cancel()
+}
+
+type topic struct {
+	floor       uint64 // Sequence number after which every update is in the history
+	history     []Update
+	subscribers map[chan Update]bool
+	updated     time.Time
+}
+
+// Hub holds the updates and subscribers of every match.
+type Hub struct {
+	mu     sync.Mutex
+	epoch  string
+	seq    uint64
+	topics map[primitive.ObjectID]*topic
+}
+
+// NewHub returns an empty hub with a new epoch.
+func NewHub() *Hub {
+	return &Hub{
+		epoch:  strconv.FormatInt(time.Now().UnixNano(), 36),
+		topics: make(map[primitive.ObjectID]*topic),
+	}
+}
+
+var hub = NewHub()
+
+// Publish sends an update of a match through the default hub.
+func Publish(match primitive.ObjectID, updateType string, data interface{}) error {
+	return hub.Publish(match, updateType, data)
+}
+
+// Subscribe follows a match through the default hub.
+func Subscribe(match primitive.ObjectID, lastID string) *Subscription {
+	return hub.Subscribe(match, lastID)
+}
+
+// Publish records an update of a match and sends it to every subscriber.
+// Subscribers whose queue is full are dropped.
+func (h *Hub) Publish(match primitive.ObjectID, updateType string, data interface{}) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	h.prune(now)
+	t := h.topic(match)
+	h.seq++
+	t.updated = now
+	update := Update{ID: h.id(h.seq), Type: updateType, Data: encoded, seq: h.seq}
+	t.history = append(t.history, update)
+	if len(t.history) > HistorySize {
+		t.floor = t.history[len(t.history)-HistorySize-1].seq
+		t.history = append([]Update{}, t.history[len(t.history)-HistorySize:]...)
+	}
+
+	for subscriber := range t.subscribers {
+		select {
+		case subscriber <- update:
+		default:
+			delete(t.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+	return nil
+}
+
+// Subscribe follows a match from now on. If lastID is the ID of an update
+// still in the history, the updates published after it are returned as
+// missed.
+func (h *Hub) Subscribe(match primitive.ObjectID, lastID string) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.prune(time.Now())
+	t := h.topic(match)
+	updates := make(chan Update, SubscriberBuffer)
+	t.subscribers[updates] = true
+
+	subscription := &Subscription{Updates: updates, LastID: h.id(h.seq)}
+	if seq, ok := h.parseID(lastID); ok && seq >= t.floor && seq <= h.seq {
+		subscription.Resumed = true
+		for _, update := range t.history {
+			if update.seq > seq {
+				subscription.Missed = append(subscription.Missed, update)
+			}
+		}
+	}
+
+	subscription.cancel = func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if t.subscribers[updates] {
+			delete(t.subscribers, updates)
+			close(updates)
+		}
+		t.updated = time.Now()
+		// A match followed but never updated has no history to keep
+		if len(t.subscribers) == 0 && len(t.history) == 0 && h.topics[match] == t {
+			delete(h.topics, match)
+		}
+	}
+	return subscription
+}
+
+func (h *Hub) topic(match primitive.ObjectID) *topic {
+	t, ok := h.topics[match]
+	if !ok {
+		t = &topic{floor: h.seq, subscribers: make(map[chan Update]bool), updated: time.Now()}
+		h.topics[match] = t
+	}
+	return t
+}
+
+// prune forgets matches nobody has followed or updated for IdleTimeout. It
+// runs on each publish and subscribe.
+func (h *Hub) prune(now time.Time) {
+	for match, t := range h.topics {
+		if len(t.subscribers) == 0 && now.Sub(t.updated) > IdleTimeout {
+			delete(h.topics, match)
+		}
+	}
+}
+
+func (h *Hub) id(seq uint64) string {
+	return fmt.Sprintf("%s-%d", h.epoch, seq)
+}
+
+func (h *Hub) parseID(id string) (uint64, bool) {
+	epoch, seq, found := strings.Cut(id, "-")
+	if !found || epoch != h.epoch {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	return n, err == nil
+}
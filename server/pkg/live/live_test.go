@@ -0,0 +1,120 @@
+package live
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// publishMany publishes n updates of a match and returns their IDs.
+func publishMany(t *testing.T, h *Hub, match primitive.ObjectID, n int) []string {
+	t.Helper()
+	var ids []string
+	for i := 0; i < n; i++ {
+		if err := h.Publish(match, "score", i); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, h.topics[match].history[len(h.topics[match].history)-1].ID)
+	}
+	return ids
+}
+
+func TestSubscribeResume(t *testing.T) {
+	earlierEpoch := strconv.FormatInt(time.Now().Add(-time.Hour).UnixNano(), 36)
+
+	tests := []struct {
+		name      string
+		published int
+		lastID    func(h *Hub, ids []string) string
+		resumed   bool
+		missed    int
+	}{
+		{name: "no ID", published: 3, lastID: func(h *Hub, ids []string) string { return "" }},
+		{name: "first update", published: 3, lastID: func(h *Hub, ids []string) string { return ids[0] }, resumed: true, missed: 2},
+		{name: "latest update", published: 3, lastID: func(h *Hub, ids []string) string { return ids[2] }, resumed: true},
+		{name: "oldest update kept", published: HistorySize + 2, lastID: func(h *Hub, ids []string) string { return ids[1] }, resumed: true, missed: HistorySize},
+		{name: "update out of the history", published: HistorySize + 2, lastID: func(h *Hub, ids []string) string { return ids[0] }},
+		{name: "earlier epoch", published: 3, lastID: func(h *Hub, ids []string) string { return earlierEpoch + "-1" }},
+		{name: "future update", published: 3, lastID: func(h *Hub, ids []string) string { return h.id(h.seq + 1) }},
+		{name: "malformed ID", published: 3, lastID: func(h *Hub, ids []string) string { return h.epoch + "-x" }},
+	}
+
+	match := primitive.NewObjectID()
+	for _, test := range tests {
+		h := NewHub()
+		ids := publishMany(t, h, match, test.published)
+
+		subscription := h.Subscribe(match, test.lastID(h, ids))
+		if subscription.Resumed != test.resumed || len(subscription.Missed) != test.missed {
+			t.Errorf("%s: resumed %t with %d missed, want %t with %d", test.name, subscription.Resumed, len(subscription.Missed), test.resumed, test.missed)
+		}
+		if test.missed > 0 && subscription.Missed[test.missed-1].ID != ids[len(ids)-1] {
+			t.Errorf("%s: last missed update %s, want %s", test.name, subscription.Missed[test.missed-1].ID, ids[len(ids)-1])
+		}
+		if subscription.LastID != ids[len(ids)-1] {
+			t.Errorf("%s: last ID %s, want %s", test.name, subscription.LastID, ids[len(ids)-1])
+		}
+		subscription.Cancel()
+	}
+}
+
+func TestPublishDropsFullSubscribers(t *testing.T) {
+	h := NewHub()
+	match := primitive.NewObjectID()
+	slow := h.Subscribe(match, "")
+	fast := h.Subscribe(match, "")
+	defer fast.Cancel()
+
+	for i := 0; i <= SubscriberBuffer; i++ {
+		if err := h.Publish(match, "score", i); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := <-fast.Updates; !ok {
+			t.Fatalf("subscriber keeping up dropped after %d updates", i)
+		}
+	}
+
+	received := 0
+	for range slow.Updates {
+		received++
+	}
+	if received != SubscriberBuffer {
+		t.Errorf("slow subscriber received %d updates before being dropped, want %d", received, SubscriberBuffer)
+	}
+	if len(h.topics[match].subscribers) != 1 {
+		t.Errorf("%d subscribers left, want 1", len(h.topics[match].subscribers))
+	}
+	slow.Cancel()
+}
+
+func TestTopicsFreed(t *testing.T) {
+	h := NewHub()
+	quiet, updated, other := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+
+	// A match only followed is forgotten with its last subscriber
+	first, second := h.Subscribe(quiet, ""), h.Subscribe(quiet, "")
+	first.Cancel()
+	if h.topics[quiet] == nil {
+		t.Fatal("match forgotten while still followed")
+	}
+	second.Cancel()
+	if h.topics[quiet] != nil {
+		t.Error("match followed but never updated kept once unfollowed")
+	}
+
+	// A match with updates keeps them until it has been idle long enough,
+	// even if nothing is published again
+	subscription := h.Subscribe(updated, "")
+	publishMany(t, h, updated, 1)
+	subscription.Cancel()
+	if h.topics[updated] == nil {
+		t.Fatal("history dropped as soon as the match was unfollowed")
+	}
+	h.topics[updated].updated = time.Now().Add(-IdleTimeout - time.Minute)
+	h.Subscribe(other, "").Cancel()
+	if h.topics[updated] != nil {
+		t.Error("idle match kept after a subscription to another match")
+	}
+}
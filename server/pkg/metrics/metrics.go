@@ -0,0 +1,134 @@
+// Package metrics derives efficiency ratios from the attacking and defending
+// counters. Every metric is a fraction between 0 and 1 and is 0 when its
+// denominator is 0.
+//
+// Shot attempts are every attack that ended in a shot:
+//
+//	attempts = point + caught + short + frame + footing + landed
+//
+// The metrics are:
+//
+//	shotSuccessRate = point / attempts
+//	errorRate       = (short + frame + footing + landed) / attempts
+//	passReliability = attempts / (attempts + badPass + dropPass)
+//	firstLineShare  = first / (first + second)
+//	secondLineShare = second / (first + second)
+package metrics
+
+import "github.com/Tchoukball-Tracker/pkg/models"
+
+const (
+	ShotSuccessRate = "shotSuccessRate"
+	ErrorRate       = "errorRate"
+	PassReliability = "passReliability"
+	FirstLineShare  = "firstLineShare"
+	SecondLineShare = "secondLineShare"
+)
+
+// Definition documents a derived metric.
+type Definition struct {
+	Name        string `json:"name"`
+	Formula     string `json:"formula"`
+	Description string `json:"description"`
+	compute     func(c models.Counters) float64
+}
+
+// Definitions lists the derived metrics in the order they are reported.
+var Definitions = []Definition{
+	{
+		Name:        ShotSuccessRate,
+		Formula:     "point / (point + caught + short + frame + footing + landed)",
+		Description: "Share of shot attempts that scored a point",
+		compute: func(c models.Counters) float64 {
+			return ratio(c.Attacking.Point, ShotAttempts(c.Attacking))
+		},
+	},
+	{
+		Name:        ErrorRate,
+		Formula:     "(short + frame + footing + landed) / (point + caught + short + frame + footing + landed)",
+		Description: "Share of shot attempts lost to a shooting error",
+		compute: func(c models.Counters) float64 {
+			a := c.Attacking
+			return ratio(a.Short+a.Frame+a.Footing+a.Landed, ShotAttempts(a))
+		},
+	},
+	{
+		Name:        PassReliability,
+		Formula:     "(point + caught + short + frame + footing + landed) / (point + caught + short + frame + footing + landed + badPass + dropPass)",
+		Description: "Share of attacks that reached a shot without a bad or dropped pass",
+		compute: func(c models.Counters) float64 {
+			attempts := ShotAttempts(c.Attacking)
+			return ratio(attempts, attempts+c.Attacking.BadPass+c.Attacking.DropPass)
+		},
+	},
+	{
+		Name:        FirstLineShare,
+		Formula:     "first / (first + second)",
+		Description: "Share of defensive catches made on the first line",
+		compute: func(c models.Counters) float64 {
+			d := c.Defending
+			return ratio(d.FirstLine, d.FirstLine+d.SecondLine)
+		},
+	},
+	{
+		Name:        SecondLineShare,
+		Formula:     "second / (first + second)",
+		Description: "Share of defensive catches made on the second line",
+		compute: func(c models.Counters) float64 {
+			d := c.Defending
+			return ratio(d.SecondLine, d.FirstLine+d.SecondLine)
+		},
+	},
+}
+
+// ShotAttempts returns the number of attacks that ended in a shot.
+func ShotAttempts(a models.Attacking) int {
+	return a.Point + a.Caught + a.Short + a.Frame + a.Footing + a.Landed
+}
+
+// Compute returns every derived metric of the counters keyed by name.
+func Compute(c models.Counters) map[string]float64 {
+	values := make(map[string]float64, len(Definitions))
+	for _, definition := range Definitions {
+		values[definition.Name] = definition.compute(c)
+	}
+	return values
+}
+
+// Variables returns the names a user-defined formula can use: every counter
+// followed by every derived metric.
+func Variables() []string {
+	var names []string
+	names = append(names, models.AttackingCounters...)
+	names = append(names, models.DefendingCounters...)
+	for _, definition := range Definitions {
+		names = append(names, definition.Name)
+	}
+	return names
+}
+
+// Values returns the counters and derived metrics keyed by the names in Variables.
+func Values(c models.Counters) map[string]float64 {
+	values := c.Values()
+	for name, value := range Compute(c) {
+		values[name] = value
+	}
+	return values
+}
+
+// IsMetric reports whether name is a derived metric.
+func IsMetric(name string) bool {
+	for _, definition := range Definitions {
+		if definition.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func ratio(numerator, denominator int) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return float64(numerator) / float64(denominator)
+}
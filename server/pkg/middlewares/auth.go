@@ -1,17 +1,39 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"os"
 
+	"github.com/Tchoukball-Tracker/pkg/database"
 	"github.com/Tchoukball-Tracker/pkg/models"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 )
 
-var jwtKey = []byte(os.Getenv("JWT_SECRET_KEY"))
+// ErrNoJWTSecret is returned when JWT_SECRET_KEY is not set.
+var ErrNoJWTSecret = errors.New("JWT_SECRET_KEY is not set")
 
-func JWTAuthMiddleware() gin.HandlerFunc {
+// JWTKey returns the key login tokens are signed with. It is read from the
+// environment when needed, as the .env file is only loaded once main runs,
+// and is an error when empty so that tokens are never signed without a secret.
+func JWTKey() ([]byte, error) {
+	key := os.Getenv("JWT_SECRET_KEY")
+	if key == "" {
+		return nil, ErrNoJWTSecret
+	}
+	return []byte(key), nil
+}
+
+// ClaimsKey is the context key the claims of the authenticated user are stored under.
+const ClaimsKey = "claims"
+
+// UserKey is the context key the authenticated user is stored under.
+const UserKey = "user"
+
+// JWTAuthMiddleware authenticates the user of the auth_token cookie and lets
+// them through if their role is granted the required models.Permission.
+func JWTAuthMiddleware(requirement string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString, err := c.Cookie("auth_token")
 		if err != nil {
@@ -22,7 +44,7 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 
 		claims := &models.Claims{}
 		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return jwtKey, nil
+			return JWTKey()
 		})
 
 		if err != nil || !token.Valid {
@@ -31,6 +53,45 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Tokens stay valid for days, so the user is checked on every request
+		// to lock out those disabled or deleted since they logged in.
+		result, err := database.FindByName(c.Request.Context(), &models.User{}, claims.Username)
+		if err != nil || result.(*models.User).Disabled {
+			c.JSON(http.StatusUnauthorized, models.HTTPError{Code: http.StatusUnauthorized, Message: "Account disabled or removed"})
+			c.Abort()
+			return
+		}
+
+		// The role is also read from the database so that a change applies
+		// straight away rather than at the next login.
+		user := result.(*models.User)
+		claims.Role = user.EffectiveRole()
+		if !models.RoleCan(claims.Role, requirement) {
+			c.JSON(http.StatusForbidden, models.HTTPError{Code: http.StatusForbidden, Message: "The " + claims.Role + " role is not allowed to do this"})
+			c.Abort()
+			return
+		}
+
+		c.Set(ClaimsKey, claims)
+		c.Set(UserKey, user)
 		c.Next()
 	}
 }
+
+// GetClaims returns the claims of the user authenticated by JWTAuthMiddleware.
+func GetClaims(c *gin.Context) *models.Claims {
+	claims, _ := c.Get(ClaimsKey)
+	if claims == nil {
+		return &models.Claims{}
+	}
+	return claims.(*models.Claims)
+}
+
+// GetUser returns the user authenticated by JWTAuthMiddleware.
+func GetUser(c *gin.Context) *models.User {
+	user, _ := c.Get(UserKey)
+	if user == nil {
+		return &models.User{}
+	}
+	return user.(*models.User)
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"net/http"
+	"time"
+
+	"github.com/Tchoukball-Tracker/pkg/database"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// ShareKey is the context key the share of a public request is stored under.
+const ShareKey = "share"
+
+// shareKey returns the key share tokens are signed with. It is derived from
+// the JWT secret so that a share token can never be used as a login token,
+// nor the other way round, and fails like JWTKey when the secret is not set.
+func shareKey() ([]byte, error) {
+	key, err := JWTKey()
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("share"))
+	return mac.Sum(nil), nil
+}
+
+// NewShareToken signs a token for a share, valid until the share expires.
+func NewShareToken(share *models.Share) (string, error) {
+	claims := &models.ShareClaims{
+		Share: share.ID.Hex(),
+		Match: share.Match.Hex(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(share.ExpiresAt),
+			IssuedAt:  jwt.NewNumericDate(share.CreatedAt),
+		},
+	}
+	key, err := shareKey()
+	if err != nil {
+		return "", err
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+}
+
+// ShareAuthMiddleware checks the share token in the token path parameter and
+// that its share has been neither revoked nor expired, storing the share in
+// the context.
+func ShareAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := &models.ShareClaims{}
+		token, err := jwt.ParseWithClaims(c.Param("token"), claims, func(token *jwt.Token) (interface{}, error) {
+			return shareKey()
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+		if err != nil || !token.Valid {
+			c.JSON(http.StatusUnauthorized, models.HTTPError{Code: http.StatusUnauthorized, Message: "Invalid or expired share link"})
+			c.Abort()
+			return
+		}
+
+		result, err := database.Find(c.Request.Context(), &models.Share{ID: utils.ConvertToMongoID(claims.Share)})
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.HTTPError{Code: http.StatusUnauthorized, Message: "Invalid or expired share link"})
+			c.Abort()
+			return
+		}
+
+		share := result.(*models.Share)
+		if share.Match.Hex() != claims.Match || !share.Active(time.Now()) {
+			c.JSON(http.StatusUnauthorized, models.HTTPError{Code: http.StatusUnauthorized, Message: "Invalid or expired share link"})
+			c.Abort()
+			return
+		}
+
+		c.Set(ShareKey, share)
+		c.Next()
+	}
+}
+
+// GetShare returns the share checked by ShareAuthMiddleware.
+func GetShare(c *gin.Context) *models.Share {
+	share, _ := c.Get(ShareKey)
+	if share == nil {
+		return &models.Share{}
+	}
+	return share.(*models.Share)
+}
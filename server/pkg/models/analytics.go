@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	AnalyticsNDJSON  = "ndjson"
+	AnalyticsParquet = "parquet"
+)
+
+// ActionRow is an entry of the action log flattened with the details of its
+// match for analysis.
+type ActionRow struct {
+	Match       primitive.ObjectID `bson:"match"`
+	MatchName   string             `bson:"match_name"`
+	MatchDate   time.Time          `bson:"match_date"`
+	Competition string             `bson:"competition"`
+	Season      string             `bson:"season"`
+	Period      string             `bson:"period"`
+	Type        string             `bson:"type"`
+	Clock       *int               `bson:"clock"`
+	Player      string             `bson:"player"`
+	Action      string             `bson:"action"`
+	Value       int                `bson:"value"`
+	PlayerIn    string             `bson:"player_in"`
+	PlayerOut   string             `bson:"player_out"`
+	CreatedAt   time.Time          `bson:"created_at"`
+}
+
+// CounterRow is the counters of a player in one third of a match, with the
+// details of the match, for analysis.
+type CounterRow struct {
+	Match       primitive.ObjectID `bson:"match"`
+	MatchName   string             `bson:"match_name"`
+	MatchDate   time.Time          `bson:"match_date"`
+	Competition string             `bson:"competition"`
+	Season      string             `bson:"season"`
+	Period      string             `bson:"period"`
+	Player      string             `bson:"player"`
+	Counters    `bson:",inline"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ArchiveVersion is the schema version of the match archives written by this
+// server. Archives of any other version are refused on import.
+const ArchiveVersion = 1
+
+// MatchArchive holds everything needed to recreate a match on another server.
+// Identifiers are those of the exporting server and are replaced on import.
+type MatchArchive struct {
+	Version      int                     `json:"schema_version"`
+	ExportedAt   time.Time               `json:"exported_at"`
+	Match        *Match                  `json:"match"`
+	Spreadsheets map[string]*Spreadsheet `json:"spreadsheets"` // Keyed by period
+	Events       []*Event                `json:"events"`
+	Players      []string                `json:"players"` // Every player named in the spreadsheets, lineups and events
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultDashboardColumns is the width of a dashboard grid when none is given.
+const DefaultDashboardColumns = 12
+
+// Dashboard is an ordered grid of graphs belonging to a user.
+type Dashboard struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Name      string             `json:"name" bson:"name"`
+	Owner     string             `json:"owner" bson:"owner"`   // Name of the user who created it
+	Shared    bool               `json:"shared" bson:"shared"` // Whether other users can view and clone it
+	Columns   int                `json:"columns" bson:"columns"`
+	Graphs    []*DashboardGraph  `json:"graphs" bson:"graphs"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// DashboardGraph places a graph on a dashboard. A missing position or size
+// falls back to the one saved on the graph.
+type DashboardGraph struct {
+	Graph    primitive.ObjectID `json:"graph" bson:"graph"`
+	Position *Position          `json:"position,omitempty" bson:"position,omitempty"`
+	Size     *Size              `json:"size,omitempty" bson:"size,omitempty"`
+}
+
+// CollectionName implements MongoModel.
+func (db *Dashboard) CollectionName() string {
+	return "Dashboards"
+}
+
+// GetID implements DatabaseEntity.
+func (db *Dashboard) GetID() primitive.ObjectID {
+	return db.ID
+}
+
+// SetID implements DatabaseEntity.
+func (db *Dashboard) SetID(id primitive.ObjectID) {
+	db.ID = id
+}
+
+// New implements DatabaseEntity.
+func (db *Dashboard) New() DatabaseEntity {
+	return &Dashboard{}
+}
+
+// CanView reports whether the user can see the dashboard.
+func (db *Dashboard) CanView(user string) bool {
+	return db.Shared || db.Owner == user
+}
@@ -18,6 +18,12 @@ type Database interface {
 	FindByValue(ctx context.Context, entity DatabaseEntity, filter bson.M) ([]DatabaseEntity, error)
 	Update(ctx context.Context, entity DatabaseEntity) (*mongo.UpdateResult, error)
 	Delete(ctx context.Context, entity DatabaseEntity) (*mongo.DeleteResult, error)
+	Aggregate(ctx context.Context, entity DatabaseEntity, pipeline mongo.Pipeline, results interface{}) error
+	Stream(ctx context.Context, entity DatabaseEntity, pipeline mongo.Pipeline, fn func(document bson.Raw) error) error
+	Collections(ctx context.Context) ([]string, error)
+	Dump(ctx context.Context, collection string, fn func(document bson.Raw) error) error
+	Load(ctx context.Context, collection string, document bson.Raw, overwrite bool) (bool, error)
+	CountByIDs(ctx context.Context, collection string, ids []interface{}) (int64, error)
 }
 
 type DatabaseEntity interface {
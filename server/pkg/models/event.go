@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	EventAction       = "action"
+	EventSubstitution = "substitution"
+	EventConceded     = "conceded"
+	EventPeriodStart  = "period_start"
+	EventPeriodEnd    = "period_end"
+)
+
+// Event is an entry in the action log of a match. Player actions are logged
+// as they are recorded on a spreadsheet, substitutions and points conceded
+// are recorded against the match, as are the start and end of each period.
+type Event struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Match       primitive.ObjectID `json:"match,omitempty" bson:"match,omitempty"`
+	Spreadsheet primitive.ObjectID `json:"spreadsheet,omitempty" bson:"spreadsheet,omitempty"`
+	Period      string             `json:"period,omitempty" bson:"period,omitempty"`
+	Type        string             `json:"type" bson:"type"`
+	Clock       *int               `json:"clock,omitempty" bson:"clock,omitempty"` // Seconds elapsed in the period
+	Player      string             `json:"player,omitempty" bson:"player,omitempty"`
+	Action      string             `json:"action,omitempty" bson:"action,omitempty"`
+	Value       int                `json:"value,omitempty" bson:"value,omitempty"`
+	PlayerIn    string             `json:"player_in,omitempty" bson:"player_in,omitempty"`
+	PlayerOut   string             `json:"player_out,omitempty" bson:"player_out,omitempty"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
+
+type Lineup struct {
+	Players []string `json:"players"`
+}
+
+type PeriodTime struct {
+	Seconds   int `json:"seconds"`
+	PlusMinus int `json:"plus_minus"`
+}
+
+type PlayingTime struct {
+	Player    string                 `json:"player"`
+	Seconds   int                    `json:"seconds"`
+	PlusMinus int                    `json:"plus_minus"`
+	Periods   map[string]*PeriodTime `json:"periods"`
+}
+
+// CollectionName implements MongoModel.
+func (db *Event) CollectionName() string {
+	return "Events"
+}
+
+// GetID implements DatabaseEntity.
+func (db *Event) GetID() primitive.ObjectID {
+	return db.ID
+}
+
+// SetID implements DatabaseEntity.
+func (db *Event) SetID(id primitive.ObjectID) {
+	db.ID = id
+}
+
+// New implements DatabaseEntity.
+func (db *Event) New() DatabaseEntity {
+	return &Event{}
+}
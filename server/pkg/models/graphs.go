@@ -5,13 +5,44 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	GraphBar        = "bar"
+	GraphLine       = "line"
+	GraphRadar      = "radar"
+	GraphStackedBar = "stackedBar"
+)
+
+// GraphTypes lists every kind of graph that can be plotted.
+var GraphTypes = []string{GraphBar, GraphLine, GraphRadar, GraphStackedBar}
+
+const (
+	DataSourceSpreadsheet = "spreadsheet"
+	DataSourceMatch       = "match"
+	DataSourceQuery       = "query"
+)
+
 type Graph struct {
-	ID         string `json:"id,omitempty"`
-	Name       string `json:"name"`
-	Type       string `json:"graphType"`
-	DataSource string `json:"datasource"`
-	// Position   *Position `json:"position"`
-	// Size       *Size     `json:"size"`
+	ID         string    `json:"id,omitempty"`
+	Name       string    `json:"name"`
+	Type       string    `json:"graphType"`
+	DataSource string    `json:"datasource"`
+	SourceType string    `json:"sourceType"`        // spreadsheet, match or query
+	Metrics    []string  `json:"metrics"`           // Counters or metrics plotted, one series each
+	Players    []string  `json:"players,omitempty"` // Players plotted, all if empty
+	Position   *Position `json:"position"`
+	Size       *Size     `json:"size"`
+}
+
+// Series is a named list of values, one per label. A null value is a gap.
+type Series struct {
+	Name   string     `json:"name"`
+	Values []*float64 `json:"values"`
+}
+
+// SeriesData is the ready-to-plot data of a graph.
+type SeriesData struct {
+	Labels []string `json:"labels"`
+	Series []Series `json:"series"`
 }
 
 type DBGraph struct {
@@ -19,8 +50,11 @@ type DBGraph struct {
 	Name       string             `bson:"name"`
 	Type       string             `bson:"type"`
 	DataSource primitive.ObjectID `bson:"datasource,omitempty"`
-	// Position   *DBPosition        `bson:"position,omitempty"`
-	// Size       *DBSize            `bson:"size,omitempty"`
+	SourceType string             `bson:"source_type"`
+	Metrics    []string           `bson:"metrics"`
+	Players    []string           `bson:"players"` // Not omitted so that updates can clear them
+	Position   *DBPosition        `bson:"position"`
+	Size       *DBSize            `bson:"size"`
 }
 
 // GetName implements DatabaseEntity.
@@ -53,13 +87,13 @@ func (db *DBGraph) New() DatabaseEntity {
 	return &DBGraph{}
 }
 
-// func (db *DBGraph) SetPosition(position *DBPosition) {
-// 	db.Position = position
-// }
+func (db *DBGraph) SetPosition(position *DBPosition) {
+	db.Position = position
+}
 
-// func (db *DBGraph) SetSize(size *DBSize) {
-// 	db.Size = size
-// }
+func (db *DBGraph) SetSize(size *DBSize) {
+	db.Size = size
+}
 
 func (db *DBGraph) ToDomain() *Graph {
 	graph := &Graph{
@@ -67,8 +101,11 @@ func (db *DBGraph) ToDomain() *Graph {
 		Name:       db.Name,
 		Type:       db.Type,
 		DataSource: db.DataSource.Hex(),
-		// Position:   db.Position.ToDomain(),
-		// Size:       db.Size.ToDomain(),
+		SourceType: db.SourceType,
+		Metrics:    db.Metrics,
+		Players:    db.Players,
+		Position:   db.Position.ToDomain(),
+		Size:       db.Size.ToDomain(),
 	}
 
 	if graph.DataSource == "000000000000000000000000" {
@@ -87,7 +124,60 @@ func (g *Graph) ToDatabase() *DBGraph {
 		Name:       g.Name,
 		Type:       g.Type,
 		DataSource: dsID,
-		// Position:   g.Position.ToDatabase(),
-		// Size:       g.Size.ToDatabase(),
+		SourceType: g.SourceType,
+		Metrics:    g.Metrics,
+		Players:    g.Players,
+		Position:   g.Position.ToDatabase(),
+		Size:       g.Size.ToDatabase(),
+	}
+}
+
+// Position is the top left cell of a graph on a dashboard grid, counted from 0.
+type Position struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type DBPosition struct {
+	X int `bson:"x"`
+	Y int `bson:"y"`
+}
+
+// Size is the number of grid cells a graph spans on a dashboard.
+type Size struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+type DBSize struct {
+	Width  int `bson:"width"`
+	Height int `bson:"height"`
+}
+
+func (db *DBPosition) ToDomain() *Position {
+	if db == nil {
+		return nil
+	}
+	return &Position{X: db.X, Y: db.Y}
+}
+
+func (p *Position) ToDatabase() *DBPosition {
+	if p == nil {
+		return nil
+	}
+	return &DBPosition{X: p.X, Y: p.Y}
+}
+
+func (db *DBSize) ToDomain() *Size {
+	if db == nil {
+		return nil
+	}
+	return &Size{Width: db.Width, Height: db.Height}
+}
+
+func (s *Size) ToDatabase() *DBSize {
+	if s == nil {
+		return nil
 	}
+	return &DBSize{Width: s.Width, Height: s.Height}
 }
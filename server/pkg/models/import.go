@@ -0,0 +1,20 @@
+package models
+
+// ImportError describes a problem with a row of an imported file. Row counts
+// from 1 as in a spreadsheet application, and is 0 for problems with the
+// file as a whole.
+type ImportError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportReport is the outcome of importing a file of player counters.
+type ImportReport struct {
+	DryRun      bool              `json:"dry_run"`
+	Rows        int               `json:"rows"`              // Player rows read from the file
+	Columns     map[string]string `json:"columns"`           // Headers of the file keyed by the counter they were mapped to
+	Ignored     []string          `json:"ignored,omitempty"` // Headers that did not match any counter
+	Errors      []ImportError     `json:"errors"`
+	Spreadsheet *Spreadsheet      `json:"spreadsheet,omitempty"` // Spreadsheet created, or that would be created in a dry run
+}
@@ -0,0 +1,58 @@
+package models
+
+const (
+	TiebreakPointsDifference = "points_difference"
+	TiebreakHeadToHead       = "head_to_head"
+	TiebreakPointsScored     = "points_scored"
+)
+
+// LeagueRules configures how finished matches are turned into league points.
+type LeagueRules struct {
+	PointsWin     int      `json:"win"`
+	PointsDraw    int      `json:"draw"`
+	PointsLoss    int      `json:"loss"`
+	PointsForfeit int      `json:"forfeit"`       // League points for the team that forfeited
+	ForfeitScore  int      `json:"forfeit_score"` // Score awarded to the opponent of a forfeit, 0 keeps the recorded score
+	Tiebreakers   []string `json:"tiebreakers"`
+}
+
+// DefaultLeagueRules returns the standard 3/2/1 tchoukball points system.
+func DefaultLeagueRules() LeagueRules {
+	return LeagueRules{
+		PointsWin:     3,
+		PointsDraw:    2,
+		PointsLoss:    1,
+		PointsForfeit: 0,
+		Tiebreakers:   []string{TiebreakPointsDifference, TiebreakHeadToHead, TiebreakPointsScored},
+	}
+}
+
+// IsTiebreaker reports whether name is a supported tiebreak rule.
+func IsTiebreaker(name string) bool {
+	switch name {
+	case TiebreakPointsDifference, TiebreakHeadToHead, TiebreakPointsScored:
+		return true
+	}
+	return false
+}
+
+type Standing struct {
+	Position         int    `json:"position"`
+	Team             string `json:"team"`
+	Played           int    `json:"played"`
+	Won              int    `json:"won"`
+	Drawn            int    `json:"drawn"`
+	Lost             int    `json:"lost"`
+	Forfeited        int    `json:"forfeited"`
+	PointsFor        int    `json:"points_for"`
+	PointsAgainst    int    `json:"points_against"`
+	PointsDifference int    `json:"points_difference"`
+	LeaguePoints     int    `json:"league_points"`
+}
+
+type LeagueTable struct {
+	Competition string      `json:"competition"`
+	Season      string      `json:"season,omitempty"`
+	Rules       LeagueRules `json:"rules"`
+	Standings   []*Standing `json:"standings"`
+}
@@ -0,0 +1,36 @@
+package models
+
+// Types of the updates streamed to the clients following a match.
+const (
+	LiveSnapshot      = "snapshot"
+	LiveAction        = "action"
+	LivePlayerAdded   = "player_added"
+	LivePlayerRemoved = "player_removed"
+	LiveScore         = "score"
+)
+
+// LiveMatch is the state of a match sent to a client when it starts
+// following the match, after which it only receives the changes.
+type LiveMatch struct {
+	Match        *Match                  `json:"match"`
+	Spreadsheets map[string]*Spreadsheet `json:"spreadsheets"` // Keyed by period
+	Score        *LiveScoreUpdate        `json:"score"`
+	Trackers     []*Tracker              `json:"trackers"` // Trackers in the tracking session of the match
+}
+
+// LivePlayerUpdate is a change to a player of one of the spreadsheets of a
+// match, holding the player's counters after the change.
+type LivePlayerUpdate struct {
+	Spreadsheet string        `json:"spreadsheet"`
+	Period      string        `json:"period"`
+	Player      *Player       `json:"player"`
+	Action      *PlayerAction `json:"action,omitempty"`
+	Tracker     *Tracker      `json:"tracker,omitempty"` // Set for actions recorded in a tracking session
+}
+
+// LiveScoreUpdate is the score of a match after a point was recorded.
+type LiveScoreUpdate struct {
+	Periods  []PeriodScore `json:"periods"`
+	Scored   int           `json:"scored"`
+	Conceded int           `json:"conceded"`
+}
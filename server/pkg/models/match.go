@@ -1,17 +1,61 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	MatchStatusScheduled = "scheduled"
+	MatchStatusLive      = "live"
+	MatchStatusFinished  = "finished"
+)
+
+const (
+	SideHome = "home"
+	SideAway = "away"
+)
+
+// DefaultPeriodLength is the length of a third in seconds when a match does not set one.
+const DefaultPeriodLength = 15 * 60
+
+// Periods lists the thirds of a match in the order they are played.
+var Periods = []string{"first", "second", "third"}
+
+// PeriodTitle names a period for display, such as "First Third".
+func PeriodTitle(period string) string {
+	if period == "" {
+		return period
+	}
+	return strings.ToUpper(period[:1]) + period[1:] + " Third"
+}
+
 type Match struct {
-	ID        primitive.ObjectID            `json:"id,omitempty" bson:"_id,omitempty"`
-	Name      string                        `json:"name" bson:"name"`
-	Thirds    map[string]primitive.ObjectID `json:"thirds" bson:"thirds"`
-	CreatedAt time.Time                     `json:"created_at" bson:"created_at"`
-	Players   []string                      `json:"players,omitempty" bson:"-"`
+	ID           primitive.ObjectID            `json:"id,omitempty" bson:"_id,omitempty"`
+	Name         string                        `json:"name" bson:"name"`
+	Competition  string                        `json:"competition,omitempty" bson:"competition,omitempty"`
+	Season       string                        `json:"season,omitempty" bson:"season,omitempty"`
+	HomeTeam     string                        `json:"home_team,omitempty" bson:"home_team,omitempty"`
+	AwayTeam     string                        `json:"away_team,omitempty" bson:"away_team,omitempty"`
+	HomeScore    int                           `json:"home_score" bson:"home_score"`
+	AwayScore    int                           `json:"away_score" bson:"away_score"`
+	Status       string                        `json:"status,omitempty" bson:"status,omitempty"`
+	Forfeit      string                        `json:"forfeit,omitempty" bson:"forfeit"`                     // Not omitted so that correcting a result clears it
+	TrackedSide  string                        `json:"tracked_side,omitempty" bson:"tracked_side,omitempty"` // Side whose players are tracked, "home" or "away", home if not set
+	Thirds       map[string]primitive.ObjectID `json:"thirds" bson:"thirds"`
+	Lineups      map[string][]string           `json:"lineups,omitempty" bson:"lineups,omitempty"`
+	PeriodLength int                           `json:"period_length,omitempty" bson:"period_length,omitempty"` // Seconds
+	CreatedAt    time.Time                     `json:"created_at" bson:"created_at"`
+	Players      []string                      `json:"players,omitempty" bson:"-"`
+}
+
+// MatchResult is the payload used to record the final score of a match.
+type MatchResult struct {
+	HomeScore int    `json:"home_score"`
+	AwayScore int    `json:"away_score"`
+	Forfeit   string `json:"forfeit,omitempty"` // Side that forfeited, "home" or "away"
 }
 
 // CollectionName implements MongoModel.
@@ -33,3 +77,52 @@ func (db *Match) SetID(id primitive.ObjectID) {
 func (db *Match) New() DatabaseEntity {
 	return &Match{}
 }
+
+// IsFinished reports whether the match has a final result.
+func (db *Match) IsFinished() bool {
+	return db.Status == MatchStatusFinished
+}
+
+// GetPeriodLength returns the length of a third in seconds.
+func (db *Match) GetPeriodLength() int {
+	if db.PeriodLength > 0 {
+		return db.PeriodLength
+	}
+	return DefaultPeriodLength
+}
+
+// PeriodOf returns the period the spreadsheet is used for, or an empty string
+// if it does not belong to the match.
+func (db *Match) PeriodOf(spreadsheetID primitive.ObjectID) string {
+	for period, id := range db.Thirds {
+		if id == spreadsheetID {
+			return period
+		}
+	}
+	return ""
+}
+
+// IsSide reports whether side is either home or away.
+func IsSide(side string) bool {
+	return side == SideHome || side == SideAway
+}
+
+// Winner returns the name of the winning team, or an empty string for a draw
+// or an unfinished match.
+func (db *Match) Winner() string {
+	if !db.IsFinished() {
+		return ""
+	}
+
+	switch {
+	case db.Forfeit == SideHome:
+		return db.AwayTeam
+	case db.Forfeit == SideAway:
+		return db.HomeTeam
+	case db.HomeScore > db.AwayScore:
+		return db.HomeTeam
+	case db.AwayScore > db.HomeScore:
+		return db.AwayTeam
+	}
+	return ""
+}
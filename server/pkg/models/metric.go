@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Metric is a user-defined metric saved as an expression over the action counters.
+type Metric struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Name        string             `json:"name" bson:"name"`
+	Expression  string             `json:"expression" bson:"expression"`
+	Description string             `json:"description,omitempty" bson:"description,omitempty"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// MetricValue is the result of evaluating a user-defined metric. Value is
+// omitted and Error set when the metric cannot be evaluated.
+type MetricValue struct {
+	Name  string   `json:"name"`
+	Value *float64 `json:"value,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+// CollectionName implements MongoModel.
+func (db *Metric) CollectionName() string {
+	return "Metrics"
+}
+
+// GetID implements DatabaseEntity.
+func (db *Metric) GetID() primitive.ObjectID {
+	return db.ID
+}
+
+// SetID implements DatabaseEntity.
+func (db *Metric) SetID(id primitive.ObjectID) {
+	db.ID = id
+}
+
+// New implements DatabaseEntity.
+func (db *Metric) New() DatabaseEntity {
+	return &Metric{}
+}
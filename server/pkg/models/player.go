@@ -9,8 +9,16 @@ type Player struct {
 type PlayerAction struct {
 	Type  string `json:"type"`
 	Value int    `json:"value"`
+	Clock *int   `json:"clock,omitempty"` // Seconds elapsed in the period
 }
 
+// AttackingCounters and DefendingCounters list the counter names in the order
+// they appear on the paper sheets, matching their JSON and BSON keys.
+var (
+	AttackingCounters = []string{"point", "caught", "short", "frame", "footing", "landed", "badPass", "dropPass"}
+	DefendingCounters = []string{"first", "second", "drop", "gap", "dig"}
+)
+
 type Attacking struct {
 	Point    int `json:"point" bson:"point"`
 	Caught   int `json:"caught" bson:"caught"`
@@ -67,3 +75,55 @@ func max(a, b int) int {
 	}
 	return b
 }
+
+// Add adds the counters of other to the attacking counters.
+func (a *Attacking) Add(other Attacking) {
+	a.Point += other.Point
+	a.Caught += other.Caught
+	a.Short += other.Short
+	a.Frame += other.Frame
+	a.Footing += other.Footing
+	a.Landed += other.Landed
+	a.BadPass += other.BadPass
+	a.DropPass += other.DropPass
+}
+
+// Add adds the counters of other to the defending counters.
+func (d *Defending) Add(other Defending) {
+	d.FirstLine += other.FirstLine
+	d.SecondLine += other.SecondLine
+	d.Drop += other.Drop
+	d.Gap += other.Gap
+	d.Dig += other.Dig
+}
+
+// Counters returns the attacking and defending counters of the player.
+func (p *Player) Counters() Counters {
+	return Counters{Attacking: p.Attacking, Defending: p.Defending}
+}
+
+// SetCounter sets the counter with the given name in AttackingCounters or
+// DefendingCounters, reporting false if there is no such counter.
+func (p *Player) SetCounter(name string, value int) bool {
+	counters := map[string]*int{
+		"point":    &p.Attacking.Point,
+		"caught":   &p.Attacking.Caught,
+		"short":    &p.Attacking.Short,
+		"frame":    &p.Attacking.Frame,
+		"footing":  &p.Attacking.Footing,
+		"landed":   &p.Attacking.Landed,
+		"badPass":  &p.Attacking.BadPass,
+		"dropPass": &p.Attacking.DropPass,
+		"first":    &p.Defending.FirstLine,
+		"second":   &p.Defending.SecondLine,
+		"drop":     &p.Defending.Drop,
+		"gap":      &p.Defending.Gap,
+		"dig":      &p.Defending.Dig,
+	}
+
+	counter, ok := counters[name]
+	if ok {
+		*counter = value
+	}
+	return ok
+}
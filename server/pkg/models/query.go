@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Query is a saved selection of matches and players that graphs can plot
+// match by match.
+type Query struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Name        string             `json:"name" bson:"name"`
+	Description string             `json:"description,omitempty" bson:"description,omitempty"`
+	Filter      StatsFilter        `json:"filter" bson:"filter"`
+	Players     []string           `json:"players,omitempty" bson:"players"` // Players totalled, the whole team if empty
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// CollectionName implements MongoModel.
+func (db *Query) CollectionName() string {
+	return "Queries"
+}
+
+// GetID implements DatabaseEntity.
+func (db *Query) GetID() primitive.ObjectID {
+	return db.ID
+}
+
+// SetID implements DatabaseEntity.
+func (db *Query) SetID(id primitive.ObjectID) {
+	db.ID = id
+}
+
+// New implements DatabaseEntity.
+func (db *Query) New() DatabaseEntity {
+	return &Query{}
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Share is a public read-only link to the score and statistics of a match.
+// The link holds a signed token naming the share, which stops working once
+// the share expires or is revoked.
+type Share struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Match       primitive.ObjectID `json:"match" bson:"match"`
+	Label       string             `json:"label,omitempty" bson:"label,omitempty"`
+	HidePlayers bool               `json:"hide_players" bson:"hide_players"` // Replace player names by numbers
+	ExpiresAt   time.Time          `json:"expires_at" bson:"expires_at"`
+	RevokedAt   *time.Time         `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	CreatedBy   string             `json:"created_by" bson:"created_by"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// ShareRequest is the payload used to create a share of a match.
+type ShareRequest struct {
+	Label       string `json:"label,omitempty"`
+	HidePlayers bool   `json:"hide_players"`
+	ExpiresIn   int    `json:"expires_in"` // Hours, 24 if not given
+}
+
+// CreatedShare is a new share with its token, which is only returned once.
+type CreatedShare struct {
+	*Share
+	Token string `json:"token"`
+	Path  string `json:"path"` // Path of the public scoreboard
+}
+
+// ShareClaims are the claims of a share token.
+type ShareClaims struct {
+	Share string `json:"share"`
+	Match string `json:"match"`
+	jwt.RegisteredClaims
+}
+
+// Scoreboard is the public view of a match.
+type Scoreboard struct {
+	Name        string           `json:"name"`
+	Competition string           `json:"competition,omitempty"`
+	Season      string           `json:"season,omitempty"`
+	HomeTeam    string           `json:"home_team,omitempty"`
+	AwayTeam    string           `json:"away_team,omitempty"`
+	HomeScore   int              `json:"home_score"`
+	AwayScore   int              `json:"away_score"`
+	Status      string           `json:"status,omitempty"`
+	Forfeit     string           `json:"forfeit,omitempty"`
+	Score       *LiveScoreUpdate `json:"score"` // Points tracked in each third
+	ExpiresAt   time.Time        `json:"expires_at"`
+}
+
+// CollectionName implements MongoModel.
+func (db *Share) CollectionName() string {
+	return "Shares"
+}
+
+// GetID implements DatabaseEntity.
+func (db *Share) GetID() primitive.ObjectID {
+	return db.ID
+}
+
+// SetID implements DatabaseEntity.
+func (db *Share) SetID(id primitive.ObjectID) {
+	db.ID = id
+}
+
+// New implements DatabaseEntity.
+func (db *Share) New() DatabaseEntity {
+	return &Share{}
+}
+
+// Active reports whether the share can still be used.
+func (db *Share) Active(now time.Time) bool {
+	return db.RevokedAt == nil && now.Before(db.ExpiresAt)
+}
@@ -0,0 +1,203 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	StatsSourceCounters = "counters"
+	StatsSourceEvents   = "events"
+)
+
+type Counters struct {
+	Attacking Attacking `json:"attacking" bson:"attacking"`
+	Defending Defending `json:"defending" bson:"defending"`
+}
+
+type PlayerStats struct {
+	Name    string               `json:"name"`
+	Totals  Counters             `json:"totals"`
+	Metrics map[string]float64   `json:"metrics"`
+	Custom  []*MetricValue       `json:"custom_metrics,omitempty"`
+	Periods map[string]*Counters `json:"periods"`
+}
+
+type MatchStats struct {
+	Match   primitive.ObjectID   `json:"match"`
+	Name    string               `json:"name"`
+	Source  string               `json:"source"` // counters or events, whichever the totals came from
+	Players []*PlayerStats       `json:"players"`
+	Periods map[string]*Counters `json:"periods"`
+	Totals  Counters             `json:"totals"`
+	Metrics map[string]float64   `json:"metrics"`
+	Custom  []*MetricValue       `json:"custom_metrics,omitempty"`
+}
+
+// StatsFilter selects the matches statistics are aggregated over.
+type StatsFilter struct {
+	From        time.Time `json:"from,omitempty" form:"from" time_format:"2006-01-02"`
+	To          time.Time `json:"to,omitempty" form:"to" time_format:"2006-01-02"`
+	Season      string    `json:"season,omitempty" form:"season"`
+	Competition string    `json:"competition,omitempty" form:"competition"`
+	Team        string    `json:"team,omitempty" form:"team"`         // Team of the tracked players
+	Opponent    string    `json:"opponent,omitempty" form:"opponent"` // Team on the side opposing the tracked players
+	Matches     []string  `json:"matches,omitempty" form:"match"`     // Match IDs
+}
+
+// IsEmpty reports whether the filter selects every match.
+func (f StatsFilter) IsEmpty() bool {
+	return f.From.IsZero() && f.To.IsZero() && f.Season == "" && f.Competition == "" && f.Team == "" && f.Opponent == "" && len(f.Matches) == 0
+}
+
+// PlayerTotals are the counters of a player summed over a set of matches.
+type PlayerTotals struct {
+	Player   string `json:"player" bson:"_id"`
+	Matches  int    `json:"matches" bson:"matches"`
+	Periods  int    `json:"periods" bson:"periods"`
+	Counters `bson:",inline"`
+}
+
+type CareerStats struct {
+	Player    string             `json:"player"`
+	Filter    StatsFilter        `json:"filter"`
+	Matches   int                `json:"matches"`
+	Periods   int                `json:"periods"`
+	Totals    Counters           `json:"totals"`
+	Metrics   map[string]float64 `json:"metrics"`
+	Custom    []*MetricValue     `json:"custom_metrics,omitempty"`
+	PerMatch  map[string]float64 `json:"per_match"`
+	PerPeriod map[string]float64 `json:"per_period"`
+}
+
+// Add adds the attacking and defending counters of other.
+func (c *Counters) Add(other Counters) {
+	c.Attacking.Add(other.Attacking)
+	c.Defending.Add(other.Defending)
+}
+
+// Values returns every counter keyed by its name in AttackingCounters and DefendingCounters.
+func (c Counters) Values() map[string]float64 {
+	return map[string]float64{
+		"point":    float64(c.Attacking.Point),
+		"caught":   float64(c.Attacking.Caught),
+		"short":    float64(c.Attacking.Short),
+		"frame":    float64(c.Attacking.Frame),
+		"footing":  float64(c.Attacking.Footing),
+		"landed":   float64(c.Attacking.Landed),
+		"badPass":  float64(c.Attacking.BadPass),
+		"dropPass": float64(c.Attacking.DropPass),
+		"first":    float64(c.Defending.FirstLine),
+		"second":   float64(c.Defending.SecondLine),
+		"drop":     float64(c.Defending.Drop),
+		"gap":      float64(c.Defending.Gap),
+		"dig":      float64(c.Defending.Dig),
+	}
+}
+
+// Average divides every counter by count, returning zeros when count is zero.
+func (c Counters) Average(count int) map[string]float64 {
+	values := c.Values()
+	for name, value := range values {
+		if count == 0 {
+			values[name] = 0
+		} else {
+			values[name] = value / float64(count)
+		}
+	}
+	return values
+}
+
+const (
+	LeaderboardTotal   = "total"
+	LeaderboardAverage = "average"
+)
+
+type LeaderboardEntry struct {
+	Rank    int     `json:"rank"` // Shared by players with equal values, who are listed by name
+	Player  string  `json:"player"`
+	Matches int     `json:"matches"`
+	Value   float64 `json:"value"`
+}
+
+type Leaderboard struct {
+	Metric     string              `json:"metric"`
+	Mode       string              `json:"mode"`
+	Scope      string              `json:"scope"`
+	MinMatches int                 `json:"min_matches"`
+	Page       int                 `json:"page"`
+	Limit      int                 `json:"limit"`
+	Total      int                 `json:"total"`
+	Entries    []*LeaderboardEntry `json:"entries"`
+}
+
+type ComparisonSubject struct {
+	ID      string `json:"id"` // Player name or match ID
+	Label   string `json:"label"`
+	Matches int    `json:"matches"`
+}
+
+// Difference compares a value against the value of the first subject.
+// Percent is omitted when the first subject's value is 0.
+type Difference struct {
+	Absolute float64  `json:"absolute"`
+	Percent  *float64 `json:"percent,omitempty"`
+}
+
+// ComparisonRow holds one counter or metric for every subject side by side.
+// A value is null when the metric cannot be computed for that subject and
+// the difference of the first subject is always null.
+type ComparisonRow struct {
+	Metric      string        `json:"metric"`
+	Values      []*float64    `json:"values"`
+	Differences []*Difference `json:"differences"`
+}
+
+type Comparison struct {
+	Kind     string              `json:"kind"`
+	Mode     string              `json:"mode,omitempty"`
+	Subjects []ComparisonSubject `json:"subjects"`
+	Rows     []*ComparisonRow    `json:"rows"`
+}
+
+// MatchTotals are counters summed over a single match.
+type MatchTotals struct {
+	Match     primitive.ObjectID `json:"match" bson:"_id"`
+	Name      string             `json:"name" bson:"name"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	Counters  `bson:",inline"`
+}
+
+const (
+	TrendImprovement = "improvement"
+	TrendDecline     = "decline"
+)
+
+type TrendPoint struct {
+	Match          primitive.ObjectID `json:"match"`
+	Name           string             `json:"name"`
+	Date           time.Time          `json:"date"`
+	Value          *float64           `json:"value"`
+	RollingAverage *float64           `json:"rolling_average"`
+	Flag           string             `json:"flag,omitempty"`
+}
+
+type Trend struct {
+	Subject   string        `json:"subject"`
+	Metric    string        `json:"metric"`
+	Window    int           `json:"window"`
+	Threshold float64       `json:"threshold"`
+	Baseline  float64       `json:"baseline"`
+	StdDev    float64       `json:"std_dev"`
+	Points    []*TrendPoint `json:"points"`
+	Series    SeriesData    `json:"series"`
+}
+
+// PeriodScore is the score of a third, from the points recorded for the
+// tracked players and the points conceded to the opponent.
+type PeriodScore struct {
+	Period   string `json:"period"`
+	Scored   int    `json:"scored"`
+	Conceded int    `json:"conceded"`
+}
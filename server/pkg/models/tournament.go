@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	TournamentStatusPools    = "pools"
+	TournamentStatusKnockout = "knockout"
+	TournamentStatusFinished = "finished"
+)
+
+type Tournament struct {
+	ID                primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Name              string             `json:"name" bson:"name"`
+	Competition       string             `json:"competition,omitempty" bson:"competition,omitempty"`
+	Season            string             `json:"season,omitempty" bson:"season,omitempty"`
+	Teams             []string           `json:"teams" bson:"teams"`
+	PoolCount         int                `json:"pool_count" bson:"pool_count"`
+	QualifiersPerPool int                `json:"qualifiers_per_pool" bson:"qualifiers_per_pool"`
+	Pools             []*Pool            `json:"pools" bson:"pools"`
+	Knockout          []*Round           `json:"knockout" bson:"knockout"`
+	Status            string             `json:"status" bson:"status"`
+	Winner            string             `json:"winner,omitempty" bson:"winner"` // Not omitted so that a corrected final clears it
+	CreatedAt         time.Time          `json:"created_at" bson:"created_at"`
+}
+
+type Pool struct {
+	Name     string     `json:"name" bson:"name"`
+	Teams    []string   `json:"teams" bson:"teams"`
+	Fixtures []*Fixture `json:"fixtures" bson:"fixtures"`
+}
+
+type Round struct {
+	Name     string     `json:"name" bson:"name"`
+	Fixtures []*Fixture `json:"fixtures" bson:"fixtures"`
+}
+
+type Fixture struct {
+	Match    primitive.ObjectID `json:"match,omitempty" bson:"match,omitempty"`
+	Round    int                `json:"round" bson:"round"`
+	HomeTeam string             `json:"home_team" bson:"home_team"`
+	AwayTeam string             `json:"away_team" bson:"away_team"`
+	Finished bool               `json:"finished" bson:"finished"`
+	Winner   string             `json:"winner,omitempty" bson:"winner"` // Not omitted so that a corrected result clears it
+}
+
+type PoolStandings struct {
+	Pool      string      `json:"pool"`
+	Standings []*Standing `json:"standings"`
+}
+
+// CollectionName implements MongoModel.
+func (db *Tournament) CollectionName() string {
+	return "Tournaments"
+}
+
+// GetID implements DatabaseEntity.
+func (db *Tournament) GetID() primitive.ObjectID {
+	return db.ID
+}
+
+// SetID implements DatabaseEntity.
+func (db *Tournament) SetID(id primitive.ObjectID) {
+	db.ID = id
+}
+
+// New implements DatabaseEntity.
+func (db *Tournament) New() DatabaseEntity {
+	return &Tournament{}
+}
+
+// Ready reports whether both teams of the fixture are known and no match has been created for it yet.
+func (f *Fixture) Ready() bool {
+	return f.HomeTeam != "" && f.AwayTeam != "" && f.Match.IsZero() && !f.Finished
+}
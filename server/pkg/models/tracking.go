@@ -0,0 +1,63 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Roles of the trackers sharing a match, each recording one side of the play.
+const (
+	TrackerAttacking = "attacking"
+	TrackerDefending = "defending"
+)
+
+// TrackerRoles lists every tracker role.
+var TrackerRoles = []string{TrackerAttacking, TrackerDefending}
+
+// TrackerActions lists the player action types each role may record, as
+// accepted by Player.AddAction.
+var TrackerActions = map[string][]string{
+	TrackerAttacking: {"point", "caught", "short", "frame", "footing", "landed", "bad pass", "drop pass"},
+	TrackerDefending: {"1st", "2nd", "drop", "gap", "dig"},
+}
+
+// Types of the messages of a tracking session besides the live updates of
+// the match, which are forwarded with their own types.
+const (
+	TrackingAction   = "action"   // Sent by a tracker to record an action
+	TrackingAck      = "ack"      // Sent to a tracker once its action is recorded
+	TrackingError    = "error"    // Sent to a tracker whose message was refused
+	TrackingPresence = "presence" // Live update of the trackers in the session
+)
+
+// Tracker is a user recording a match in a tracking session.
+type Tracker struct {
+	Name     string    `json:"name"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// TrackingPresenceUpdate lists the trackers connected to a match.
+type TrackingPresenceUpdate struct {
+	Trackers []*Tracker `json:"trackers"`
+}
+
+// TrackingRequest is a message sent by a tracker. Ref is chosen by the
+// tracker and echoed in the reply so that it can match the two.
+type TrackingRequest struct {
+	Type   string       `json:"type"`
+	Ref    string       `json:"ref,omitempty"`
+	Period string       `json:"period"`
+	Player string       `json:"player"`
+	Action PlayerAction `json:"action"`
+}
+
+// TrackingMessage is a message sent to a tracker, either a reply to one of
+// its requests or a live update of the match.
+type TrackingMessage struct {
+	Type    string          `json:"type"`
+	ID      string          `json:"id,omitempty"` // ID of the live update
+	Ref     string          `json:"ref,omitempty"`
+	Message string          `json:"message,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+}
@@ -2,21 +2,83 @@
 package models
 
 import (
+	"time"
+
 	"github.com/golang-jwt/jwt/v4"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Roles of the users, each allowed everything the roles below it are.
+const (
+	RoleAdmin   = "admin"   // Manages users and deletes anything
+	RoleCoach   = "coach"   // Manages matches, rosters, competitions and settings
+	RoleTracker = "tracker" // Records actions during matches
+	RoleViewer  = "viewer"  // Reads only
+)
+
+// Roles lists every role, from the most to the least privileged. Users
+// without a known role, such as those created before roles existed, are
+// viewers.
+var Roles = []string{RoleAdmin, RoleCoach, RoleTracker, RoleViewer}
+
+// Permissions a route can require, each granted to a role and those above it.
+const (
+	PermissionRead   = "read"   // Viewers
+	PermissionTrack  = "track"  // Trackers
+	PermissionManage = "manage" // Coaches
+	PermissionAdmin  = "admin"  // Admins
+)
+
+var permissionRoles = map[string]string{
+	PermissionRead:   RoleViewer,
+	PermissionTrack:  RoleTracker,
+	PermissionManage: RoleCoach,
+	PermissionAdmin:  RoleAdmin,
+}
+
+// MinPasswordLength is the shortest password accepted for a user.
+const MinPasswordLength = 8
+
 // LoginRequest represents the payload for the login request
 type User struct {
 	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
 	Name         string             `bson:"name" json:"name"`
-	Password     string             `json:"password"`
+	Password     string             `json:"password,omitempty"` // bcrypt hash, never returned by the API
 	KeepLoggedIn bool               `json:"keep_logged_in"`
+	Role         string             `bson:"role,omitempty" json:"role"`
+	Disabled     bool               `bson:"disabled" json:"disabled"` // Not omitted so that enabling a user clears it
+	CreatedAt    time.Time          `bson:"created_at,omitempty" json:"created_at,omitempty"`
+}
+
+// UserRequest is the payload used to create a user.
+type UserRequest struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+	Role     string `json:"role,omitempty"` // viewer if not given
+}
+
+// RoleRequest is the payload used to change the role of a user.
+type RoleRequest struct {
+	Role string `json:"role"`
+}
+
+// PasswordRequest is the payload used to reset the password of a user.
+type PasswordRequest struct {
+	Password string `json:"password"`
+}
+
+// SetupRequest is the payload used to create the first admin with the
+// one-time setup token.
+type SetupRequest struct {
+	Token    string `json:"token"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
 }
 
 // Claims struct to hold JWT claims
 type Claims struct {
 	Username string `json:"username"`
+	Role     string `json:"role"`
 	jwt.RegisteredClaims
 }
 
@@ -39,3 +101,30 @@ func (db *User) SetID(id primitive.ObjectID) {
 func (db *User) New() DatabaseEntity {
 	return &User{}
 }
+
+// EffectiveRole returns the role of the user, which is viewer if it is unknown.
+func (db *User) EffectiveRole() string {
+	for _, role := range Roles {
+		if role == db.Role {
+			return role
+		}
+	}
+	return RoleViewer
+}
+
+// RoleCan reports whether a role is granted a permission.
+func RoleCan(role string, permission string) bool {
+	required, ok := permissionRoles[permission]
+	if !ok {
+		return false
+	}
+	for _, known := range Roles {
+		if known == role {
+			return true
+		}
+		if known == required {
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	WebhookMatchCreated  = "match.created"
+	WebhookPeriodStarted = "period.started"
+	WebhookPeriodEnded   = "period.ended"
+	WebhookPointRecorded = "point.recorded"
+	WebhookMatchFinished = "match.finished"
+)
+
+// WebhookEvents lists every event a webhook can subscribe to.
+var WebhookEvents = []string{WebhookMatchCreated, WebhookPeriodStarted, WebhookPeriodEnded, WebhookPointRecorded, WebhookMatchFinished}
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook is a URL that receives a signed JSON POST for each event it
+// subscribes to.
+type Webhook struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Name      string             `json:"name" bson:"name"`
+	URL       string             `json:"url" bson:"url"`
+	Secret    string             `json:"-" bson:"secret"`      // Key of the HMAC-SHA256 signature, only returned on creation
+	Events    []string           `json:"events" bson:"events"` // Every event if empty
+	Disabled  bool               `json:"disabled" bson:"disabled"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// WebhookWithSecret is a webhook along with its signing secret. The secret is
+// accepted when a webhook is created or updated, generated if not given on
+// creation, and only returned when the webhook is created.
+type WebhookWithSecret struct {
+	*Webhook
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebhookPayload is the body POSTed to a webhook. The match and the entry of
+// the action log that caused the event are included when there is one.
+type WebhookPayload struct {
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Match     *Match    `json:"match,omitempty"`
+	Entry     *Event    `json:"entry,omitempty"`
+}
+
+// WebhookDelivery records the delivery of one event to one webhook and every
+// attempt made to deliver it.
+type WebhookDelivery struct {
+	ID        primitive.ObjectID  `json:"id,omitempty" bson:"_id,omitempty"`
+	Webhook   primitive.ObjectID  `json:"webhook" bson:"webhook"`
+	Event     string              `json:"event" bson:"event"`
+	Payload   json.RawMessage     `json:"payload" bson:"payload" swaggertype:"object"`
+	Status    string              `json:"status" bson:"status"`
+	Attempts  []*DeliveryAttempt  `json:"attempts" bson:"attempts"`
+	ReplayOf  *primitive.ObjectID `json:"replay_of,omitempty" bson:"replay_of,omitempty"`
+	CreatedAt time.Time           `json:"created_at" bson:"created_at"`
+}
+
+type DeliveryAttempt struct {
+	At         time.Time `json:"at" bson:"at"`
+	StatusCode int       `json:"status_code,omitempty" bson:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty" bson:"error,omitempty"`
+	Duration   int64     `json:"duration_ms" bson:"duration_ms"`
+}
+
+// CollectionName implements MongoModel.
+func (db *Webhook) CollectionName() string {
+	return "Webhooks"
+}
+
+// GetID implements DatabaseEntity.
+func (db *Webhook) GetID() primitive.ObjectID {
+	return db.ID
+}
+
+// SetID implements DatabaseEntity.
+func (db *Webhook) SetID(id primitive.ObjectID) {
+	db.ID = id
+}
+
+// New implements DatabaseEntity.
+func (db *Webhook) New() DatabaseEntity {
+	return &Webhook{}
+}
+
+// Subscribes reports whether the webhook receives the event.
+func (db *Webhook) Subscribes(event string) bool {
+	if db.Disabled {
+		return false
+	}
+	if len(db.Events) == 0 {
+		return true
+	}
+	for _, subscribed := range db.Events {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// CollectionName implements MongoModel.
+func (db *WebhookDelivery) CollectionName() string {
+	return "WebhookDeliveries"
+}
+
+// GetID implements DatabaseEntity.
+func (db *WebhookDelivery) GetID() primitive.ObjectID {
+	return db.ID
+}
+
+// SetID implements DatabaseEntity.
+func (db *WebhookDelivery) SetID(id primitive.ObjectID) {
+	db.ID = id
+}
+
+// New implements DatabaseEntity.
+func (db *WebhookDelivery) New() DatabaseEntity {
+	return &WebhookDelivery{}
+}
@@ -0,0 +1,359 @@
+// Package parquet writes flat tables as Apache Parquet files for tools such as
+// pandas and DuckDB.
+//
+// Every column is optional, so any value may be null, and is written with
+// plain encoding in gzip-compressed version 1 data pages. Rows are buffered
+// into row groups of RowGroupSize rows, each written out as soon as it is
+// full, so only one row group is held in memory at a time.
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// RowGroupSize is the number of rows buffered before they are written out.
+const RowGroupSize = 10000
+
+const magic = "PAR1"
+
+type Type int
+
+const (
+	String    Type = iota // UTF-8 byte array
+	Int64                 // 64 bit signed integer
+	Double                // 64 bit float
+	Timestamp             // Milliseconds since the Unix epoch, in UTC
+)
+
+// Column describes a column of a table.
+type Column struct {
+	Name string
+	Type Type
+}
+
+// Physical types, converted types, encodings and codecs of the format.
+const (
+	physicalInt64     = 2
+	physicalDouble    = 5
+	physicalByteArray = 6
+
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+
+	repetitionOptional = 1
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	codecGzip = 2
+
+	pageData = 0
+)
+
+var ErrClosed = errors.New("parquet: writer is closed")
+
+type columnChunk struct {
+	offset           int64
+	values           int64
+	uncompressedSize int64
+	compressedSize   int64
+}
+
+type rowGroup struct {
+	rows    int64
+	size    int64
+	columns []columnChunk
+}
+
+// Writer writes rows to a Parquet file.
+type Writer struct {
+	w       io.Writer
+	columns []Column
+	offset  int64 // Bytes written so far
+	rows    int   // Rows in the current row group
+	defined [][]bool
+	values  []bytes.Buffer
+	groups  []rowGroup
+	closed  bool
+}
+
+// NewWriter returns a writer of a table with the given columns. Nothing is
+// written to w until the first row group is full or the writer is closed.
+func NewWriter(w io.Writer, columns []Column) *Writer {
+	return &Writer{
+		w:       w,
+		columns: columns,
+		defined: make([][]bool, len(columns)),
+		values:  make([]bytes.Buffer, len(columns)),
+	}
+}
+
+// Write adds a row holding a value per column. A value is nil for null, or a
+// string, int, int64, float64 or time.Time matching the column type.
+func (w *Writer) Write(row []interface{}) error {
+	if w.closed {
+		return ErrClosed
+	}
+	if len(row) != len(w.columns) {
+		return fmt.Errorf("parquet: row has %d values for %d columns", len(row), len(w.columns))
+	}
+
+	for i, value := range row {
+		if err := w.append(i, value); err != nil {
+			return err
+		}
+	}
+
+	w.rows++
+	if w.rows == RowGroupSize {
+		return w.flush()
+	}
+	return nil
+}
+
+// Close writes any buffered rows and the file footer. It does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	w.closed = true
+
+	if w.offset == 0 {
+		if err := w.write([]byte(magic)); err != nil {
+			return err
+		}
+	}
+
+	footer := w.footer()
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(footer)))
+	if err := w.write(footer); err != nil {
+		return err
+	}
+	if err := w.write(length[:]); err != nil {
+		return err
+	}
+	return w.write([]byte(magic))
+}
+
+func (w *Writer) append(i int, value interface{}) error {
+	column := w.columns[i]
+	if value == nil {
+		w.defined[i] = append(w.defined[i], false)
+		return nil
+	}
+
+	var buf [8]byte
+	values := &w.values[i]
+	switch v := value.(type) {
+	case string:
+		if column.Type != String {
+			return typeError(column, value)
+		}
+		binary.LittleEndian.PutUint32(buf[:4], uint32(len(v)))
+		values.Write(buf[:4])
+		values.WriteString(v)
+	case int:
+		if column.Type != Int64 {
+			return typeError(column, value)
+		}
+		binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		values.Write(buf[:])
+	case int64:
+		if column.Type != Int64 {
+			return typeError(column, value)
+		}
+		binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		values.Write(buf[:])
+	case float64:
+		if column.Type != Double {
+			return typeError(column, value)
+		}
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+		values.Write(buf[:])
+	case time.Time:
+		if column.Type != Timestamp {
+			return typeError(column, value)
+		}
+		binary.LittleEndian.PutUint64(buf[:], uint64(v.UnixMilli()))
+		values.Write(buf[:])
+	default:
+		return typeError(column, value)
+	}
+
+	w.defined[i] = append(w.defined[i], true)
+	return nil
+}
+
+func typeError(column Column, value interface{}) error {
+	return fmt.Errorf("parquet: cannot write %T to column %s", value, column.Name)
+}
+
+// flush writes the buffered rows as a row group with one data page per column.
+func (w *Writer) flush() error {
+	if w.rows == 0 {
+		return nil
+	}
+	if w.offset == 0 {
+		if err := w.write([]byte(magic)); err != nil {
+			return err
+		}
+	}
+
+	group := rowGroup{rows: int64(w.rows)}
+	for i := range w.columns {
+		var page bytes.Buffer
+		levels := definitionLevels(w.defined[i])
+		var length [4]byte
+		binary.LittleEndian.PutUint32(length[:], uint32(len(levels)))
+		page.Write(length[:])
+		page.Write(levels)
+		page.Write(w.values[i].Bytes())
+
+		var compressed bytes.Buffer
+		zw := gzip.NewWriter(&compressed)
+		zw.Write(page.Bytes())
+		if err := zw.Close(); err != nil {
+			return err
+		}
+
+		var header compact
+		header.structValue(func() {
+			header.i32(1, pageData)
+			header.i32(2, int32(page.Len()))
+			header.i32(3, int32(compressed.Len()))
+			header.structField(5, func() {
+				header.i32(1, int32(w.rows))
+				header.i32(2, encodingPlain)
+				header.i32(3, encodingRLE)
+				header.i32(4, encodingRLE)
+			})
+		})
+
+		chunk := columnChunk{
+			offset:           w.offset,
+			values:           int64(w.rows),
+			uncompressedSize: int64(header.Len() + page.Len()),
+			compressedSize:   int64(header.Len() + compressed.Len()),
+		}
+		if err := w.write(header.Bytes()); err != nil {
+			return err
+		}
+		if err := w.write(compressed.Bytes()); err != nil {
+			return err
+		}
+
+		group.size += chunk.uncompressedSize
+		group.columns = append(group.columns, chunk)
+		w.defined[i] = w.defined[i][:0]
+		w.values[i].Reset()
+	}
+
+	w.groups = append(w.groups, group)
+	w.rows = 0
+	return nil
+}
+
+// definitionLevels encodes whether each value is set with the bit-packed form
+// of the RLE hybrid encoding, using a bit width of 1.
+func definitionLevels(defined []bool) []byte {
+	groups := (len(defined) + 7) / 8
+	var header [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(header[:], uint64(groups)<<1|1)
+
+	levels := make([]byte, n+groups)
+	copy(levels, header[:n])
+	for i, set := range defined {
+		if set {
+			levels[n+i/8] |= 1 << (i % 8)
+		}
+	}
+	return levels
+}
+
+// footer encodes the file metadata.
+func (w *Writer) footer() []byte {
+	var rows int64
+	for _, group := range w.groups {
+		rows += group.rows
+	}
+
+	var c compact
+	c.structValue(func() {
+		c.i32(1, 1)
+		c.list(2, thriftStruct, len(w.columns)+1)
+		c.structValue(func() {
+			c.string(4, "schema")
+			c.i32(5, int32(len(w.columns)))
+		})
+		for _, column := range w.columns {
+			column := column
+			c.structValue(func() {
+				c.i32(1, physicalType(column.Type))
+				c.i32(3, repetitionOptional)
+				c.string(4, column.Name)
+				switch column.Type {
+				case String:
+					c.i32(6, convertedUTF8)
+				case Timestamp:
+					c.i32(6, convertedTimestampMillis)
+				}
+			})
+		}
+		c.i64(3, rows)
+		c.list(4, thriftStruct, len(w.groups))
+		for _, group := range w.groups {
+			group := group
+			c.structValue(func() {
+				c.list(1, thriftStruct, len(group.columns))
+				for i, chunk := range group.columns {
+					column, chunk := w.columns[i], chunk
+					c.structValue(func() {
+						c.i64(2, chunk.offset)
+						c.structField(3, func() {
+							c.i32(1, physicalType(column.Type))
+							c.i32List(2, encodingPlain, encodingRLE)
+							c.stringList(3, column.Name)
+							c.i32(4, codecGzip)
+							c.i64(5, chunk.values)
+							c.i64(6, chunk.uncompressedSize)
+							c.i64(7, chunk.compressedSize)
+							c.i64(9, chunk.offset)
+						})
+					})
+				}
+				c.i64(2, group.size)
+				c.i64(3, group.rows)
+			})
+		}
+		c.string(6, "Tchoukball Tracker")
+	})
+	return c.Bytes()
+}
+
+func physicalType(t Type) int32 {
+	switch t {
+	case Int64, Timestamp:
+		return physicalInt64
+	case Double:
+		return physicalDouble
+	}
+	return physicalByteArray
+}
+
+func (w *Writer) write(p []byte) error {
+	n, err := w.w.Write(p)
+	w.offset += int64(n)
+	return err
+}
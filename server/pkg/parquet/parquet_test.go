@@ -0,0 +1,235 @@
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// readFile decodes a file written by Writer back into its rows, checking the
+// footer and page headers against the data they describe.
+func readFile(t *testing.T, data []byte, columns []Column) [][]interface{} {
+	t.Helper()
+	if len(data) < 12 || string(data[:4]) != magic || string(data[len(data)-4:]) != magic {
+		t.Fatalf("file of %d bytes does not start and end with %s", len(data), magic)
+	}
+	length := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	if length > len(data)-12 {
+		t.Fatalf("footer of %d bytes does not fit in the file", length)
+	}
+	meta, n := decodeStruct(t, data[len(data)-8-length:len(data)-8])
+	if n != length {
+		t.Fatalf("footer holds %d bytes, %d decoded", length, n)
+	}
+
+	if meta[1] != int64(1) {
+		t.Errorf("version = %v, want 1", meta[1])
+	}
+	schema := meta[2].([]interface{})
+	if want := (fields{4: "schema", 5: int64(len(columns))}); !reflect.DeepEqual(schema[0], want) {
+		t.Errorf("schema root = %v, want %v", schema[0], want)
+	}
+	for i, column := range columns {
+		want := fields{1: int64(physicalType(column.Type)), 3: int64(repetitionOptional), 4: column.Name}
+		switch column.Type {
+		case String:
+			want[6] = int64(convertedUTF8)
+		case Timestamp:
+			want[6] = int64(convertedTimestampMillis)
+		}
+		if !reflect.DeepEqual(schema[i+1], want) {
+			t.Errorf("schema of column %s = %v, want %v", column.Name, schema[i+1], want)
+		}
+	}
+
+	var rows [][]interface{}
+	for _, g := range meta[4].([]interface{}) {
+		group := g.(fields)
+		groupRows := group[3].(int64)
+		chunks := group[1].([]interface{})
+		if len(chunks) != len(columns) {
+			t.Fatalf("row group has %d column chunks, want %d", len(chunks), len(columns))
+		}
+
+		values := make([][]interface{}, len(columns))
+		var size int64
+		for i, c := range chunks {
+			chunk := c.(fields)
+			offset := chunk[2].(int64)
+			meta := chunk[3].(fields)
+			if meta[9] != offset || meta[1] != int64(physicalType(columns[i].Type)) || meta[4] != int64(codecGzip) || meta[5] != groupRows {
+				t.Errorf("metadata of column %s at %d = %v", columns[i].Name, offset, meta)
+			}
+			if name := meta[3].([]interface{}); len(name) != 1 || name[0] != columns[i].Name {
+				t.Errorf("path of column %s = %v", columns[i].Name, name)
+			}
+
+			header, n := decodeStruct(t, data[offset:])
+			uncompressed, compressed := header[2].(int64), header[3].(int64)
+			if header[1] != int64(pageData) || header[5].(fields)[1] != groupRows {
+				t.Errorf("page header of column %s = %v", columns[i].Name, header)
+			}
+			if meta[6] != int64(n)+uncompressed || meta[7] != int64(n)+compressed {
+				t.Errorf("column %s sizes = %v, %v, want %d, %d", columns[i].Name, meta[6], meta[7], int64(n)+uncompressed, int64(n)+compressed)
+			}
+			size += meta[6].(int64)
+
+			start := offset + int64(n)
+			zr, err := gzip.NewReader(bytes.NewReader(data[start : start+compressed]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			page, err := io.ReadAll(zr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if int64(len(page)) != uncompressed {
+				t.Errorf("page of column %s holds %d bytes, header says %d", columns[i].Name, len(page), uncompressed)
+			}
+			values[i] = readPage(t, page, columns[i].Type, int(groupRows))
+		}
+		if group[2] != size {
+			t.Errorf("row group size = %v, want %d", group[2], size)
+		}
+
+		for row := 0; row < int(groupRows); row++ {
+			rows = append(rows, make([]interface{}, len(columns)))
+			for i := range columns {
+				rows[len(rows)-1][i] = values[i][row]
+			}
+		}
+	}
+
+	if meta[3] != int64(len(rows)) {
+		t.Errorf("footer counts %v rows, %d read", meta[3], len(rows))
+	}
+	return rows
+}
+
+// readPage decodes the definition levels and plain values of a data page.
+func readPage(t *testing.T, page []byte, columnType Type, count int) []interface{} {
+	t.Helper()
+	length := int(binary.LittleEndian.Uint32(page))
+	levels := page[4 : 4+length]
+	header, n := binary.Uvarint(levels)
+	if header&1 != 1 || int(header>>1) != (count+7)/8 || n+int(header>>1) != length {
+		t.Fatalf("definition levels %x for %d values", levels, count)
+	}
+
+	data := page[4+length:]
+	values := make([]interface{}, count)
+	for i := range values {
+		if levels[n+i/8]&(1<<(i%8)) == 0 {
+			continue
+		}
+		switch columnType {
+		case String:
+			size := int(binary.LittleEndian.Uint32(data))
+			values[i], data = string(data[4:4+size]), data[4+size:]
+		case Int64:
+			values[i], data = int64(binary.LittleEndian.Uint64(data)), data[8:]
+		case Double:
+			values[i], data = math.Float64frombits(binary.LittleEndian.Uint64(data)), data[8:]
+		case Timestamp:
+			values[i], data = time.UnixMilli(int64(binary.LittleEndian.Uint64(data))).UTC(), data[8:]
+		}
+	}
+	if len(data) != 0 {
+		t.Errorf("%d bytes left after %d values", len(data), count)
+	}
+	return values
+}
+
+func TestWriter(t *testing.T) {
+	columns := []Column{
+		{Name: "player", Type: String},
+		{Name: "points", Type: Int64},
+		{Name: "ratio", Type: Double},
+		{Name: "at", Type: Timestamp},
+	}
+	at := time.Date(2024, 5, 1, 18, 30, 0, 0, time.UTC)
+
+	many := make([][]interface{}, RowGroupSize+3)
+	for i := range many {
+		many[i] = []interface{}{"Alex", int64(i), float64(i) / 2, at.Add(time.Duration(i) * time.Millisecond)}
+		if i%3 == 0 {
+			many[i][i%4] = nil
+		}
+	}
+
+	tests := []struct {
+		name   string
+		rows   [][]interface{}
+		groups int
+	}{
+		{
+			name: "nulls",
+			rows: [][]interface{}{
+				{"Alex", int64(3), 0.5, at},
+				{nil, nil, nil, nil},
+				{"", int64(-1), math.Inf(1), nil},
+				{"Zoë (captain)", nil, -2.25, at.Add(time.Second)},
+			},
+			groups: 1,
+		},
+		{name: "several row groups", rows: many, groups: 2},
+		{name: "empty table", rows: nil, groups: 0},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		w := NewWriter(&buf, columns)
+		for _, row := range test.rows {
+			if err := w.Write(row); err != nil {
+				t.Fatalf("%s: %v", test.name, err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		if groups := len(w.groups); groups != test.groups {
+			t.Errorf("%s: %d row groups, want %d", test.name, groups, test.groups)
+		}
+		rows := readFile(t, buf.Bytes(), columns)
+		if len(rows) != len(test.rows) {
+			t.Fatalf("%s: read %d rows, want %d", test.name, len(rows), len(test.rows))
+		}
+		for i := range rows {
+			if !reflect.DeepEqual(rows[i], test.rows[i]) {
+				t.Errorf("%s: row %d = %v, want %v", test.name, i, rows[i], test.rows[i])
+				break
+			}
+		}
+	}
+}
+
+func TestWriterErrors(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, []Column{{Name: "points", Type: Int64}})
+
+	if err := w.Write([]interface{}{"3"}); err == nil {
+		t.Error("writing a string to an Int64 column succeeded")
+	}
+	if err := w.Write([]interface{}{3, 4}); err == nil {
+		t.Error("writing a row of two values to one column succeeded")
+	}
+	if err := w.Write([]interface{}{3}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("%d bytes written before the row group is full", buf.Len())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write([]interface{}{4}); !errors.Is(err, ErrClosed) {
+		t.Errorf("write after close = %v, want %v", err, ErrClosed)
+	}
+}
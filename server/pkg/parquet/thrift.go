@@ -0,0 +1,100 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Field types of the Thrift compact protocol.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// compact encodes Thrift structs with the compact protocol, which Parquet
+// uses for its page headers and file metadata. Fields must be written in
+// increasing order of their ID within each struct.
+type compact struct {
+	bytes.Buffer
+	last int16 // ID of the last field written in the current struct
+}
+
+func (c *compact) varint(v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	c.Write(buf[:binary.PutUvarint(buf[:], v)])
+}
+
+func (c *compact) zigzag(v int64) {
+	c.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (c *compact) field(id int16, fieldType byte) {
+	if delta := id - c.last; delta > 0 && delta <= 15 {
+		c.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		c.WriteByte(fieldType)
+		c.zigzag(int64(id))
+	}
+	c.last = id
+}
+
+func (c *compact) i32(id int16, v int32) {
+	c.field(id, thriftI32)
+	c.zigzag(int64(v))
+}
+
+func (c *compact) i64(id int16, v int64) {
+	c.field(id, thriftI64)
+	c.zigzag(v)
+}
+
+func (c *compact) string(id int16, s string) {
+	c.field(id, thriftBinary)
+	c.varint(uint64(len(s)))
+	c.WriteString(s)
+}
+
+// structField writes a nested struct whose fields are written by fn.
+func (c *compact) structField(id int16, fn func()) {
+	c.field(id, thriftStruct)
+	c.structValue(fn)
+}
+
+// structValue writes the fields of a struct followed by its stop byte. It is
+// used directly for the top level struct and for struct list elements.
+func (c *compact) structValue(fn func()) {
+	last := c.last
+	c.last = 0
+	fn()
+	c.WriteByte(0)
+	c.last = last
+}
+
+// list writes the header of a list of size elements, which must follow.
+func (c *compact) list(id int16, elementType byte, size int) {
+	c.field(id, thriftList)
+	if size < 15 {
+		c.WriteByte(byte(size)<<4 | elementType)
+	} else {
+		c.WriteByte(0xf0 | elementType)
+		c.varint(uint64(size))
+	}
+}
+
+func (c *compact) i32List(id int16, values ...int32) {
+	c.list(id, thriftI32, len(values))
+	for _, v := range values {
+		c.zigzag(int64(v))
+	}
+}
+
+func (c *compact) stringList(id int16, values ...string) {
+	c.list(id, thriftBinary, len(values))
+	for _, v := range values {
+		c.varint(uint64(len(v)))
+		c.WriteString(v)
+	}
+}
@@ -0,0 +1,176 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// fields holds a decoded Thrift struct by field ID. Integers are decoded to
+// int64, binaries to string, lists to []interface{} and structs to fields.
+type fields map[int16]interface{}
+
+// decoder reads the Thrift compact protocol back, so that the tests can check
+// what compact writes without a Thrift library.
+type decoder struct {
+	t    *testing.T
+	data []byte
+	pos  int
+}
+
+// decodeStruct decodes the struct at the start of data, returning it with the
+// number of bytes it takes.
+func decodeStruct(t *testing.T, data []byte) (fields, int) {
+	t.Helper()
+	d := &decoder{t: t, data: data}
+	return d.structValue(), d.pos
+}
+
+func (d *decoder) byte() byte {
+	if d.pos >= len(d.data) {
+		d.t.Fatalf("compact: unexpected end of data at byte %d", d.pos)
+	}
+	d.pos++
+	return d.data[d.pos-1]
+}
+
+func (d *decoder) varint() uint64 {
+	v, n := binary.Uvarint(d.data[d.pos:])
+	if n <= 0 {
+		d.t.Fatalf("compact: bad varint at byte %d", d.pos)
+	}
+	d.pos += n
+	return v
+}
+
+func (d *decoder) zigzag() int64 {
+	v := d.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (d *decoder) value(fieldType byte) interface{} {
+	switch fieldType {
+	case thriftI32, thriftI64:
+		return d.zigzag()
+	case thriftBinary:
+		n := int(d.varint())
+		if d.pos+n > len(d.data) {
+			d.t.Fatalf("compact: binary of %d bytes at byte %d overruns the data", n, d.pos)
+		}
+		d.pos += n
+		return string(d.data[d.pos-n : d.pos])
+	case thriftList:
+		header := d.byte()
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(d.varint())
+		}
+		list := []interface{}{}
+		for i := 0; i < size; i++ {
+			list = append(list, d.value(header&0x0f))
+		}
+		return list
+	case thriftStruct:
+		return d.structValue()
+	}
+	d.t.Fatalf("compact: unknown field type %d at byte %d", fieldType, d.pos)
+	return nil
+}
+
+func (d *decoder) structValue() fields {
+	s := fields{}
+	var last int16
+	for {
+		header := d.byte()
+		if header == 0 {
+			return s
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(d.zigzag())
+		}
+		last = id
+		s[id] = d.value(header & 0x0f)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	many := make([]int32, 15)
+	manyDecoded := make([]interface{}, 15)
+	for i := range many {
+		many[i] = int32(i - 10)
+		manyDecoded[i] = int64(i - 10)
+	}
+
+	tests := []struct {
+		name  string
+		write func(c *compact)
+		want  fields
+	}{
+		{
+			name: "short field deltas",
+			write: func(c *compact) {
+				c.i32(1, -1)
+				c.i64(2, 1<<40)
+				c.string(4, "name")
+			},
+			want: fields{1: int64(-1), 2: int64(1 << 40), 4: "name"},
+		},
+		{
+			name: "long field delta",
+			write: func(c *compact) {
+				c.i32(1, 7)
+				c.i32(20, 8)
+				c.i32(21, 9)
+			},
+			want: fields{1: int64(7), 20: int64(8), 21: int64(9)},
+		},
+		{
+			name: "nested struct restarts field IDs",
+			write: func(c *compact) {
+				c.i32(3, 1)
+				c.structField(5, func() {
+					c.i32(1, 2)
+					c.i32(2, 3)
+				})
+				c.i32(6, 4)
+			},
+			want: fields{3: int64(1), 5: fields{1: int64(2), 2: int64(3)}, 6: int64(4)},
+		},
+		{
+			name: "lists",
+			write: func(c *compact) {
+				c.i32List(1, 0, 3)
+				c.stringList(2, "a", "bc")
+				c.i32List(3, many...)
+				c.list(4, thriftStruct, 2)
+				c.structValue(func() { c.i32(1, 1) })
+				c.structValue(func() { c.string(2, "x") })
+			},
+			want: fields{
+				1: []interface{}{int64(0), int64(3)},
+				2: []interface{}{"a", "bc"},
+				3: manyDecoded,
+				4: []interface{}{fields{1: int64(1)}, fields{2: "x"}},
+			},
+		},
+		{
+			name:  "empty struct",
+			write: func(c *compact) {},
+			want:  fields{},
+		},
+	}
+
+	for _, test := range tests {
+		var c compact
+		c.structValue(func() { test.write(&c) })
+
+		got, n := decodeStruct(t, c.Bytes())
+		if n != c.Len() {
+			t.Errorf("%s: decoded %d of %d bytes", test.name, n, c.Len())
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: decoded %v, want %v", test.name, got, test.want)
+		}
+	}
+}
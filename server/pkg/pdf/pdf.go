@@ -0,0 +1,210 @@
+// Package pdf writes printable PDF documents of vector shapes and text set in
+// the standard Helvetica fonts. Positions are in points from the top left
+// corner of the page.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image/color"
+	"io"
+	"sort"
+)
+
+// A4 page size in points.
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// Point is a position on a page.
+type Point struct {
+	X, Y float64
+}
+
+// Document is an ordered list of pages.
+type Document struct {
+	Pages  []*Page
+	Title  string
+	alphas map[uint8]bool
+}
+
+// Page is a single page, drawn in order.
+type Page struct {
+	Width, Height float64
+	document      *Document
+	content       bytes.Buffer
+}
+
+// New returns an empty document.
+func New(title string) *Document {
+	return &Document{Title: title, alphas: make(map[uint8]bool)}
+}
+
+// AddPage appends an empty A4 portrait page.
+func (d *Document) AddPage() *Page {
+	page := &Page{Width: A4Width, Height: A4Height, document: d}
+	d.Pages = append(d.Pages, page)
+	return page
+}
+
+// Rect fills a rectangle.
+func (p *Page) Rect(x, y, width, height float64, fill color.NRGBA) {
+	p.paint(fill, func() {
+		fmt.Fprintf(&p.content, "%s rg %.2f %.2f %.2f %.2f re f\n", rgb(fill), x, p.Height-y-height, width, height)
+	})
+}
+
+// Polyline strokes a line through the points.
+func (p *Page) Polyline(points []Point, width float64, stroke color.NRGBA) {
+	if len(points) < 2 {
+		return
+	}
+	p.paint(stroke, func() {
+		fmt.Fprintf(&p.content, "%s RG %.2f w 1 J 1 j ", rgb(stroke), width)
+		p.path(points)
+		p.content.WriteString("S\n")
+	})
+}
+
+// Polygon fills the shape enclosed by the points.
+func (p *Page) Polygon(points []Point, fill color.NRGBA) {
+	if len(points) < 3 {
+		return
+	}
+	p.paint(fill, func() {
+		fmt.Fprintf(&p.content, "%s rg ", rgb(fill))
+		p.path(points)
+		p.content.WriteString("h f\n")
+	})
+}
+
+// Text writes text with its baseline starting at the position.
+func (p *Page) Text(x, y float64, text string, font Font, size float64, fill color.NRGBA) {
+	if text == "" {
+		return
+	}
+	p.paint(fill, func() {
+		fmt.Fprintf(&p.content, "BT %s rg /F%d %.2f Tf %.2f %.2f Td (", rgb(fill), font+1, size, x, p.Height-y)
+		for _, b := range encode(text) {
+			if b == '(' || b == ')' || b == '\\' {
+				p.content.WriteByte('\\')
+			}
+			p.content.WriteByte(b)
+		}
+		p.content.WriteString(") Tj ET\n")
+	})
+}
+
+func (p *Page) path(points []Point) {
+	for i, point := range points {
+		operator := "l"
+		if i == 0 {
+			operator = "m"
+		}
+		fmt.Fprintf(&p.content, "%.2f %.2f %s ", point.X, p.Height-point.Y, operator)
+	}
+}
+
+// paint draws with the opacity of the colour, isolating the change of
+// graphics state when it is translucent.
+func (p *Page) paint(c color.NRGBA, draw func()) {
+	if c.A == 0xff {
+		draw()
+		return
+	}
+	p.document.alphas[c.A] = true
+	fmt.Fprintf(&p.content, "q /GS%d gs ", c.A)
+	draw()
+	p.content.WriteString("Q\n")
+}
+
+func rgb(c color.NRGBA) string {
+	return fmt.Sprintf("%.3f %.3f %.3f", float64(c.R)/0xff, float64(c.G)/0xff, float64(c.B)/0xff)
+}
+
+// Write writes the document as a PDF file.
+func (d *Document) Write(w io.Writer) error {
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects are numbered before they are written so that the page tree can
+	// be referenced by the pages written ahead of it.
+	var offsets []int
+	reserve := func() int {
+		offsets = append(offsets, 0)
+		return len(offsets)
+	}
+	write := func(id int, content string) {
+		offsets[id-1] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", id, content)
+	}
+	object := func(content string) int {
+		id := reserve()
+		write(id, content)
+		return id
+	}
+
+	catalog := reserve()
+	pages := reserve()
+
+	var fonts bytes.Buffer
+	for i, name := range fontNames {
+		id := object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+		fmt.Fprintf(&fonts, "/F%d %d 0 R ", i+1, id)
+	}
+
+	alphas := make([]int, 0, len(d.alphas))
+	for alpha := range d.alphas {
+		alphas = append(alphas, int(alpha))
+	}
+	sort.Ints(alphas)
+	var states bytes.Buffer
+	for _, alpha := range alphas {
+		id := object(fmt.Sprintf("<< /Type /ExtGState /ca %.3f /CA %.3f >>", float64(alpha)/0xff, float64(alpha)/0xff))
+		fmt.Fprintf(&states, "/GS%d %d 0 R ", alpha, id)
+	}
+	resources := fmt.Sprintf("<< /Font << %s>> /ExtGState << %s>> >>", fonts.String(), states.String())
+
+	var kids bytes.Buffer
+	for _, page := range d.Pages {
+		var compressed bytes.Buffer
+		writer := zlib.NewWriter(&compressed)
+		if _, err := writer.Write(page.content.Bytes()); err != nil {
+			return err
+		}
+		if err := writer.Close(); err != nil {
+			return err
+		}
+
+		content := object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.String()))
+		id := object(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Resources %s /Contents %d 0 R >>", pages, page.Width, page.Height, resources, content))
+		fmt.Fprintf(&kids, "%d 0 R ", id)
+	}
+
+	write(pages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids.String(), len(d.Pages)))
+	write(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pages))
+	info := object(fmt.Sprintf("<< /Title (%s) /Producer (Tchoukball Tracker) >>", escapeString(d.Title)))
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, catalog, info, xref)
+
+	_, err := out.WriteTo(w)
+	return err
+}
+
+func escapeString(text string) string {
+	var b bytes.Buffer
+	for _, c := range encode(text) {
+		if c == '(' || c == ')' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,145 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image/color"
+	"io"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+var (
+	startXref = regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`)
+	stream    = regexp.MustCompile(`/Length (\d+) /Filter /FlateDecode >>\nstream\n`)
+)
+
+// readFile checks that every entry of the cross-reference table of a file
+// points at its object and returns the inflated content streams.
+func readFile(t *testing.T, data []byte) [][]byte {
+	t.Helper()
+	match := startXref.FindSubmatch(data)
+	if match == nil {
+		t.Fatalf("file does not end with startxref: %q", data[max(0, len(data)-40):])
+	}
+	xref, _ := strconv.Atoi(string(match[1]))
+
+	var size int
+	if _, err := fmt.Sscanf(string(data[xref:]), "xref\n0 %d\n", &size); err != nil {
+		t.Fatalf("startxref %d does not point at the xref table: %v", xref, err)
+	}
+	table := data[xref+len(fmt.Sprintf("xref\n0 %d\n", size)):]
+	if !bytes.HasPrefix(table, []byte("0000000000 65535 f \n")) {
+		t.Errorf("xref table does not start with the free entry: %q", table[:20])
+	}
+	for id := 1; id < size; id++ {
+		entry := string(table[20*id : 20*(id+1)])
+		var offset int
+		if _, err := fmt.Sscanf(entry, "%010d 00000 n \n", &offset); err != nil || len(entry) != 20 {
+			t.Fatalf("xref entry of object %d is %q", id, entry)
+		}
+		if header := fmt.Sprintf("%d 0 obj\n", id); !bytes.HasPrefix(data[offset:], []byte(header)) {
+			t.Errorf("xref entry of object %d points at %q", id, data[offset:offset+len(header)])
+		}
+	}
+	if trailer := fmt.Sprintf("trailer\n<< /Size %d ", size); !bytes.Contains(table, []byte(trailer)) {
+		t.Errorf("trailer does not give the size %d", size)
+	}
+
+	var contents [][]byte
+	for _, indices := range stream.FindAllSubmatchIndex(data, -1) {
+		length, _ := strconv.Atoi(string(data[indices[2]:indices[3]]))
+		compressed := data[indices[1] : indices[1]+length]
+		if !bytes.HasPrefix(data[indices[1]+length:], []byte("\nendstream")) {
+			t.Fatalf("stream at %d is not %d bytes long", indices[1], length)
+		}
+		reader, err := zlib.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents = append(contents, content)
+	}
+	return contents
+}
+
+func TestWrite(t *testing.T) {
+	d := New("Report")
+	for i := 0; i < 3; i++ {
+		page := d.AddPage()
+		page.Rect(10, 10, 100, 20, color.NRGBA{R: 0xff, A: 0xff})
+		page.Polygon([]Point{{0, 0}, {10, 0}, {5, 10}}, color.NRGBA{B: 0xff, A: 0x80})
+		page.Polyline([]Point{{0, 0}, {50, 50}}, 2, color.NRGBA{G: 0xff, A: 0x40})
+		page.Text(20, 40, fmt.Sprintf("Page %d", i+1), Helvetica, 10, color.NRGBA{A: 0xff})
+	}
+
+	var buf bytes.Buffer
+	if err := d.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-1.4\n")) {
+		t.Errorf("file starts with %q", buf.Bytes()[:9])
+	}
+
+	contents := readFile(t, buf.Bytes())
+	if len(contents) != len(d.Pages) {
+		t.Fatalf("%d content streams for %d pages", len(contents), len(d.Pages))
+	}
+	for i, content := range contents {
+		if !bytes.Contains(content, []byte(fmt.Sprintf("(Page %d) Tj", i+1))) {
+			t.Errorf("content of page %d is %q", i+1, content)
+		}
+	}
+	for _, state := range []string{"/GS64 ", "/GS128 "} {
+		if !bytes.Contains(buf.Bytes(), []byte(state)) {
+			t.Errorf("graphics state %s is not defined", state)
+		}
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("/Kids [")) || !bytes.Contains(buf.Bytes(), []byte("/Count 3 >>")) {
+		t.Error("page tree does not count 3 pages")
+	}
+}
+
+func TestTextEncoding(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "Plain text", want: "Plain text"},
+		{text: "O'Neil (captain)", want: `O'Neil \(captain\)`},
+		{text: `C:\scores) (`, want: `C:\\scores\) \(`},
+		{text: "Zoë Müller", want: "Zo\xeb M\xfcller"},
+		{text: "€5 – “final”", want: "\x805 \x96 \x93final\x94"},
+		{text: "北京 🏐", want: "?? ?"},
+	}
+
+	d := New(`Report (draft) \ 北京`)
+	page := d.AddPage()
+	for i, test := range tests {
+		page.Text(20, float64(20*(i+1)), test.text, Helvetica, 10, color.NRGBA{A: 0xff})
+	}
+
+	var buf bytes.Buffer
+	if err := d.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	contents := readFile(t, buf.Bytes())
+	lines := regexp.MustCompile(`Td \((.*)\) Tj ET\n`).FindAllSubmatch(contents[0], -1)
+	if len(lines) != len(tests) {
+		t.Fatalf("%d texts written, want %d", len(lines), len(tests))
+	}
+	for i, test := range tests {
+		if got := string(lines[i][1]); got != test.want {
+			t.Errorf("%q written as %q, want %q", test.text, got, test.want)
+		}
+	}
+
+	if title := `/Title (Report \(draft\) \\ ??)`; !bytes.Contains(buf.Bytes(), []byte(title)) {
+		t.Errorf("document does not hold %s", title)
+	}
+}
@@ -0,0 +1,193 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Tchoukball-Tracker/pkg/metrics"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/pdf"
+	"github.com/Tchoukball-Tracker/pkg/stats"
+)
+
+// radarPlayers is the number of top scorers compared on the metrics chart,
+// beyond which the chart becomes unreadable.
+const radarPlayers = 6
+
+// Match lays out the report of a match: its header and final score, the
+// score of every third, tables of the attacking and defending counters and
+// metrics of every player, and charts of shots, scores and metrics.
+func Match(match *models.Match, matchStats *models.MatchStats, scores []models.PeriodScore, generated time.Time) (*pdf.Document, error) {
+	w := newWriter(match.Name)
+
+	w.text(match.Name, pdf.HelveticaBold, 20, ink)
+	if match.HomeTeam != "" || match.AwayTeam != "" {
+		w.text(match.HomeTeam+" vs "+match.AwayTeam, pdf.Helvetica, 13, ink)
+	}
+	var details []string
+	for _, detail := range []string{match.Competition, match.Season} {
+		if detail != "" {
+			details = append(details, detail)
+		}
+	}
+	if !match.CreatedAt.IsZero() {
+		details = append(details, match.CreatedAt.Format("2 January 2006"))
+	}
+	if len(details) > 0 {
+		w.text(strings.Join(details, " · "), pdf.Helvetica, bodySize, muted)
+	}
+
+	w.y += 6
+	w.text(finalScore(match), pdf.HelveticaBold, 16, ink)
+	if match.Status != "" {
+		w.text("Status: "+match.Status, pdf.Helvetica, bodySize, muted)
+	}
+
+	w.heading("Score by third")
+	var scoreRows [][]string
+	var scored, conceded int
+	for _, score := range scores {
+		scoreRows = append(scoreRows, []string{models.PeriodTitle(score.Period), strconv.Itoa(score.Scored), strconv.Itoa(score.Conceded)})
+		scored += score.Scored
+		conceded += score.Conceded
+	}
+	scoreRows = append(scoreRows, []string{"Total", strconv.Itoa(scored), strconv.Itoa(conceded)})
+	w.table([]column{{"Third", 135}, {"Scored", 70}, {"Conceded", 70}}, scoreRows, true)
+
+	playerWidth := 115.0
+	counterTable := func(title string, names []string) {
+		w.heading(title)
+		columns := []column{{"Player", playerWidth}}
+		for _, name := range names {
+			columns = append(columns, column{name, (w.width() - playerWidth) / float64(len(names))})
+		}
+
+		var rows [][]string
+		addRow := func(name string, counters models.Counters) {
+			values := counters.Values()
+			row := []string{name}
+			for _, counter := range names {
+				row = append(row, strconv.Itoa(int(values[counter])))
+			}
+			rows = append(rows, row)
+		}
+		for _, player := range matchStats.Players {
+			addRow(player.Name, player.Totals)
+		}
+		addRow("Total", matchStats.Totals)
+		w.table(columns, rows, true)
+	}
+	counterTable("Attacking", models.AttackingCounters)
+	counterTable("Defending", models.DefendingCounters)
+
+	w.heading("Metrics")
+	columns := []column{{"Player", playerWidth}}
+	for _, definition := range metrics.Definitions {
+		columns = append(columns, column{definition.Name, (w.width() - playerWidth) / float64(len(metrics.Definitions))})
+	}
+	var metricRows [][]string
+	addMetrics := func(name string, counters models.Counters) {
+		values := metrics.Compute(counters)
+		row := []string{name}
+		for _, definition := range metrics.Definitions {
+			row = append(row, fmt.Sprintf("%.0f%%", values[definition.Name]*100))
+		}
+		metricRows = append(metricRows, row)
+	}
+	for _, player := range matchStats.Players {
+		addMetrics(player.Name, player.Totals)
+	}
+	addMetrics("Team", matchStats.Totals)
+	w.table(columns, metricRows, true)
+
+	w.newPage()
+	w.text("Charts", pdf.HelveticaBold, 16, ink)
+	w.y += 6
+	for _, chart := range []struct {
+		graphType, title string
+		data             *models.SeriesData
+		height           float64
+	}{
+		{models.GraphStackedBar, "Shots by player", shotsByPlayer(matchStats), 235},
+		{models.GraphBar, "Score by third", scoreByThird(scores), 235},
+		{models.GraphRadar, "Metrics of the top scorers", metricsOfTopScorers(matchStats), 380},
+	} {
+		if err := w.chart(chart.graphType, chart.title, chart.data, chart.height); err != nil {
+			return nil, err
+		}
+	}
+
+	w.footer(match.Name + " · Generated " + generated.Format("2 January 2006 15:04"))
+	return w.document, nil
+}
+
+// finalScore describes the result, naming the side that forfeited if any.
+func finalScore(match *models.Match) string {
+	home, away := match.HomeTeam, match.AwayTeam
+	if home == "" {
+		home = "Home"
+	}
+	if away == "" {
+		away = "Away"
+	}
+
+	score := fmt.Sprintf("%s %d – %d %s", home, match.HomeScore, match.AwayScore, away)
+	switch match.Forfeit {
+	case models.SideHome:
+		score += " (" + home + " forfeited)"
+	case models.SideAway:
+		score += " (" + away + " forfeited)"
+	}
+	return score
+}
+
+func shotsByPlayer(matchStats *models.MatchStats) *models.SeriesData {
+	var labels []string
+	var values []map[string]float64
+	for _, player := range matchStats.Players {
+		labels = append(labels, player.Name)
+		values = append(values, player.Totals.Values())
+	}
+	return stats.Series(labels, values, []string{"point", "caught", "short", "frame", "footing", "landed"}, nil)
+}
+
+func scoreByThird(scores []models.PeriodScore) *models.SeriesData {
+	data := &models.SeriesData{Series: []models.Series{{Name: "Scored"}, {Name: "Conceded"}}}
+	for _, score := range scores {
+		scored, conceded := float64(score.Scored), float64(score.Conceded)
+		data.Labels = append(data.Labels, models.PeriodTitle(score.Period))
+		data.Series[0].Values = append(data.Series[0].Values, &scored)
+		data.Series[1].Values = append(data.Series[1].Values, &conceded)
+	}
+	return data
+}
+
+// metricsOfTopScorers compares the metrics of the players who scored the
+// most points, one series per player.
+func metricsOfTopScorers(matchStats *models.MatchStats) *models.SeriesData {
+	players := append([]*models.PlayerStats{}, matchStats.Players...)
+	sort.SliceStable(players, func(i, j int) bool {
+		return players[i].Totals.Attacking.Point > players[j].Totals.Attacking.Point
+	})
+	if len(players) > radarPlayers {
+		players = players[:radarPlayers]
+	}
+
+	data := &models.SeriesData{}
+	for _, definition := range metrics.Definitions {
+		data.Labels = append(data.Labels, definition.Name)
+	}
+	for _, player := range players {
+		values := metrics.Compute(player.Totals)
+		series := models.Series{Name: player.Name}
+		for _, definition := range metrics.Definitions {
+			value := values[definition.Name]
+			series.Values = append(series.Values, &value)
+		}
+		data.Series = append(data.Series, series)
+	}
+	return data
+}
@@ -0,0 +1,171 @@
+// Package report lays out printable PDF reports, flowing headings, text,
+// tables and charts down A4 pages.
+package report
+
+import (
+	"image/color"
+	"strconv"
+
+	"github.com/Tchoukball-Tracker/pkg/charts"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/pdf"
+)
+
+const (
+	margin      = 40.0
+	footerSpace = 30.0
+	bodySize    = 10.0
+	tableSize   = 9.0
+	headerSize  = 8.0
+	rowHeight   = 15.0
+)
+
+var (
+	ink        = color.NRGBA{R: 0x22, G: 0x22, B: 0x22, A: 0xff}
+	muted      = color.NRGBA{R: 0x77, G: 0x77, B: 0x77, A: 0xff}
+	rule       = color.NRGBA{R: 0xbb, G: 0xbb, B: 0xbb, A: 0xff}
+	headerFill = color.NRGBA{R: 0xe8, G: 0xe8, B: 0xe8, A: 0xff}
+	stripeFill = color.NRGBA{R: 0xf6, G: 0xf6, B: 0xf6, A: 0xff}
+)
+
+// writer flows content down the pages of a document, starting a new page
+// when the next block does not fit.
+type writer struct {
+	document *pdf.Document
+	page     *pdf.Page
+	y        float64
+}
+
+func newWriter(title string) *writer {
+	w := &writer{document: pdf.New(title)}
+	w.newPage()
+	return w
+}
+
+func (w *writer) newPage() {
+	w.page = w.document.AddPage()
+	w.y = margin
+}
+
+func (w *writer) width() float64 {
+	return w.page.Width - 2*margin
+}
+
+// ensure starts a new page unless height fits above the footer.
+func (w *writer) ensure(height float64) {
+	if w.y+height > w.page.Height-margin-footerSpace {
+		w.newPage()
+	}
+}
+
+// text writes a line of text and moves below it.
+func (w *writer) text(text string, font pdf.Font, size float64, fill color.NRGBA) {
+	w.ensure(size * 1.4)
+	w.y += size
+	w.page.Text(margin, w.y, text, font, size, fill)
+	w.y += size * 0.4
+}
+
+// heading starts a section, keeping it on the same page as the first rows
+// that follow it.
+func (w *writer) heading(text string) {
+	w.ensure(13*2 + 3*rowHeight)
+	w.y += 13
+	w.text(text, pdf.HelveticaBold, 13, ink)
+	w.y += 4
+}
+
+// column of a table. The first column is aligned left and the others right.
+type column struct {
+	title string
+	width float64
+}
+
+// table writes rows under a shaded header row, repeating the header on every
+// page it continues onto. The last row is set in bold when totals is true.
+func (w *writer) table(columns []column, rows [][]string, totals bool) {
+	header := func() {
+		w.page.Rect(margin, w.y, tableWidth(columns), rowHeight, headerFill)
+		w.cells(columns, titles(columns), pdf.HelveticaBold, headerSize)
+	}
+
+	w.ensure(2 * rowHeight)
+	header()
+	for i, row := range rows {
+		if w.y+rowHeight > w.page.Height-margin-footerSpace {
+			w.newPage()
+			header()
+		}
+
+		font := pdf.Helvetica
+		if totals && i == len(rows)-1 {
+			font = pdf.HelveticaBold
+			w.page.Polyline([]pdf.Point{{X: margin, Y: w.y}, {X: margin + tableWidth(columns), Y: w.y}}, 0.75, rule)
+		} else if i%2 == 1 {
+			w.page.Rect(margin, w.y, tableWidth(columns), rowHeight, stripeFill)
+		}
+		w.cells(columns, row, font, tableSize)
+	}
+	w.y += rowHeight / 2
+}
+
+func (w *writer) cells(columns []column, values []string, font pdf.Font, size float64) {
+	x := margin
+	for i, c := range columns {
+		value := fit(values[i], font, size, c.width-8)
+		if i == 0 {
+			w.page.Text(x+4, w.y+rowHeight-4, value, font, size, ink)
+		} else {
+			w.page.Text(x+c.width-4-pdf.TextWidth(value, font, size), w.y+rowHeight-4, value, font, size, ink)
+		}
+		x += c.width
+	}
+	w.y += rowHeight
+}
+
+func titles(columns []column) []string {
+	values := make([]string, len(columns))
+	for i, c := range columns {
+		values[i] = c.title
+	}
+	return values
+}
+
+func tableWidth(columns []column) float64 {
+	total := 0.0
+	for _, c := range columns {
+		total += c.width
+	}
+	return total
+}
+
+// fit shortens text with an ellipsis until it is no wider than width.
+func fit(text string, font pdf.Font, size, width float64) string {
+	if pdf.TextWidth(text, font, size) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.TextWidth(string(runes)+"…", font, size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// chart draws a graph across the width of the page.
+func (w *writer) chart(graphType, title string, data *models.SeriesData, height float64) error {
+	w.ensure(height + 10)
+	canvas := charts.NewPDF(w.page, margin, w.y, w.width(), height)
+	if err := charts.Draw(canvas, graphType, title, data); err != nil {
+		return err
+	}
+	w.y += height + 10
+	return nil
+}
+
+// footer writes the text and page number at the bottom of every page.
+func (w *writer) footer(text string) {
+	for i, page := range w.document.Pages {
+		line := text + " · Page " + strconv.Itoa(i+1) + " of " + strconv.Itoa(len(w.document.Pages))
+		page.Text((page.Width-pdf.TextWidth(line, pdf.Helvetica, 8))/2, page.Height-margin+10, line, pdf.Helvetica, 8, muted)
+	}
+}
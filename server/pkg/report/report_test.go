@@ -0,0 +1,64 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/pdf"
+)
+
+func TestMatch(t *testing.T) {
+	match := &models.Match{Name: `Lions (A) \ 北京`, HomeTeam: "Lions", AwayTeam: "Tigers", HomeScore: 15, AwayScore: 12, Status: models.MatchStatusFinished}
+	matchStats := &models.MatchStats{Name: match.Name}
+	for _, name := range []string{"Zoë", "O'Neil (c)", "李", strings.Repeat("Very long name ", 10)} {
+		player := &models.PlayerStats{Name: name}
+		player.Totals.Attacking.Point = len(name)
+		matchStats.Players = append(matchStats.Players, player)
+		matchStats.Totals.Attacking.Point += len(name)
+	}
+	scores := []models.PeriodScore{{Period: "first", Scored: 5, Conceded: 4}, {Period: "second", Scored: 10, Conceded: 8}}
+
+	document, err := Match(match, matchStats, scores, time.Date(2024, 5, 1, 18, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(document.Pages) < 2 {
+		t.Errorf("report has %d pages, want the charts on a page of their own", len(document.Pages))
+	}
+
+	var buf bytes.Buffer
+	if err := document.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-1.4\n")) || !bytes.HasSuffix(buf.Bytes(), []byte("%%EOF\n")) {
+		t.Error("report is not a complete PDF file")
+	}
+	if title := `/Title (Lions \(A\) \\ ??)`; !bytes.Contains(buf.Bytes(), []byte(title)) {
+		t.Errorf("report does not hold %s", title)
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		text  string
+		width float64
+		want  string
+	}{
+		{text: "Alex", width: 100, want: "Alex"},
+		{text: "Alexandra Montgomery", width: 50, want: "Alexandr…"},
+		{text: "Alex", width: 1, want: "…"},
+	}
+
+	for _, test := range tests {
+		got := fit(test.text, pdf.Helvetica, 10, test.width)
+		if got != test.want {
+			t.Errorf("fit(%q, %v) = %q, want %q", test.text, test.width, got, test.want)
+		}
+		if got != test.text && len(got) > 3 && pdf.TextWidth(got, pdf.Helvetica, 10) > test.width {
+			t.Errorf("fit(%q, %v) = %q, which is wider", test.text, test.width, got)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package stats
+
+import (
+	"github.com/Tchoukball-Tracker/pkg/metrics"
+	"github.com/Tchoukball-Tracker/pkg/models"
+)
+
+// Career turns aggregated player totals into career statistics with per-match
+// and per-period averages.
+func Career(totals *models.PlayerTotals, filter models.StatsFilter) *models.CareerStats {
+	return &models.CareerStats{
+		Player:    totals.Player,
+		Filter:    filter,
+		Matches:   totals.Matches,
+		Periods:   totals.Periods,
+		Totals:    totals.Counters,
+		Metrics:   metrics.Compute(totals.Counters),
+		PerMatch:  totals.Counters.Average(totals.Matches),
+		PerPeriod: totals.Counters.Average(totals.Periods),
+	}
+}
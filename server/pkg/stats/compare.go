@@ -0,0 +1,48 @@
+package stats
+
+import (
+	"github.com/Tchoukball-Tracker/pkg/metrics"
+	"github.com/Tchoukball-Tracker/pkg/models"
+)
+
+// Compare lays out every counter, built-in and user-defined metric of the
+// subjects side by side, with the difference of each subject from the first.
+// values holds the counter and derived metric values of each subject in the
+// same order as subjects.
+func Compare(kind string, subjects []models.ComparisonSubject, values []map[string]float64, custom []*CustomMetric) *models.Comparison {
+	comparison := &models.Comparison{Kind: kind, Subjects: subjects}
+
+	names := metrics.Variables()
+	for _, metric := range custom {
+		names = append(names, metric.Name)
+	}
+
+	for _, name := range names {
+		value, _ := Resolve(name, custom)
+		row := &models.ComparisonRow{Metric: name}
+		for i := range subjects {
+			v, err := value(values[i])
+			if err != nil {
+				row.Values = append(row.Values, nil)
+			} else {
+				row.Values = append(row.Values, &v)
+			}
+			row.Differences = append(row.Differences, difference(row.Values[0], row.Values[i], i))
+		}
+		comparison.Rows = append(comparison.Rows, row)
+	}
+	return comparison
+}
+
+func difference(baseline, value *float64, index int) *models.Difference {
+	if index == 0 || baseline == nil || value == nil {
+		return nil
+	}
+
+	diff := &models.Difference{Absolute: *value - *baseline}
+	if *baseline != 0 {
+		percent := diff.Absolute / *baseline * 100
+		diff.Percent = &percent
+	}
+	return diff
+}
@@ -0,0 +1,56 @@
+package stats
+
+import (
+	"github.com/Tchoukball-Tracker/pkg/formula"
+	"github.com/Tchoukball-Tracker/pkg/metrics"
+	"github.com/Tchoukball-Tracker/pkg/models"
+)
+
+// CustomMetric is a user-defined metric compiled for evaluation. Err is set if
+// the saved expression no longer compiles.
+type CustomMetric struct {
+	Name    string
+	Formula *formula.Formula
+	Err     error
+}
+
+// CompileMetrics compiles the expressions of the saved user-defined metrics.
+func CompileMetrics(saved []*models.Metric) []*CustomMetric {
+	compiled := make([]*CustomMetric, 0, len(saved))
+	for _, metric := range saved {
+		f, err := formula.Compile(metric.Expression, metrics.Variables())
+		compiled = append(compiled, &CustomMetric{Name: metric.Name, Formula: f, Err: err})
+	}
+	return compiled
+}
+
+// Evaluate computes each user-defined metric for the counters, reporting
+// evaluation errors such as division by zero per metric.
+func Evaluate(custom []*CustomMetric, c models.Counters) []*models.MetricValue {
+	if len(custom) == 0 {
+		return nil
+	}
+
+	values := metrics.Values(c)
+	results := make([]*models.MetricValue, 0, len(custom))
+	for _, metric := range custom {
+		result := &models.MetricValue{Name: metric.Name}
+		if metric.Err != nil {
+			result.Error = metric.Err.Error()
+		} else if value, err := metric.Formula.Evaluate(values); err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Value = &value
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// ApplyCustom evaluates the user-defined metrics for every player and the team.
+func ApplyCustom(s *models.MatchStats, custom []*CustomMetric) {
+	for _, player := range s.Players {
+		player.Custom = Evaluate(custom, player.Totals)
+	}
+	s.Custom = Evaluate(custom, s.Totals)
+}
@@ -0,0 +1,134 @@
+package stats
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Tchoukball-Tracker/pkg/metrics"
+	"github.com/Tchoukball-Tracker/pkg/models"
+)
+
+// ValueFunc computes a metric from the counter and derived metric values of a subject.
+type ValueFunc func(values map[string]float64) (float64, error)
+
+// Resolve returns a function computing the named counter, built-in metric or
+// user-defined metric. It reports false if no metric has that name.
+func Resolve(name string, custom []*CustomMetric) (ValueFunc, bool) {
+	for _, variable := range metrics.Variables() {
+		if variable == name {
+			return func(values map[string]float64) (float64, error) {
+				return values[name], nil
+			}, true
+		}
+	}
+
+	for _, metric := range custom {
+		if metric.Name == name {
+			metric := metric
+			return func(values map[string]float64) (float64, error) {
+				if metric.Err != nil {
+					return 0, metric.Err
+				}
+				return metric.Formula.Evaluate(values)
+			}, true
+		}
+	}
+	return nil, false
+}
+
+// TotalsValues returns the values metrics are computed from for a player. In
+// average mode counters are averaged per match while derived metrics, being
+// ratios, are computed from the totals.
+func TotalsValues(totals *models.PlayerTotals, mode string) map[string]float64 {
+	values := metrics.Values(totals.Counters)
+	if mode == models.LeaderboardAverage {
+		for name, value := range totals.Counters.Average(totals.Matches) {
+			values[name] = value
+		}
+	}
+	return values
+}
+
+// ParseScope turns a scope such as "season:2024" or
+// "competition:National League,season:2024" into a stats filter. The scope
+// "all" or an empty scope selects every match.
+func ParseScope(scope string) (models.StatsFilter, error) {
+	var filter models.StatsFilter
+	if scope == "" || scope == "all" {
+		return filter, nil
+	}
+
+	for _, part := range strings.Split(scope, ",") {
+		kind, value, ok := strings.Cut(part, ":")
+		if !ok || value == "" {
+			return filter, fmt.Errorf("Invalid scope %q, expected kind:value", part)
+		}
+		switch kind {
+		case "season":
+			filter.Season = value
+		case "competition":
+			filter.Competition = value
+		case "opponent":
+			filter.Opponent = value
+		case "match":
+			filter.Matches = append(filter.Matches, value)
+		default:
+			return filter, fmt.Errorf("Unknown scope %q, use season, competition, opponent or match", kind)
+		}
+	}
+	return filter, nil
+}
+
+// Rank orders the players by value, highest first unless ascending is set.
+// Players with equal values share a rank and are listed by name. Players
+// below minMatches or whose value cannot be computed are left out.
+func Rank(totals []*models.PlayerTotals, value ValueFunc, mode string, minMatches int, ascending bool) []*models.LeaderboardEntry {
+	var entries []*models.LeaderboardEntry
+	for _, t := range totals {
+		if t.Matches < minMatches {
+			continue
+		}
+		v, err := value(TotalsValues(t, mode))
+		if err != nil {
+			continue
+		}
+		entries = append(entries, &models.LeaderboardEntry{Player: t.Player, Matches: t.Matches, Value: v})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Value != b.Value {
+			return (a.Value > b.Value) != ascending
+		}
+		return a.Player < b.Player
+	})
+
+	for i, entry := range entries {
+		entry.Rank = i + 1
+		if i > 0 && entries[i-1].Value == entry.Value {
+			entry.Rank = entries[i-1].Rank
+		}
+	}
+	return entries
+}
+
+var ErrInvalidPage = errors.New("Page and limit must be positive")
+
+// Paginate returns the entries on the given page, starting from page 1.
+func Paginate(entries []*models.LeaderboardEntry, page, limit int) ([]*models.LeaderboardEntry, error) {
+	if page < 1 || limit < 1 {
+		return nil, ErrInvalidPage
+	}
+
+	start := (page - 1) * limit
+	if start >= len(entries) {
+		return []*models.LeaderboardEntry{}, nil
+	}
+	end := start + limit
+	if end > len(entries) {
+		end = len(entries)
+	}
+	return entries[start:end], nil
+}
@@ -0,0 +1,149 @@
+package stats
+
+import (
+	"github.com/Tchoukball-Tracker/pkg/metrics"
+	"github.com/Tchoukball-Tracker/pkg/models"
+)
+
+// matchStats accumulates per-player and per-period counters while keeping
+// players in the order they first appear.
+type matchStats struct {
+	stats   *models.MatchStats
+	players map[string]*models.PlayerStats
+}
+
+func newMatchStats(match *models.Match, source string) *matchStats {
+	s := &matchStats{
+		stats: &models.MatchStats{
+			Match:   match.ID,
+			Name:    match.Name,
+			Source:  source,
+			Players: []*models.PlayerStats{},
+			Periods: make(map[string]*models.Counters),
+		},
+		players: make(map[string]*models.PlayerStats),
+	}
+
+	for period := range match.Thirds {
+		s.stats.Periods[period] = &models.Counters{}
+	}
+	return s
+}
+
+func (s *matchStats) player(name string) *models.PlayerStats {
+	if _, ok := s.players[name]; !ok {
+		s.players[name] = &models.PlayerStats{Name: name, Periods: make(map[string]*models.Counters)}
+		s.stats.Players = append(s.stats.Players, s.players[name])
+	}
+	return s.players[name]
+}
+
+func (s *matchStats) add(period, name string, counters models.Counters) {
+	player := s.player(name)
+	if _, ok := player.Periods[period]; !ok {
+		player.Periods[period] = &models.Counters{}
+	}
+	if _, ok := s.stats.Periods[period]; !ok {
+		s.stats.Periods[period] = &models.Counters{}
+	}
+
+	player.Periods[period].Add(counters)
+	player.Totals.Add(counters)
+	s.stats.Periods[period].Add(counters)
+	s.stats.Totals.Add(counters)
+}
+
+// FromSpreadsheets totals the attacking and defending counters of each third
+// of the match, keyed by period.
+func FromSpreadsheets(match *models.Match, spreadsheets map[string]*models.Spreadsheet) *models.MatchStats {
+	s := newMatchStats(match, models.StatsSourceCounters)
+	for _, period := range models.Periods {
+		spreadsheet, ok := spreadsheets[period]
+		if !ok {
+			continue
+		}
+		for _, player := range spreadsheet.Players {
+			s.add(period, player.Name, player.Counters())
+		}
+	}
+	return s.withMetrics()
+}
+
+// FromEvents replays the player actions in the action log of the match.
+func FromEvents(match *models.Match, events []*models.Event) *models.MatchStats {
+	s := newMatchStats(match, models.StatsSourceEvents)
+
+	// Replay into a player per period so the counters are floored at zero
+	// exactly as they are when recorded on a spreadsheet
+	periods := make(map[string]map[string]*models.Player)
+	for _, event := range events {
+		if event.Type != models.EventAction || event.Player == "" {
+			continue
+		}
+		if _, ok := periods[event.Period]; !ok {
+			periods[event.Period] = make(map[string]*models.Player)
+		}
+		if _, ok := periods[event.Period][event.Player]; !ok {
+			periods[event.Period][event.Player] = &models.Player{Name: event.Player}
+			s.player(event.Player)
+		}
+		periods[event.Period][event.Player].AddAction(models.PlayerAction{Type: event.Action, Value: event.Value})
+	}
+
+	for _, player := range s.stats.Players {
+		for _, period := range models.Periods {
+			if p, ok := periods[period][player.Name]; ok {
+				s.add(period, p.Name, p.Counters())
+			}
+		}
+	}
+	return s.withMetrics()
+}
+
+// HasActions reports whether the action log holds any player action, as
+// opposed to only period, substitution or conceded point events.
+func HasActions(events []*models.Event) bool {
+	for _, event := range events {
+		if event.Type == models.EventAction && event.Player != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Reconciles reports whether two statistics of the same match give every
+// player the same counters in every period. Players without any counters in
+// a period are left out, as a spreadsheet lists players who did nothing.
+func Reconciles(a, b *models.MatchStats) bool {
+	counters := func(s *models.MatchStats) map[[2]string]models.Counters {
+		byPlayer := make(map[[2]string]models.Counters)
+		for _, player := range s.Players {
+			for period, c := range player.Periods {
+				if *c != (models.Counters{}) {
+					byPlayer[[2]string{period, player.Name}] = *c
+				}
+			}
+		}
+		return byPlayer
+	}
+
+	ca, cb := counters(a), counters(b)
+	if len(ca) != len(cb) {
+		return false
+	}
+	for key, c := range ca {
+		if cb[key] != c {
+			return false
+		}
+	}
+	return true
+}
+
+// withMetrics derives the metrics of every player and the team from their totals.
+func (s *matchStats) withMetrics() *models.MatchStats {
+	for _, player := range s.stats.Players {
+		player.Metrics = metrics.Compute(player.Totals)
+	}
+	s.stats.Metrics = metrics.Compute(s.stats.Totals)
+	return s.stats
+}
@@ -0,0 +1,232 @@
+package stats
+
+import (
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"github.com/Tchoukball-Tracker/pkg/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MatchFilter builds the query selecting the matches a stats filter covers.
+func MatchFilter(filter models.StatsFilter) bson.M {
+	query := bson.M{}
+	if !filter.From.IsZero() || !filter.To.IsZero() {
+		createdAt := bson.M{}
+		if !filter.From.IsZero() {
+			createdAt["$gte"] = filter.From
+		}
+		if !filter.To.IsZero() {
+			// Include the whole of the last day
+			createdAt["$lt"] = filter.To.AddDate(0, 0, 1)
+		}
+		query["created_at"] = createdAt
+	}
+	if filter.Season != "" {
+		query["season"] = filter.Season
+	}
+	if filter.Competition != "" {
+		query["competition"] = filter.Competition
+	}
+	if len(filter.Matches) > 0 {
+		ids := bson.A{}
+		for _, id := range filter.Matches {
+			ids = append(ids, utils.ConvertToMongoID(id))
+		}
+		query["_id"] = bson.M{"$in": ids}
+	}
+	var sides bson.A
+	if filter.Team != "" {
+		sides = append(sides, bson.M{"$or": teamFilter(filter.Team, false)})
+	}
+	if filter.Opponent != "" {
+		sides = append(sides, bson.M{"$or": teamFilter(filter.Opponent, true)})
+	}
+	if len(sides) > 0 {
+		query["$and"] = sides
+	}
+	return query
+}
+
+// teamFilter matches the matches team played in, either as the team of the
+// tracked players or as their opponent. Matches without a tracked side track
+// the home team.
+func teamFilter(team string, opponent bool) bson.A {
+	// Where the team is when the tracked players play at home and away
+	whenHome, whenAway := "home_team", "away_team"
+	if opponent {
+		whenHome, whenAway = whenAway, whenHome
+	}
+	return bson.A{
+		bson.M{"tracked_side": bson.M{"$ne": models.SideAway}, whenHome: team},
+		bson.M{"tracked_side": models.SideAway, whenAway: team},
+	}
+}
+
+// playerRows returns the stages that turn the filtered matches into one
+// document per player per third, shaped as
+// {match, name, created_at, competition, season, period, player: {name, attacking, defending}}.
+func playerRows(filter models.StatsFilter) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$match", Value: MatchFilter(filter)}},
+		{{Key: "$project", Value: bson.M{"name": 1, "created_at": 1, "competition": 1, "season": 1, "thirds": bson.M{"$objectToArray": "$thirds"}}}},
+		{{Key: "$unwind", Value: "$thirds"}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         (&models.Spreadsheet{}).CollectionName(),
+			"localField":   "thirds.v",
+			"foreignField": "_id",
+			"as":           "spreadsheet",
+		}}},
+		{{Key: "$unwind", Value: "$spreadsheet"}},
+		{{Key: "$unwind", Value: "$spreadsheet.players"}},
+		{{Key: "$project", Value: bson.M{
+			"match":       "$_id",
+			"name":        1,
+			"created_at":  1,
+			"competition": 1,
+			"season":      1,
+			"period":      "$thirds.k",
+			"player":      "$spreadsheet.players",
+		}}},
+	}
+}
+
+// CounterRowsPipeline turns the filtered matches into a models.CounterRow
+// document per player per third, ordered by match date, period and player.
+func CounterRowsPipeline(filter models.StatsFilter) mongo.Pipeline {
+	return append(playerRows(filter),
+		bson.D{{Key: "$sort", Value: bson.D{
+			{Key: "created_at", Value: 1},
+			{Key: "match", Value: 1},
+			{Key: "period", Value: 1},
+			{Key: "player.name", Value: 1},
+		}}},
+		bson.D{{Key: "$project", Value: bson.M{
+			"_id":         0,
+			"match":       1,
+			"match_name":  "$name",
+			"match_date":  "$created_at",
+			"competition": 1,
+			"season":      1,
+			"period":      1,
+			"player":      "$player.name",
+			"attacking":   "$player.attacking",
+			"defending":   "$player.defending",
+		}}},
+	)
+}
+
+// MatchIDsPipeline selects the IDs of the filtered matches.
+func MatchIDsPipeline(filter models.StatsFilter) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$match", Value: MatchFilter(filter)}},
+		{{Key: "$project", Value: bson.M{"_id": 1}}},
+	}
+}
+
+// ActionRowsPipeline runs over the action log, joining the events of the
+// given matches to their match into models.ActionRow documents, ordered by
+// match date, period, game clock and time recorded. Each event is joined to
+// its one match, so no document ever holds the whole log of a match.
+func ActionRowsPipeline(matches []primitive.ObjectID) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"match": bson.M{"$in": matches}}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         (&models.Match{}).CollectionName(),
+			"localField":   "match",
+			"foreignField": "_id",
+			"as":           "match_info",
+		}}},
+		{{Key: "$unwind", Value: "$match_info"}},
+		{{Key: "$sort", Value: bson.D{
+			{Key: "match_info.created_at", Value: 1},
+			{Key: "match", Value: 1},
+			{Key: "period", Value: 1},
+			{Key: "clock", Value: 1},
+			{Key: "created_at", Value: 1},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":         0,
+			"match":       1,
+			"match_name":  "$match_info.name",
+			"match_date":  "$match_info.created_at",
+			"competition": "$match_info.competition",
+			"season":      "$match_info.season",
+			"period":      1,
+			"type":        1,
+			"clock":       1,
+			"player":      1,
+			"action":      1,
+			"value":       1,
+			"player_in":   1,
+			"player_out":  1,
+			"created_at":  1,
+		}}},
+	}
+}
+
+// PlayerTotalsPipeline aggregates the counters of every player over the
+// filtered matches into models.PlayerTotals documents. If any players are
+// given only those players are aggregated.
+func PlayerTotalsPipeline(filter models.StatsFilter, players ...string) mongo.Pipeline {
+	pipeline := playerRows(filter)
+	if len(players) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"player.name": bson.M{"$in": players}}}})
+	}
+
+	// Sum each third into a row per player per match, then each match into a row per player
+	byMatch := bson.M{"_id": bson.M{"player": "$player.name", "match": "$match"}, "periods": bson.M{"$sum": 1}}
+	byPlayer := bson.M{"_id": "$_id.player", "matches": bson.M{"$sum": 1}, "periods": bson.M{"$sum": "$periods"}}
+	shape := bson.M{"matches": 1, "periods": 1, "attacking": bson.M{}, "defending": bson.M{}}
+	for _, counter := range counterPaths() {
+		byMatch[counter.field] = bson.M{"$sum": "$player." + counter.group + "." + counter.name}
+		byPlayer[counter.field] = bson.M{"$sum": "$" + counter.field}
+		shape[counter.group].(bson.M)[counter.name] = "$" + counter.field
+	}
+
+	return append(pipeline,
+		bson.D{{Key: "$group", Value: byMatch}},
+		bson.D{{Key: "$group", Value: byPlayer}},
+		bson.D{{Key: "$project", Value: shape}},
+	)
+}
+
+// MatchTotalsPipeline aggregates the counters of the given players, or of the
+// whole team if none are given, into a models.MatchTotals document per
+// filtered match, ordered by match date.
+func MatchTotalsPipeline(filter models.StatsFilter, players ...string) mongo.Pipeline {
+	pipeline := playerRows(filter)
+	if len(players) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"player.name": bson.M{"$in": players}}}})
+	}
+
+	byMatch := bson.M{"_id": "$match", "name": bson.M{"$first": "$name"}, "created_at": bson.M{"$first": "$created_at"}}
+	shape := bson.M{"name": 1, "created_at": 1, "attacking": bson.M{}, "defending": bson.M{}}
+	for _, counter := range counterPaths() {
+		byMatch[counter.field] = bson.M{"$sum": "$player." + counter.group + "." + counter.name}
+		shape[counter.group].(bson.M)[counter.name] = "$" + counter.field
+	}
+
+	return append(pipeline,
+		bson.D{{Key: "$group", Value: byMatch}},
+		bson.D{{Key: "$project", Value: shape}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}}},
+	)
+}
+
+type counterPath struct {
+	group string // Either attacking or defending
+	name  string // Counter name within the group
+	field string // Flat name used while grouping
+}
+
+func counterPaths() []counterPath {
+	var paths []counterPath
+	for _, name := range models.AttackingCounters {
+		paths = append(paths, counterPath{group: "attacking", name: name, field: "attacking_" + name})
+	}
+	for _, name := range models.DefendingCounters {
+		paths = append(paths, counterPath{group: "defending", name: name, field: "defending_" + name})
+	}
+	return paths
+}
@@ -0,0 +1,127 @@
+// Package stats computes statistics from spreadsheet counters and the action log.
+package stats
+
+import (
+	"sort"
+
+	"github.com/Tchoukball-Tracker/pkg/models"
+)
+
+// PlayingTime computes the time on court and plus/minus of every player in
+// each period from the lineups and the timed events of the match. Events
+// without a game clock cannot be placed on the timeline and are ignored.
+// Events at the same clock are taken in the order they were recorded, so a
+// substitution made before the period starts changes its lineup.
+func PlayingTime(match *models.Match, events []*models.Event) []*models.PlayingTime {
+	players := make(map[string]*models.PlayingTime)
+	player := func(name string) *models.PlayingTime {
+		if _, ok := players[name]; !ok {
+			players[name] = &models.PlayingTime{Player: name, Periods: make(map[string]*models.PeriodTime)}
+		}
+		return players[name]
+	}
+
+	for _, period := range models.Periods {
+		timeline := timedEvents(events, period)
+		end := periodEnd(match, timeline)
+
+		times := make(map[string]*models.PeriodTime)
+		periodTime := func(name string) *models.PeriodTime {
+			if _, ok := times[name]; !ok {
+				times[name] = &models.PeriodTime{}
+				player(name).Periods[period] = times[name]
+			}
+			return times[name]
+		}
+
+		// onCourt maps each player on court to the clock they came on at
+		onCourt := make(map[string]int)
+		for _, name := range match.Lineups[period] {
+			onCourt[name] = 0
+			periodTime(name)
+		}
+
+		for _, event := range timeline {
+			clock := *event.Clock
+			switch event.Type {
+			case models.EventSubstitution:
+				if since, ok := onCourt[event.PlayerOut]; ok {
+					periodTime(event.PlayerOut).Seconds += clock - since
+					delete(onCourt, event.PlayerOut)
+				}
+				if _, ok := onCourt[event.PlayerIn]; !ok && event.PlayerIn != "" {
+					onCourt[event.PlayerIn] = clock
+					periodTime(event.PlayerIn)
+				}
+			case models.EventAction:
+				if event.Action != "point" {
+					continue
+				}
+				for name := range onCourt {
+					periodTime(name).PlusMinus += event.Value
+				}
+			case models.EventConceded:
+				for name := range onCourt {
+					periodTime(name).PlusMinus -= event.Value
+				}
+			}
+		}
+
+		for name, since := range onCourt {
+			periodTime(name).Seconds += end - since
+		}
+	}
+
+	result := make([]*models.PlayingTime, 0, len(players))
+	for _, p := range players {
+		for _, period := range p.Periods {
+			p.Seconds += period.Seconds
+			p.PlusMinus += period.PlusMinus
+		}
+		result = append(result, p)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Player < result[j].Player
+	})
+	return result
+}
+
+// periodEnd returns the clock a period ends at. A period of a live match
+// without an end event is still being played, or yet to be, so it ends at its
+// last event. Otherwise it lasts its full length, or up to its last event if
+// that is later.
+func periodEnd(match *models.Match, timeline []*models.Event) int {
+	end := match.GetPeriodLength()
+	if match.Status == models.MatchStatusLive {
+		end = 0
+		for _, event := range timeline {
+			if event.Type == models.EventPeriodEnd {
+				end = match.GetPeriodLength()
+			}
+		}
+	}
+	if len(timeline) > 0 && *timeline[len(timeline)-1].Clock > end {
+		end = *timeline[len(timeline)-1].Clock
+	}
+	return end
+}
+
+// timedEvents returns the events of the period that have a game clock, in the
+// order they happened.
+func timedEvents(events []*models.Event, period string) []*models.Event {
+	var timeline []*models.Event
+	for _, event := range events {
+		if event.Period == period && event.Clock != nil {
+			timeline = append(timeline, event)
+		}
+	}
+
+	sort.SliceStable(timeline, func(i, j int) bool {
+		if *timeline[i].Clock != *timeline[j].Clock {
+			return *timeline[i].Clock < *timeline[j].Clock
+		}
+		return timeline[i].CreatedAt.Before(timeline[j].CreatedAt)
+	})
+	return timeline
+}
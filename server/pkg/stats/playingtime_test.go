@@ -0,0 +1,119 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Tchoukball-Tracker/pkg/models"
+)
+
+// timed returns an event of the first period at a clock, recorded at the
+// given second of the test so that events at the same clock keep an order.
+func timed(recorded, clock int, event models.Event) *models.Event {
+	event.Period = "first"
+	event.Clock = &clock
+	event.CreatedAt = time.Date(2024, 5, 1, 18, 0, recorded, 0, time.UTC)
+	return &event
+}
+
+func TestPlayingTime(t *testing.T) {
+	lineups := map[string][]string{"first": {"Alex", "Sam"}}
+	start := models.Event{Type: models.EventPeriodStart}
+	end := models.Event{Type: models.EventPeriodEnd}
+	point := models.Event{Type: models.EventAction, Action: "point", Value: 1}
+	conceded := models.Event{Type: models.EventConceded, Value: 1}
+	substitute := func(out, in string) models.Event {
+		return models.Event{Type: models.EventSubstitution, PlayerOut: out, PlayerIn: in}
+	}
+
+	tests := []struct {
+		name   string
+		status string
+		events []*models.Event
+		want   map[string]models.PeriodTime
+	}{
+		{
+			name:   "lineup plays the whole period",
+			status: models.MatchStatusFinished,
+			events: []*models.Event{timed(0, 0, start), timed(1, 100, point), timed(2, 200, conceded), timed(3, 600, end)},
+			want:   map[string]models.PeriodTime{"Alex": {Seconds: 600}, "Sam": {Seconds: 600}},
+		},
+		{
+			name:   "substitution during the period",
+			status: models.MatchStatusFinished,
+			events: []*models.Event{
+				timed(0, 0, start),
+				timed(1, 100, point),
+				timed(2, 250, substitute("Alex", "Kim")),
+				timed(3, 300, point),
+				timed(4, 400, conceded),
+				timed(5, 600, end),
+			},
+			want: map[string]models.PeriodTime{"Alex": {Seconds: 250, PlusMinus: 1}, "Kim": {Seconds: 350}, "Sam": {Seconds: 600, PlusMinus: 1}},
+		},
+		{
+			name:   "substitution before the period starts",
+			status: models.MatchStatusFinished,
+			events: []*models.Event{timed(0, 0, substitute("Alex", "Kim")), timed(1, 0, start), timed(2, 100, point), timed(3, 600, end)},
+			want:   map[string]models.PeriodTime{"Alex": {}, "Kim": {Seconds: 600, PlusMinus: 1}, "Sam": {Seconds: 600, PlusMinus: 1}},
+		},
+		{
+			name:   "substitution recorded late at the start",
+			status: models.MatchStatusFinished,
+			events: []*models.Event{timed(1, 0, substitute("Alex", "Kim")), timed(0, 0, start), timed(2, 0, point), timed(3, 600, end)},
+			want:   map[string]models.PeriodTime{"Alex": {}, "Kim": {Seconds: 600, PlusMinus: 1}, "Sam": {Seconds: 600, PlusMinus: 1}},
+		},
+		{
+			name:   "untimed substitution",
+			status: models.MatchStatusFinished,
+			events: []*models.Event{timed(0, 0, start), {Period: "first", Type: models.EventSubstitution, PlayerOut: "Alex", PlayerIn: "Kim"}},
+			want:   map[string]models.PeriodTime{"Alex": {Seconds: 600}, "Sam": {Seconds: 600}},
+		},
+		{
+			name:   "finished match without an end event",
+			status: models.MatchStatusFinished,
+			events: []*models.Event{timed(0, 0, start), timed(1, 100, point)},
+			want:   map[string]models.PeriodTime{"Alex": {Seconds: 600, PlusMinus: 1}, "Sam": {Seconds: 600, PlusMinus: 1}},
+		},
+		{
+			name:   "live period without an end event",
+			status: models.MatchStatusLive,
+			events: []*models.Event{timed(0, 0, start), timed(1, 100, substitute("Sam", "Kim")), timed(2, 240, conceded)},
+			want:   map[string]models.PeriodTime{"Alex": {Seconds: 240, PlusMinus: -1}, "Kim": {Seconds: 140, PlusMinus: -1}, "Sam": {Seconds: 100}},
+		},
+		{
+			name:   "live period not started",
+			status: models.MatchStatusLive,
+			want:   map[string]models.PeriodTime{"Alex": {}, "Sam": {}},
+		},
+		{
+			name:   "live period ended",
+			status: models.MatchStatusLive,
+			events: []*models.Event{timed(0, 0, start), timed(1, 100, point), timed(2, 600, end)},
+			want:   map[string]models.PeriodTime{"Alex": {Seconds: 600, PlusMinus: 1}, "Sam": {Seconds: 600, PlusMinus: 1}},
+		},
+		{
+			name:   "events past the period length",
+			status: models.MatchStatusFinished,
+			events: []*models.Event{timed(0, 0, start), timed(1, 650, point)},
+			want:   map[string]models.PeriodTime{"Alex": {Seconds: 650, PlusMinus: 1}, "Sam": {Seconds: 650, PlusMinus: 1}},
+		},
+	}
+
+	for _, test := range tests {
+		match := &models.Match{Status: test.status, Lineups: lineups, PeriodLength: 600}
+		got := make(map[string]models.PeriodTime)
+		for _, player := range PlayingTime(match, test.events) {
+			period := player.Periods["first"]
+			if period == nil || player.Seconds != period.Seconds || player.PlusMinus != period.PlusMinus {
+				t.Errorf("%s: totals of %s do not add up to the first period: %+v", test.name, player.Player, player)
+				continue
+			}
+			got[player.Player] = *period
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
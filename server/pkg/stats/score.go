@@ -0,0 +1,25 @@
+package stats
+
+import "github.com/Tchoukball-Tracker/pkg/models"
+
+// PeriodScores returns the score of every third in the order they are
+// played, counting the points of the tracked players as scored and the
+// conceded events as the points of the opponent.
+func PeriodScores(matchStats *models.MatchStats, events []*models.Event) []models.PeriodScore {
+	conceded := make(map[string]int)
+	for _, event := range events {
+		if event.Type == models.EventConceded {
+			conceded[event.Period] += event.Value
+		}
+	}
+
+	scores := make([]models.PeriodScore, 0, len(models.Periods))
+	for _, period := range models.Periods {
+		score := models.PeriodScore{Period: period, Conceded: conceded[period]}
+		if counters, ok := matchStats.Periods[period]; ok {
+			score.Scored = counters.Attacking.Point
+		}
+		scores = append(scores, score)
+	}
+	return scores
+}
@@ -0,0 +1,25 @@
+package stats
+
+import (
+	"github.com/Tchoukball-Tracker/pkg/models"
+)
+
+// Series lays out the named counters and metrics as one series each, with a
+// value per label. values holds the counter and derived metric values of each
+// label in the same order as labels. Values that cannot be computed, such as
+// a metric that has since been deleted, are left as gaps.
+func Series(labels []string, values []map[string]float64, names []string, custom []*CustomMetric) *models.SeriesData {
+	data := &models.SeriesData{Labels: labels, Series: []models.Series{}}
+	for _, name := range names {
+		series := models.Series{Name: name, Values: make([]*float64, len(labels))}
+		if value, ok := Resolve(name, custom); ok {
+			for i := range labels {
+				if v, err := value(values[i]); err == nil {
+					series.Values[i] = &v
+				}
+			}
+		}
+		data.Series = append(data.Series, series)
+	}
+	return data
+}
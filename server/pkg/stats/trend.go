@@ -0,0 +1,92 @@
+package stats
+
+import (
+	"math"
+
+	"github.com/Tchoukball-Tracker/pkg/metrics"
+	"github.com/Tchoukball-Tracker/pkg/models"
+)
+
+// Trend computes the metric for each match in order with a rolling average
+// over window matches. A rolling average is flagged as an improvement or
+// decline when it is more than threshold standard errors away from the
+// subject's baseline, the mean over every match in the series. If
+// lowerIsBetter is set a falling average is an improvement.
+func Trend(subject, metric string, rows []*models.MatchTotals, value ValueFunc, window int, threshold float64, lowerIsBetter bool) *models.Trend {
+	trend := &models.Trend{
+		Subject:   subject,
+		Metric:    metric,
+		Window:    window,
+		Threshold: threshold,
+		Points:    []*models.TrendPoint{},
+	}
+
+	var known []float64
+	for _, row := range rows {
+		point := &models.TrendPoint{Match: row.Match, Name: row.Name, Date: row.CreatedAt}
+		if v, err := value(metrics.Values(row.Counters)); err == nil {
+			point.Value = &v
+			known = append(known, v)
+		}
+		trend.Points = append(trend.Points, point)
+	}
+
+	trend.Baseline, trend.StdDev = meanStdDev(known)
+	standardError := trend.StdDev / math.Sqrt(float64(window))
+
+	// Rolling average of the last window values, skipping matches the metric
+	// could not be computed for
+	var recent []float64
+	for _, point := range trend.Points {
+		if point.Value == nil {
+			continue
+		}
+		recent = append(recent, *point.Value)
+		if len(recent) > window {
+			recent = recent[1:]
+		}
+		if len(recent) < window {
+			continue
+		}
+
+		average, _ := meanStdDev(recent)
+		point.RollingAverage = &average
+		if standardError == 0 || math.Abs(average-trend.Baseline) <= threshold*standardError {
+			continue
+		}
+		if (average > trend.Baseline) != lowerIsBetter {
+			point.Flag = models.TrendImprovement
+		} else {
+			point.Flag = models.TrendDecline
+		}
+	}
+
+	values := models.Series{Name: metric}
+	averages := models.Series{Name: "Rolling average"}
+	for _, point := range trend.Points {
+		trend.Series.Labels = append(trend.Series.Labels, point.Name)
+		values.Values = append(values.Values, point.Value)
+		averages.Values = append(averages.Values, point.RollingAverage)
+	}
+	trend.Series.Series = []models.Series{values, averages}
+	return trend
+}
+
+// meanStdDev returns the mean and population standard deviation of values.
+func meanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)))
+}
@@ -0,0 +1,319 @@
+// Package tournament schedules pool and knockout fixtures for weekend tournaments.
+package tournament
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Tchoukball-Tracker/pkg/league"
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrPoolsUnfinished = errors.New("All pool matches must be finished before seeding the knockout")
+	ErrAlreadySeeded   = errors.New("The knockout has already been seeded")
+)
+
+// DrawPools splits the teams into count pools in snake order, so the teams
+// listed first are spread evenly across the pools, and schedules a round robin
+// for each pool.
+func DrawPools(teams []string, count int) []*models.Pool {
+	pools := make([]*models.Pool, count)
+	for i := range pools {
+		pools[i] = &models.Pool{Name: fmt.Sprintf("Pool %c", 'A'+i)}
+	}
+
+	for i, team := range teams {
+		index := i % count
+		if (i/count)%2 == 1 {
+			index = count - 1 - index
+		}
+		pools[index].Teams = append(pools[index].Teams, team)
+	}
+
+	for _, pool := range pools {
+		pool.Fixtures = RoundRobin(pool.Teams)
+	}
+	return pools
+}
+
+// RoundRobin schedules every team to play every other team once using the
+// circle method. Teams with a bye in a round are left out of that round.
+func RoundRobin(teams []string) []*models.Fixture {
+	circle := append([]string{}, teams...)
+	if len(circle)%2 == 1 {
+		circle = append(circle, "")
+	}
+
+	var fixtures []*models.Fixture
+	for round := 0; round < len(circle)-1; round++ {
+		for i := 0; i < len(circle)/2; i++ {
+			home, away := circle[i], circle[len(circle)-1-i]
+			if home == "" || away == "" {
+				continue
+			}
+			// Alternate the fixed team between home and away
+			if i == 0 && round%2 == 1 {
+				home, away = away, home
+			}
+			fixtures = append(fixtures, &models.Fixture{Round: round + 1, HomeTeam: home, AwayTeam: away})
+		}
+
+		// Keep the first team fixed and rotate the rest clockwise
+		last := circle[len(circle)-1]
+		copy(circle[2:], circle[1:len(circle)-1])
+		circle[1] = last
+	}
+	return fixtures
+}
+
+// PoolStandings computes the table of a pool from the finished pool matches.
+func PoolStandings(pool *models.Pool, matches map[primitive.ObjectID]*models.Match) []*models.Standing {
+	var played []*models.Match
+	for _, fixture := range pool.Fixtures {
+		if match, ok := matches[fixture.Match]; ok {
+			played = append(played, match)
+		}
+	}
+
+	standings := league.Table(played, models.DefaultLeagueRules())
+
+	// Teams that have not finished a match yet still belong in the table
+	listed := make(map[string]bool, len(standings))
+	for _, s := range standings {
+		listed[s.Team] = true
+	}
+	for _, team := range pool.Teams {
+		if !listed[team] {
+			standings = append(standings, &models.Standing{Position: len(standings) + 1, Team: team})
+		}
+	}
+	return standings
+}
+
+// Qualifiers returns the teams that progress from the pools in seeding order.
+// Pool winners are seeded first, then runners-up and so on. Teams finishing in
+// the same position are ordered by league points, points difference and
+// points scored.
+func Qualifiers(t *models.Tournament, matches map[primitive.ObjectID]*models.Match) []string {
+	byPosition := make([][]*models.Standing, t.QualifiersPerPool)
+	for _, pool := range t.Pools {
+		for _, s := range PoolStandings(pool, matches) {
+			if s.Position <= t.QualifiersPerPool {
+				byPosition[s.Position-1] = append(byPosition[s.Position-1], s)
+			}
+		}
+	}
+
+	var seeds []string
+	for _, standings := range byPosition {
+		sort.SliceStable(standings, func(i, j int) bool {
+			a, b := standings[i], standings[j]
+			if a.LeaguePoints != b.LeaguePoints {
+				return a.LeaguePoints > b.LeaguePoints
+			}
+			if a.PointsDifference != b.PointsDifference {
+				return a.PointsDifference > b.PointsDifference
+			}
+			return a.PointsFor > b.PointsFor
+		})
+		for _, s := range standings {
+			seeds = append(seeds, s.Team)
+		}
+	}
+	return seeds
+}
+
+// PoolsFinished reports whether every pool fixture has a result.
+func PoolsFinished(t *models.Tournament) bool {
+	for _, pool := range t.Pools {
+		for _, fixture := range pool.Fixtures {
+			if !fixture.Finished {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Seed builds the knockout bracket from the pool standings. The top seeds
+// receive byes when the number of qualifiers is not a power of two.
+func Seed(t *models.Tournament, matches map[primitive.ObjectID]*models.Match) error {
+	if len(t.Knockout) > 0 {
+		return ErrAlreadySeeded
+	}
+	if !PoolsFinished(t) {
+		return ErrPoolsUnfinished
+	}
+
+	seeds := Qualifiers(t, matches)
+	if len(seeds) < 2 {
+		t.Status = models.TournamentStatusFinished
+		if len(seeds) == 1 {
+			t.Winner = seeds[0]
+		}
+		return nil
+	}
+
+	size := 1
+	for size < len(seeds) {
+		size *= 2
+	}
+
+	var rounds []*models.Round
+	for fixtures := size / 2; fixtures >= 1; fixtures /= 2 {
+		round := &models.Round{Name: roundName(fixtures)}
+		for i := 0; i < fixtures; i++ {
+			round.Fixtures = append(round.Fixtures, &models.Fixture{Round: len(rounds) + 1})
+		}
+		rounds = append(rounds, round)
+	}
+
+	order := bracketOrder(size)
+	for i, fixture := range rounds[0].Fixtures {
+		fixture.HomeTeam = seed(seeds, order[2*i])
+		fixture.AwayTeam = seed(seeds, order[2*i+1])
+		if fixture.AwayTeam == "" {
+			fixture.Finished = true
+			fixture.Winner = fixture.HomeTeam
+		}
+	}
+
+	t.Knockout = rounds
+	t.Status = models.TournamentStatusKnockout
+	propagate(t)
+	return nil
+}
+
+// RecordResult marks the fixture played as match as finished and moves the
+// winner of a knockout fixture into the next round. It reports whether the
+// match belongs to the tournament.
+func RecordResult(t *models.Tournament, match *models.Match) bool {
+	for _, pool := range t.Pools {
+		for _, fixture := range pool.Fixtures {
+			if fixture.Match == match.ID {
+				fixture.Finished = match.IsFinished()
+				fixture.Winner = match.Winner()
+				return true
+			}
+		}
+	}
+
+	for _, round := range t.Knockout {
+		for _, fixture := range round.Fixtures {
+			if fixture.Match == match.ID {
+				// A drawn knockout match cannot advance anyone until it is decided
+				fixture.Winner = match.Winner()
+				fixture.Finished = fixture.Winner != ""
+				propagate(t)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReadyFixtures returns the fixtures that need a match to be created.
+func ReadyFixtures(t *models.Tournament) []*models.Fixture {
+	var ready []*models.Fixture
+	for _, pool := range t.Pools {
+		for _, fixture := range pool.Fixtures {
+			if fixture.Ready() {
+				ready = append(ready, fixture)
+			}
+		}
+	}
+	for _, round := range t.Knockout {
+		for _, fixture := range round.Fixtures {
+			if fixture.Ready() {
+				ready = append(ready, fixture)
+			}
+		}
+	}
+	return ready
+}
+
+// Stage returns the name of the pool or knockout round the fixture belongs to.
+func Stage(t *models.Tournament, fixture *models.Fixture) string {
+	for _, pool := range t.Pools {
+		for _, f := range pool.Fixtures {
+			if f == fixture {
+				return pool.Name
+			}
+		}
+	}
+	for _, round := range t.Knockout {
+		for i, f := range round.Fixtures {
+			if f == fixture {
+				if len(round.Fixtures) == 1 {
+					return round.Name
+				}
+				return fmt.Sprintf("%s %d", round.Name, i+1)
+			}
+		}
+	}
+	return ""
+}
+
+// propagate moves the winner of every knockout fixture into its slot in the
+// next round and finishes the tournament once the final is decided. A fixture
+// left without a winner after a correction empties its slot, and a final left
+// without one reopens the tournament, so nothing stale is carried forward.
+func propagate(t *models.Tournament) {
+	for r, round := range t.Knockout {
+		for i, fixture := range round.Fixtures {
+			if r == len(t.Knockout)-1 {
+				t.Winner = fixture.Winner
+				if fixture.Winner != "" {
+					t.Status = models.TournamentStatusFinished
+				} else {
+					t.Status = models.TournamentStatusKnockout
+				}
+				continue
+			}
+
+			next := t.Knockout[r+1].Fixtures[i/2]
+			if i%2 == 0 {
+				next.HomeTeam = fixture.Winner
+			} else {
+				next.AwayTeam = fixture.Winner
+			}
+		}
+	}
+}
+
+// bracketOrder returns the seed numbers in bracket order, so that the top two
+// seeds can only meet in the final. For a size of 8 it returns
+// 1, 8, 4, 5, 2, 7, 3, 6.
+func bracketOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		next := make([]int, 0, len(order)*2)
+		for _, s := range order {
+			next = append(next, s, 2*len(order)+1-s)
+		}
+		order = next
+	}
+	return order
+}
+
+func seed(seeds []string, number int) string {
+	if number > len(seeds) {
+		return ""
+	}
+	return seeds[number-1]
+}
+
+func roundName(fixtures int) string {
+	switch fixtures {
+	case 1:
+		return "Final"
+	case 2:
+		return "Semi-final"
+	case 4:
+		return "Quarter-final"
+	}
+	return fmt.Sprintf("Round of %d", fixtures*2)
+}
@@ -0,0 +1,234 @@
+package tournament
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/Tchoukball-Tracker/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// playedPool returns a tournament whose single pool of n teams has finished,
+// with every team beating those listed after it, so the seeds follow the
+// order of the teams.
+func playedPool(n int) (*models.Tournament, map[primitive.ObjectID]*models.Match) {
+	teams := make([]string, n)
+	for i := range teams {
+		teams[i] = fmt.Sprintf("T%d", i+1)
+	}
+
+	t := &models.Tournament{Teams: teams, PoolCount: 1, QualifiersPerPool: n}
+	t.Pools = DrawPools(teams, 1)
+
+	rank := make(map[string]int, n)
+	for i, team := range teams {
+		rank[team] = i
+	}
+
+	matches := make(map[primitive.ObjectID]*models.Match)
+	for _, fixture := range t.Pools[0].Fixtures {
+		match := &models.Match{
+			ID:        primitive.NewObjectID(),
+			HomeTeam:  fixture.HomeTeam,
+			AwayTeam:  fixture.AwayTeam,
+			HomeScore: 5,
+			AwayScore: 10,
+			Status:    models.MatchStatusFinished,
+		}
+		if rank[fixture.HomeTeam] < rank[fixture.AwayTeam] {
+			match.HomeScore, match.AwayScore = 10, 5
+		}
+		fixture.Match = match.ID
+		fixture.Finished = true
+		fixture.Winner = match.Winner()
+		matches[match.ID] = match
+	}
+	return t, matches
+}
+
+// pairs lists the teams of every fixture of a round, a missing team as "-".
+func pairs(round *models.Round) []string {
+	var got []string
+	for _, fixture := range round.Fixtures {
+		home, away := fixture.HomeTeam, fixture.AwayTeam
+		if home == "" {
+			home = "-"
+		}
+		if away == "" {
+			away = "-"
+		}
+		got = append(got, home+" v "+away)
+	}
+	return got
+}
+
+func TestSeed(t *testing.T) {
+	tests := []struct {
+		teams  int
+		rounds []string
+		first  []string
+		second []string
+	}{
+		{
+			teams:  3,
+			rounds: []string{"Semi-final", "Final"},
+			first:  []string{"T1 v -", "T2 v T3"},
+			second: []string{"T1 v -"},
+		},
+		{
+			teams:  5,
+			rounds: []string{"Quarter-final", "Semi-final", "Final"},
+			first:  []string{"T1 v -", "T4 v T5", "T2 v -", "T3 v -"},
+			second: []string{"T1 v -", "T2 v T3"},
+		},
+		{
+			teams:  6,
+			rounds: []string{"Quarter-final", "Semi-final", "Final"},
+			first:  []string{"T1 v -", "T4 v T5", "T2 v -", "T3 v T6"},
+			second: []string{"T1 v -", "T2 v -"},
+		},
+		{
+			teams:  8,
+			rounds: []string{"Quarter-final", "Semi-final", "Final"},
+			first:  []string{"T1 v T8", "T4 v T5", "T2 v T7", "T3 v T6"},
+			second: []string{"- v -", "- v -"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%d teams", test.teams), func(t *testing.T) {
+			tournament, matches := playedPool(test.teams)
+			if err := Seed(tournament, matches); err != nil {
+				t.Fatal(err)
+			}
+
+			var rounds []string
+			for _, round := range tournament.Knockout {
+				rounds = append(rounds, round.Name)
+			}
+			if !reflect.DeepEqual(rounds, test.rounds) {
+				t.Errorf("got rounds %v, want %v", rounds, test.rounds)
+			}
+			if got := pairs(tournament.Knockout[0]); !reflect.DeepEqual(got, test.first) {
+				t.Errorf("got first round %v, want %v", got, test.first)
+			}
+			if got := pairs(tournament.Knockout[1]); !reflect.DeepEqual(got, test.second) {
+				t.Errorf("got second round %v, want %v", got, test.second)
+			}
+
+			// Only the fixtures with a bye are decided straight away
+			for _, fixture := range tournament.Knockout[0].Fixtures {
+				if fixture.Finished != (fixture.AwayTeam == "") {
+					t.Errorf("fixture %s v %s finished is %v", fixture.HomeTeam, fixture.AwayTeam, fixture.Finished)
+				}
+			}
+		})
+	}
+}
+
+func TestSeedErrors(t *testing.T) {
+	tournament, matches := playedPool(4)
+	tournament.Pools[0].Fixtures[0].Finished = false
+	if err := Seed(tournament, matches); err != ErrPoolsUnfinished {
+		t.Errorf("got %v with a pool match unfinished, want %v", err, ErrPoolsUnfinished)
+	}
+
+	tournament.Pools[0].Fixtures[0].Finished = true
+	if err := Seed(tournament, matches); err != nil {
+		t.Fatal(err)
+	}
+	if err := Seed(tournament, matches); err != ErrAlreadySeeded {
+		t.Errorf("got %v seeding twice, want %v", err, ErrAlreadySeeded)
+	}
+}
+
+func TestRecordResultCorrection(t *testing.T) {
+	tournament, matches := playedPool(4)
+	if err := Seed(tournament, matches); err != nil {
+		t.Fatal(err)
+	}
+
+	semi := tournament.Knockout[0].Fixtures[0]
+	match := &models.Match{ID: primitive.NewObjectID(), HomeTeam: semi.HomeTeam, AwayTeam: semi.AwayTeam, Status: models.MatchStatusFinished}
+	semi.Match = match.ID
+
+	match.HomeScore, match.AwayScore = 10, 5
+	if !RecordResult(tournament, match) {
+		t.Fatal("match not found in the tournament")
+	}
+	if final := tournament.Knockout[1].Fixtures[0]; final.HomeTeam != "T1" {
+		t.Errorf("got %q in the final, want T1", final.HomeTeam)
+	}
+
+	match.HomeScore, match.AwayScore = 5, 10
+	RecordResult(tournament, match)
+	if final := tournament.Knockout[1].Fixtures[0]; final.HomeTeam != "T4" {
+		t.Errorf("got %q in the final after the correction, want T4", final.HomeTeam)
+	}
+}
+
+func TestRecordResultCorrectionClearsWinner(t *testing.T) {
+	tournament, matches := playedPool(4)
+	if err := Seed(tournament, matches); err != nil {
+		t.Fatal(err)
+	}
+
+	// Play both semi-finals and the final
+	play := func(fixture *models.Fixture, homeScore, awayScore int) *models.Match {
+		match := &models.Match{ID: primitive.NewObjectID(), HomeTeam: fixture.HomeTeam, AwayTeam: fixture.AwayTeam, HomeScore: homeScore, AwayScore: awayScore, Status: models.MatchStatusFinished}
+		fixture.Match = match.ID
+		RecordResult(tournament, match)
+		return match
+	}
+	semi := play(tournament.Knockout[0].Fixtures[0], 10, 5)
+	play(tournament.Knockout[0].Fixtures[1], 10, 5)
+	final := play(tournament.Knockout[1].Fixtures[0], 10, 5)
+	if tournament.Winner != "T1" || tournament.Status != models.TournamentStatusFinished {
+		t.Fatalf("got winner %q and status %s, want T1 and finished", tournament.Winner, tournament.Status)
+	}
+
+	// Correcting the final to a draw reopens the tournament
+	final.HomeScore, final.AwayScore = 10, 10
+	RecordResult(tournament, final)
+	if tournament.Winner != "" || tournament.Status != models.TournamentStatusKnockout {
+		t.Errorf("got winner %q and status %s after the correction, want none and knockout", tournament.Winner, tournament.Status)
+	}
+
+	// An empty winner must still be stored, as updates $set the tournament
+	data, err := bson.Marshal(tournament)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bson.Raw(data).LookupErr("winner"); err != nil {
+		t.Errorf("empty tournament winner left out of the document: %v", err)
+	}
+	if _, err := bson.Raw(data).LookupErr("knockout", "1", "fixtures", "0", "winner"); err != nil {
+		t.Errorf("empty fixture winner left out of the document: %v", err)
+	}
+
+	// Correcting a semi-final to a draw empties its slot in the final
+	semi.HomeScore, semi.AwayScore = 5, 5
+	RecordResult(tournament, semi)
+	if home := tournament.Knockout[1].Fixtures[0].HomeTeam; home != "" {
+		t.Errorf("got %q in the final after the semi-final was drawn, want none", home)
+	}
+}
+
+func TestBracketOrder(t *testing.T) {
+	tests := []struct {
+		size int
+		want []int
+	}{
+		{size: 2, want: []int{1, 2}},
+		{size: 4, want: []int{1, 4, 2, 3}},
+		{size: 8, want: []int{1, 8, 4, 5, 2, 7, 3, 6}},
+	}
+
+	for _, test := range tests {
+		if got := bracketOrder(test.size); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("bracketOrder(%d) = %v, want %v", test.size, got, test.want)
+		}
+	}
+}
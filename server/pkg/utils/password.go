@@ -3,10 +3,9 @@ package utils
 
 import "golang.org/x/crypto/bcrypt"
 
-// HashPassword hashes the given password with a cost of 5
+// HashPassword hashes the given password with the default bcrypt cost
 func HashPassword(password string) (string, error) {
-	const cost = 5
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	return string(bytes), err
 }
 
@@ -0,0 +1,180 @@
+// Package webhook signs and sends webhook requests.
+//
+// Each request is a JSON POST carrying the headers:
+//
+//	X-Tchoukball-Event      the event, such as point.recorded
+//	X-Tchoukball-Delivery   the ID of the delivery, new for every replay
+//	X-Tchoukball-Timestamp  the Unix time the request was signed at
+//	X-Tchoukball-Signature  sha256=<hex HMAC-SHA256 of "<timestamp>.<body>">
+//
+// Receivers should recompute the signature with the webhook secret and reject
+// requests whose timestamp is too old to guard against replayed requests.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	HeaderEvent     = "X-Tchoukball-Event"
+	HeaderDelivery  = "X-Tchoukball-Delivery"
+	HeaderTimestamp = "X-Tchoukball-Timestamp"
+	HeaderSignature = "X-Tchoukball-Signature"
+)
+
+var (
+	// MaxAttempts is the number of times a delivery is tried before it fails.
+	MaxAttempts = 6
+	// BaseDelay is the wait before the first retry, doubled for every retry after it.
+	BaseDelay = 2 * time.Second
+	// MaxDelay caps the wait between two attempts.
+	MaxDelay = 5 * time.Minute
+)
+
+var (
+	// ErrInvalidURL is returned for a URL that is not an absolute http or https URL.
+	ErrInvalidURL = errors.New("Webhook URL must be an absolute http or https URL")
+	// ErrUnresolvedHost is returned for a URL whose host cannot be resolved.
+	ErrUnresolvedHost = errors.New("Webhook URL host cannot be resolved")
+	// ErrForbiddenAddress is returned for a URL that points to a loopback,
+	// private, link-local or otherwise internal address.
+	ErrForbiddenAddress = errors.New("Webhook URL must not point to a loopback, private or link-local address")
+)
+
+// client only connects to allowed addresses, checking the address actually
+// dialled so that redirects and hosts resolving to an internal address after
+// they were validated are still refused. Proxies are not used for the same
+// reason.
+var client = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				if ip := net.ParseIP(host); ip == nil || !Allowed(ip) {
+					return ErrForbiddenAddress
+				}
+				return nil
+			},
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+	},
+}
+
+// Public reports whether an address can be reached from the internet, as
+// opposed to loopback, private, link-local, multicast or unspecified ones.
+func Public(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// Allowed reports whether webhooks may be delivered to an address. Only public
+// addresses are allowed unless WEBHOOK_ALLOW_PRIVATE is set to true, which
+// also allows loopback and private addresses for receivers on the local
+// network or a local test server. Link-local addresses, which cloud metadata
+// services listen on, are never allowed.
+func Allowed(ip net.IP) bool {
+	if Public(ip) {
+		return true
+	}
+	return os.Getenv("WEBHOOK_ALLOW_PRIVATE") == "true" && (ip.IsLoopback() || ip.IsPrivate())
+}
+
+// CheckURL checks that a webhook URL is an absolute http or https URL whose
+// host only resolves to allowed addresses.
+func CheckURL(ctx context.Context, rawURL string) error {
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Hostname() == "" {
+		return ErrInvalidURL
+	}
+
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, target.Hostname())
+	if err != nil || len(addresses) == 0 {
+		return ErrUnresolvedHost
+	}
+	for _, address := range addresses {
+		if !Allowed(address.IP) {
+			return ErrForbiddenAddress
+		}
+	}
+	return nil
+}
+
+// NewSecret returns a random signing secret.
+func NewSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// Sign returns the signature header value of a body sent at the given time.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff returns how long to wait after the given failed attempt, counted
+// from 1, before trying again.
+func Backoff(attempt int) time.Duration {
+	delay := BaseDelay
+	for i := 1; i < attempt && delay < MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > MaxDelay {
+		return MaxDelay
+	}
+	return delay
+}
+
+// Send signs and POSTs a body to url, returning the response status code. A
+// response outside 2xx is returned as an error along with its status code.
+func Send(ctx context.Context, url, secret, event, delivery string, body []byte) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "Tchoukball-Tracker-Webhook")
+	request.Header.Set(HeaderEvent, event)
+	request.Header.Set(HeaderDelivery, delivery)
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, Sign(secret, timestamp, body))
+
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("webhook responded %s", response.Status)
+	}
+	return response.StatusCode, nil
+}
@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{url: "https://203.0.113.7/hook", want: nil},
+		{url: "http://[2001:4860:4860::8888]:8080/hook", want: nil},
+		{url: "ftp://203.0.113.7/hook", want: ErrInvalidURL},
+		{url: "/hook", want: ErrInvalidURL},
+		{url: "http://127.0.0.1/hook", want: ErrForbiddenAddress},
+		{url: "http://localhost:8080/hook", want: ErrForbiddenAddress},
+		{url: "http://[::1]/hook", want: ErrForbiddenAddress},
+		{url: "http://10.0.0.5/hook", want: ErrForbiddenAddress},
+		{url: "http://172.16.0.1/hook", want: ErrForbiddenAddress},
+		{url: "http://192.168.1.1/hook", want: ErrForbiddenAddress},
+		{url: "http://169.254.169.254/latest/meta-data", want: ErrForbiddenAddress},
+		{url: "http://[fe80::1]/hook", want: ErrForbiddenAddress},
+		{url: "http://[fd00::1]/hook", want: ErrForbiddenAddress},
+		{url: "http://0.0.0.0/hook", want: ErrForbiddenAddress},
+	}
+
+	for _, test := range tests {
+		if err := CheckURL(context.Background(), test.url); !errors.Is(err, test.want) {
+			t.Errorf("CheckURL(%q) = %v, want %v", test.url, err, test.want)
+		}
+	}
+}
+
+func TestSendRefusesInternalAddress(t *testing.T) {
+	if _, err := Send(context.Background(), "http://127.0.0.1:1/hook", "secret", "match.created", "1", nil); !errors.Is(err, ErrForbiddenAddress) {
+		t.Errorf("Send to loopback error = %v, want %v", err, ErrForbiddenAddress)
+	}
+}
+
+func TestCheckURLAllowPrivate(t *testing.T) {
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE", "true")
+
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{url: "http://127.0.0.1:8080/hook", want: nil},
+		{url: "http://localhost/hook", want: nil},
+		{url: "http://192.168.1.20/scoreboard", want: nil},
+		{url: "http://[fd00::1]/hook", want: nil},
+		{url: "http://169.254.169.254/latest/meta-data", want: ErrForbiddenAddress},
+		{url: "http://[fe80::1]/hook", want: ErrForbiddenAddress},
+		{url: "http://0.0.0.0/hook", want: ErrForbiddenAddress},
+	}
+
+	for _, test := range tests {
+		if err := CheckURL(context.Background(), test.url); !errors.Is(err, test.want) {
+			t.Errorf("CheckURL(%q) = %v, want %v", test.url, err, test.want)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE", "true")
+
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	payload := []byte(`{"event":"match.created"}`)
+	status, err := Send(context.Background(), server.URL, "secret", "match.created", "delivery-1", payload)
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("Send = %d, %v, want %d", status, err, http.StatusOK)
+	}
+
+	timestamp, err := strconv.ParseInt(received.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		t.Fatalf("timestamp header %q: %v", received.Header.Get(HeaderTimestamp), err)
+	}
+	if got, want := received.Header.Get(HeaderSignature), Sign("secret", timestamp, payload); got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+	if received.Header.Get(HeaderEvent) != "match.created" || received.Header.Get(HeaderDelivery) != "delivery-1" {
+		t.Errorf("event and delivery headers = %q, %q", received.Header.Get(HeaderEvent), received.Header.Get(HeaderDelivery))
+	}
+	if string(body) != string(payload) {
+		t.Errorf("body = %s, want %s", body, payload)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: BaseDelay},
+		{attempt: 2, want: 2 * BaseDelay},
+		{attempt: 3, want: 4 * BaseDelay},
+		{attempt: 20, want: MaxDelay},
+	}
+
+	for _, test := range tests {
+		if got := Backoff(test.attempt); got != test.want {
+			t.Errorf("Backoff(%d) = %v, want %v", test.attempt, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// MaxEntrySize is the largest a part of a workbook may be once decompressed,
+// so that a small upload cannot expand to exhaust memory.
+const MaxEntrySize = 64 << 20
+
+const (
+	maxColumns = 16384   // Columns a worksheet can have
+	maxCells   = 1 << 20 // Cells returned, counting the empty ones filling gaps
+)
+
+var (
+	ErrNoSheets = errors.New("Workbook has no sheets")
+	ErrTooLarge = errors.New("Workbook is too large once decompressed")
+)
+
+// ReadSheet returns the values of the named sheet row by row, or of the first
+// sheet if name is empty. Missing cells are returned as empty strings.
+func ReadSheet(r io.ReaderAt, size int64, name string) ([][]string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid XLSX file: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decode(files, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, ErrNoSheets
+	}
+
+	sheetID := workbook.Sheets[0].ID
+	if name != "" {
+		sheetID = ""
+		for _, sheet := range workbook.Sheets {
+			if sheet.Name == name {
+				sheetID = sheet.ID
+			}
+		}
+		if sheetID == "" {
+			return nil, fmt.Errorf("Workbook has no sheet named %q", name)
+		}
+	}
+
+	var relationships struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decode(files, "xl/_rels/workbook.xml.rels", &relationships); err != nil {
+		return nil, err
+	}
+
+	sheetPath := ""
+	for _, relationship := range relationships.Relationships {
+		if relationship.ID == sheetID {
+			sheetPath = relationship.Target
+			if strings.HasPrefix(sheetPath, "/") {
+				sheetPath = strings.TrimPrefix(sheetPath, "/")
+			} else {
+				sheetPath = path.Join("xl", sheetPath)
+			}
+		}
+	}
+
+	var sharedStrings struct {
+		Items []richText `xml:"si"`
+	}
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decode(files, "xl/sharedStrings.xml", &sharedStrings); err != nil {
+			return nil, err
+		}
+	}
+
+	var worksheet struct {
+		Rows []struct {
+			Cells []struct {
+				Ref    string   `xml:"r,attr"`
+				Type   string   `xml:"t,attr"`
+				Value  string   `xml:"v"`
+				Inline richText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := decode(files, sheetPath, &worksheet); err != nil {
+		return nil, err
+	}
+
+	cells := 0
+	rows := make([][]string, 0, len(worksheet.Rows))
+	for _, row := range worksheet.Rows {
+		var values []string
+		for _, cell := range row.Cells {
+			column := len(values)
+			if cell.Ref != "" {
+				if column, err = columnIndex(cell.Ref); err != nil {
+					return nil, err
+				}
+			}
+			if column >= len(values) {
+				if cells += column + 1 - len(values); cells > maxCells {
+					return nil, ErrTooLarge
+				}
+				values = append(values, make([]string, column+1-len(values))...)
+			}
+
+			switch cell.Type {
+			case "s":
+				var index int
+				if _, err := fmt.Sscan(cell.Value, &index); err != nil || index < 0 || index >= len(sharedStrings.Items) {
+					return nil, fmt.Errorf("Invalid shared string in cell %s", cell.Ref)
+				}
+				values[column] = sharedStrings.Items[index].String()
+			case "inlineStr":
+				values[column] = cell.Inline.String()
+			default:
+				values[column] = cell.Value
+			}
+		}
+		rows = append(rows, values)
+	}
+	return rows, nil
+}
+
+// richText is a string item, either plain or split into formatted runs.
+type richText struct {
+	Text string   `xml:"t"`
+	Runs []string `xml:"r>t"`
+}
+
+func (t richText) String() string {
+	return t.Text + strings.Join(t.Runs, "")
+}
+
+func decode(files map[string]*zip.File, name string, v interface{}) error {
+	file, ok := files[name]
+	if !ok {
+		return fmt.Errorf("Invalid XLSX file: missing %s", name)
+	}
+
+	if file.UncompressedSize64 > MaxEntrySize {
+		return ErrTooLarge
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	// The declared size cannot be trusted, so the reading is limited too.
+	limited := &io.LimitedReader{R: reader, N: MaxEntrySize + 1}
+	if err := xml.NewDecoder(limited).Decode(v); err != nil {
+		if limited.N <= 0 {
+			return ErrTooLarge
+		}
+		return fmt.Errorf("Invalid XLSX file: %s: %w", name, err)
+	}
+	return nil
+}
+
+// columnIndex returns the column, counted from 0, of an A1 cell reference.
+func columnIndex(ref string) (int, error) {
+	column := 0
+	letters := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		column = column*26 + int(r-'A') + 1
+		letters++
+	}
+	if letters == 0 || column > maxColumns {
+		return 0, fmt.Errorf("Invalid cell reference %q", ref)
+	}
+	return column - 1, nil
+}
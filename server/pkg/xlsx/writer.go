@@ -0,0 +1,250 @@
+// Package xlsx reads and writes the subset of Office Open XML workbooks the
+// tracker exchanges: sheets of plain strings and numbers, bold headers and
+// merged header cells.
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// MaxSheetName is the longest sheet name spreadsheet applications accept.
+const MaxSheetName = 31
+
+// Cell is a single value of a sheet.
+type Cell struct {
+	Value  string
+	Number bool // Whether Value holds a number
+	Bold   bool
+}
+
+// String returns a text cell.
+func String(value string) Cell {
+	return Cell{Value: value}
+}
+
+// Number returns a numeric cell.
+func Number(value float64) Cell {
+	return Cell{Value: strconv.FormatFloat(value, 'f', -1, 64), Number: true}
+}
+
+// Header returns a bold text cell.
+func Header(value string) Cell {
+	return Cell{Value: value, Bold: true}
+}
+
+// Sheet is a grid of cells, filled row by row.
+type Sheet struct {
+	Name   string
+	Rows   [][]Cell
+	merges []string
+}
+
+// Workbook is an ordered list of sheets.
+type Workbook struct {
+	Sheets []*Sheet
+}
+
+// AddSheet appends an empty sheet, making its name valid and unique within
+// the workbook.
+func (w *Workbook) AddSheet(name string) *Sheet {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" {
+		name = "Sheet"
+	}
+
+	unique := truncate(name, MaxSheetName)
+	for i := 2; w.hasSheet(unique); i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		unique = truncate(name, MaxSheetName-len(suffix)) + suffix
+	}
+
+	sheet := &Sheet{Name: unique}
+	w.Sheets = append(w.Sheets, sheet)
+	return sheet
+}
+
+func (w *Workbook) hasSheet(name string) bool {
+	for _, sheet := range w.Sheets {
+		if strings.EqualFold(sheet.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(name string, length int) string {
+	runes := []rune(name)
+	if len(runes) > length {
+		return string(runes[:length])
+	}
+	return name
+}
+
+// AddRow appends a row of cells.
+func (s *Sheet) AddRow(cells ...Cell) {
+	s.Rows = append(s.Rows, cells)
+}
+
+// Merge joins the cells between two corners, with rows and columns counted
+// from 0, into one cell showing the value of the top left cell.
+func (s *Sheet) Merge(top, left, bottom, right int) {
+	s.merges = append(s.merges, CellName(top, left)+":"+CellName(bottom, right))
+}
+
+// CellName returns the A1 reference of a cell, with row and column counted from 0.
+func CellName(row, column int) string {
+	return ColumnName(column) + strconv.Itoa(row+1)
+}
+
+// ColumnName returns the letters of a column counted from 0: A, B, ... Z, AA.
+func ColumnName(column int) string {
+	name := ""
+	for column++; column > 0; column = (column - 1) / 26 {
+		name = string(rune('A'+(column-1)%26)) + name
+	}
+	return name
+}
+
+// Write writes the workbook as an XLSX file.
+func (w *Workbook) Write(out io.Writer) error {
+	archive := zip.NewWriter(out)
+
+	files := []struct {
+		name    string
+		content func(io.Writer) error
+	}{
+		{"[Content_Types].xml", w.writeContentTypes},
+		{"_rels/.rels", writeString(packageRels)},
+		{"xl/workbook.xml", w.writeWorkbook},
+		{"xl/_rels/workbook.xml.rels", w.writeWorkbookRels},
+		{"xl/styles.xml", writeString(styles)},
+	}
+	for i, sheet := range w.Sheets {
+		files = append(files, struct {
+			name    string
+			content func(io.Writer) error
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), sheet.write})
+	}
+
+	for _, file := range files {
+		writer, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if err := file.content(writer); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+const packageRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+// styles defines the default cell format, 0, and bold headers, 1.
+const styles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1" applyAlignment="1"><alignment horizontal="center"/></xf></cellXfs>` +
+	`</styleSheet>`
+
+func writeString(content string) func(io.Writer) error {
+	return func(w io.Writer) error {
+		_, err := io.WriteString(w, content)
+		return err
+	}
+}
+
+func (w *Workbook) writeContentTypes(out io.Writer) error {
+	var b strings.Builder
+	b.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := range w.Sheets {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	b.WriteString(`</Types>`)
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func (w *Workbook) writeWorkbook(out io.Writer) error {
+	var b strings.Builder
+	b.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, sheet := range w.Sheets {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(sheet.Name), i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func (w *Workbook) writeWorkbookRels(out io.Writer) error {
+	var b strings.Builder
+	b.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range w.Sheets {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(w.Sheets)+1)
+	b.WriteString(`</Relationships>`)
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func (s *Sheet) write(out io.Writer) error {
+	var b strings.Builder
+	b.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r, row := range s.Rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			style := ""
+			if cell.Bold {
+				style = ` s="1"`
+			}
+			switch {
+			case cell.Number:
+				fmt.Fprintf(&b, `<c r="%s"%s><v>%s</v></c>`, CellName(r, c), style, cell.Value)
+			case cell.Value != "":
+				fmt.Fprintf(&b, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, CellName(r, c), style, escape(cell.Value))
+			case cell.Bold:
+				fmt.Fprintf(&b, `<c r="%s"%s/>`, CellName(r, c), style)
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData>`)
+
+	if len(s.merges) > 0 {
+		fmt.Fprintf(&b, `<mergeCells count="%d">`, len(s.merges))
+		for _, merge := range s.merges {
+			fmt.Fprintf(&b, `<mergeCell ref="%s"/>`, merge)
+		}
+		b.WriteString(`</mergeCells>`)
+	}
+	b.WriteString(`</worksheet>`)
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func escape(value string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(value))
+	return b.String()
+}